@@ -0,0 +1,374 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gctx"
+	"golang.org/x/term"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+)
+
+const adminUsage = `Usage: arbiter admin <command> [flags]
+
+Commands:
+  register-eth   register the arbitrator by staking ELA
+  register-nft   register the arbitrator by staking NFTs
+  stake-eth      add ELA stake
+  stake-nft      add NFT stake
+  unstake        withdraw all stake
+  set-fee-rate   set the arbitrator fee rate
+  set-deadline   set the arbitrator deadline
+  pause          stop accepting new transactions
+  unpause        resume accepting new transactions
+  set-revenue    set the revenue ETH/BTC addresses
+
+Common flags:
+  -keyfile   arbitrator key file ({"privKey":"<hex>"}), prompted if empty
+  -dry-run   prepare and simulate the transaction without sending it
+  -yes       do not ask for confirmation
+  -wait      wait for the receipt (default true)
+  -timeout   receipt wait timeout (default 5m)
+`
+
+type adminOptions struct {
+	keyFile string
+	dryRun  bool
+	yes     bool
+	wait    bool
+	timeout time.Duration
+}
+
+func runAdmin(args []string) error {
+	if len(args) == 0 {
+		fmt.Print(adminUsage)
+		return errors.New("missing admin command")
+	}
+	command := strings.ToLower(args[0])
+	switch command {
+	case "-h", "-help", "--help", "help":
+		fmt.Print(adminUsage)
+		return flag.ErrHelp
+	}
+	fs := flag.NewFlagSet("admin "+command, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Print(adminUsage)
+	}
+	var opts adminOptions
+	fs.StringVar(&opts.keyFile, "keyfile", "", "arbitrator key file")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "simulate without sending")
+	fs.BoolVar(&opts.yes, "yes", false, "skip confirmation")
+	fs.BoolVar(&opts.wait, "wait", true, "wait for receipt")
+	fs.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "receipt wait timeout")
+
+	var (
+		btcAddress = fs.String("btc-address", "", "BTC address")
+		btcPubKey  = fs.String("btc-pubkey", "", "hex encoded BTC public key")
+		ethAddress = fs.String("eth-address", "", "revenue ESC address")
+		feeRate    = fs.String("fee-rate", "", "fee rate")
+		deadline   = fs.String("deadline", "", "deadline as unix timestamp")
+		amount     = fs.String("amount", "", "ELA amount to stake, e.g. 1.5")
+		tokenIds   = fs.String("token-ids", "", "comma separated NFT token ids")
+	)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var call contract.AdminCall
+	var err error
+	switch command {
+	case "register-eth":
+		var pubKey []byte
+		var rate, dl, stake *big.Int
+		if pubKey, err = parseHexFlag("btc-pubkey", *btcPubKey); err != nil {
+			return err
+		}
+		if rate, err = parseIntFlag("fee-rate", *feeRate); err != nil {
+			return err
+		}
+		if dl, err = parseIntFlag("deadline", *deadline); err != nil {
+			return err
+		}
+		if stake, err = parseEther(*amount); err != nil {
+			return err
+		}
+		if *btcAddress == "" {
+			return errors.New("-btc-address is required")
+		}
+		call = contract.RegisterArbitratorByStakeETH(*btcAddress, pubKey, rate, dl, stake)
+	case "register-nft":
+		var pubKey []byte
+		var rate, dl *big.Int
+		var ids []*big.Int
+		if ids, err = parseTokenIds(*tokenIds); err != nil {
+			return err
+		}
+		if pubKey, err = parseHexFlag("btc-pubkey", *btcPubKey); err != nil {
+			return err
+		}
+		if rate, err = parseIntFlag("fee-rate", *feeRate); err != nil {
+			return err
+		}
+		if dl, err = parseIntFlag("deadline", *deadline); err != nil {
+			return err
+		}
+		if *btcAddress == "" {
+			return errors.New("-btc-address is required")
+		}
+		call = contract.RegisterArbitratorByStakeNFT(ids, *btcAddress, pubKey, rate, dl)
+	case "stake-eth":
+		var stake *big.Int
+		if stake, err = parseEther(*amount); err != nil {
+			return err
+		}
+		call = contract.StakeETH(stake)
+	case "stake-nft":
+		var ids []*big.Int
+		if ids, err = parseTokenIds(*tokenIds); err != nil {
+			return err
+		}
+		call = contract.StakeNFT(ids)
+	case "unstake":
+		call = contract.Unstake()
+	case "set-fee-rate":
+		var rate *big.Int
+		if rate, err = parseIntFlag("fee-rate", *feeRate); err != nil {
+			return err
+		}
+		call = contract.SetArbitratorFeeRate(rate)
+	case "set-deadline":
+		var dl *big.Int
+		if dl, err = parseIntFlag("deadline", *deadline); err != nil {
+			return err
+		}
+		call = contract.SetArbitratorDeadline(dl)
+	case "pause":
+		call = contract.Pause()
+	case "unpause":
+		call = contract.Unpause()
+	case "set-revenue":
+		var pubKey []byte
+		if pubKey, err = parseHexFlag("btc-pubkey", *btcPubKey); err != nil {
+			return err
+		}
+		if !common.IsHexAddress(*ethAddress) {
+			return errors.New("-eth-address must be a valid ESC address")
+		}
+		if *btcAddress == "" {
+			return errors.New("-btc-address is required")
+		}
+		call = contract.SetRevenueAddresses(common.HexToAddress(*ethAddress), pubKey, *btcAddress)
+	default:
+		fmt.Print(adminUsage)
+		return fmt.Errorf("unknown admin command %q", command)
+	}
+
+	return sendAdminCall(call, opts)
+}
+
+func sendAdminCall(call contract.AdminCall, opts adminOptions) error {
+	ctx := gctx.New()
+	g.Cfg().GetAdapter().(*gcfg.AdapterFile).SetPath(".")
+	http, err := g.Cfg().Get(ctx, "chain.esc")
+	if err != nil {
+		return fmt.Errorf("get chain.esc config: %v", err)
+	}
+	manager, err := g.Cfg().Get(ctx, "arbiter.escArbiterManagerContractAddress")
+	if err != nil {
+		return fmt.Errorf("get escArbiterManagerContractAddress config: %v", err)
+	}
+
+	privateKey, err := readArbitratorKey(opts.keyFile)
+	if err != nil {
+		return err
+	}
+	admin, err := contract.NewAdmin(ctx, http.String(), manager.String(), privateKey)
+	if err != nil {
+		return err
+	}
+
+	tx, err := admin.Prepare(ctx, call)
+	if err != nil {
+		return fmt.Errorf("prepare %s: %v", call.Method, err)
+	}
+	printAdminTransaction(tx)
+
+	if err := admin.Simulate(ctx, tx); err != nil {
+		return fmt.Errorf("simulate %s: %v", call.Method, err)
+	}
+	fmt.Println("Simulation:   ok")
+	if opts.dryRun {
+		fmt.Println("Dry run, transaction not sent.")
+		return nil
+	}
+
+	if !opts.yes && !confirm("Send transaction?") {
+		return errors.New("aborted")
+	}
+	hash, err := admin.Send(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("send %s: %v", call.Method, err)
+	}
+	fmt.Println("Transaction: ", hash.String())
+	if !opts.wait {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	receipt, err := admin.WaitForReceipt(waitCtx, hash)
+	if err != nil {
+		return fmt.Errorf("wait for receipt: %v", err)
+	}
+	fmt.Println("Block:       ", receipt.BlockNumber)
+	fmt.Println("Gas used:    ", receipt.GasUsed)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s reverted", hash.String())
+	}
+	fmt.Println("Status:       success")
+	return nil
+}
+
+func printAdminTransaction(t *contract.AdminTransaction) {
+	fmt.Println("Method:      ", t.Call.Method)
+	for i, arg := range t.Call.Args {
+		if b, ok := arg.([]byte); ok {
+			arg = hex.EncodeToString(b)
+		}
+		fmt.Printf("  arg[%d]:     %v\n", i, arg)
+	}
+	fmt.Println("From:        ", t.From.String())
+	fmt.Println("To:          ", t.To.String())
+	fmt.Println("Value:       ", formatEther(t.Tx.Value()), "ELA")
+	fmt.Println("Nonce:       ", t.Tx.Nonce())
+	fmt.Println("Gas limit:   ", t.Tx.Gas())
+	fmt.Println("Gas price:   ", t.Tx.GasPrice(), "wei")
+	fmt.Println("Max cost:    ", formatEther(t.MaxCost()), "ELA")
+}
+
+func readArbitratorKey(keyFile string) (string, error) {
+	var key string
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", fmt.Errorf("read key file: %v", err)
+		}
+		var a struct {
+			PrivateKey string `json:"privKey"`
+		}
+		if err := json.Unmarshal(data, &a); err != nil {
+			return "", fmt.Errorf("unmarshal key file: %v", err)
+		}
+		key = a.PrivateKey
+	} else {
+		fmt.Print("Enter arbitrator ESC private key (64 hex characters): ")
+		keyBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("error reading private key: %v", err)
+		}
+		key = string(keyBytes)
+	}
+
+	key = strings.TrimPrefix(strings.TrimSpace(key), "0x")
+	if len(key) != 64 {
+		return "", errors.New("private key must be exactly 64 hex characters")
+	}
+	if _, err := hex.DecodeString(key); err != nil {
+		return "", errors.New("private key must be in hex format")
+	}
+	return key, nil
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
+
+func parseHexFlag(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("-%s is required", name)
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("-%s must be hex encoded: %v", name, err)
+	}
+	return b, nil
+}
+
+func parseIntFlag(name, value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("-%s is required", name)
+	}
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("-%s must be a non-negative integer", name)
+	}
+	if n.BitLen() > 256 {
+		return nil, fmt.Errorf("-%s does not fit in a uint256", name)
+	}
+	return n, nil
+}
+
+func parseTokenIds(value string) ([]*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("-token-ids is required")
+	}
+	var ids []*big.Int
+	for _, s := range strings.Split(value, ",") {
+		id, err := parseIntFlag("token-ids", strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+var weiPerEther = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// parseEther converts a decimal ELA amount into wei.
+func parseEther(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("-amount is required")
+	}
+	// big.Rat also parses fractions and exponents, a huge exponent takes
+	// ages to expand
+	if strings.ContainsAny(value, "/eE") {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	r, ok := new(big.Rat).SetString(value)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	r.Mul(r, new(big.Rat).SetInt(weiPerEther))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("amount %q has more than 18 decimals", value)
+	}
+	if r.Num().BitLen() > 256 {
+		return nil, fmt.Errorf("amount %q does not fit in a uint256", value)
+	}
+	return r.Num(), nil
+}
+
+func formatEther(wei *big.Int) string {
+	return new(big.Rat).SetFrac(wei, weiPerEther).FloatString(18)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const maxUint256 = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+func TestParseIntFlag(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"0", "0"},
+		{"250", "250"},
+		{maxUint256, maxUint256},
+		{"", ""},
+		{"-1", ""},
+		{"1.5", ""},
+		{"0x10", ""},
+		{"abc", ""},
+		{maxUint256[:len(maxUint256)-1] + "6", ""},
+	}
+	for _, tt := range tests {
+		n, err := parseIntFlag("fee-rate", tt.value)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%q: accepted as %s", tt.value, n)
+			}
+			continue
+		}
+		if err != nil || n.String() != tt.want {
+			t.Errorf("%q: %v %v, want %s", tt.value, n, err, tt.want)
+		}
+	}
+}
+
+func TestParseTokenIds(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []int64
+	}{
+		{"1", []int64{1}},
+		{"1, 2,3", []int64{1, 2, 3}},
+		{"", nil},
+		{"1,", nil},
+		{"1,-2", nil},
+		{"1;2", nil},
+		{"1," + maxUint256 + "0", nil},
+	}
+	for _, tt := range tests {
+		ids, err := parseTokenIds(tt.value)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%q: accepted as %v", tt.value, ids)
+			}
+			continue
+		}
+		if err != nil || len(ids) != len(tt.want) {
+			t.Errorf("%q: %v %v, want %v", tt.value, ids, err, tt.want)
+			continue
+		}
+		for i, id := range ids {
+			if id.Int64() != tt.want[i] {
+				t.Errorf("%q: %v, want %v", tt.value, ids, tt.want)
+			}
+		}
+	}
+}
+
+func TestParseEther(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"1", "1000000000000000000"},
+		{"1.5", "1500000000000000000"},
+		{"0.000000000000000001", "1"},
+		{"", ""},
+		{"0", ""},
+		{"-1", ""},
+		{"abc", ""},
+		{"1/3", ""},
+		{"1e3", ""},
+		{"1e100000000", ""},
+		{"0.0000000000000000001", ""},
+		{maxUint256, ""},
+	}
+	for _, tt := range tests {
+		wei, err := parseEther(tt.value)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%q: accepted as %s", tt.value, wei)
+			}
+			continue
+		}
+		want, _ := new(big.Int).SetString(tt.want, 10)
+		if err != nil || wei.Cmp(want) != 0 {
+			t.Errorf("%q: %v %v, want %s", tt.value, wei, err, tt.want)
+		}
+	}
+}
+
+func TestAdminHelp(t *testing.T) {
+	for _, args := range [][]string{{"-h"}, {"help"}, {"stake-eth", "-h"}} {
+		if err := runAdmin(args); !errors.Is(err, flag.ErrHelp) {
+			t.Errorf("%s: %v", strings.Join(args, " "), err)
+		}
+	}
+	if err := runAdmin([]string{"stake"}); err == nil || errors.Is(err, flag.ErrHelp) {
+		t.Errorf("unknown command: %v", err)
+	}
+}
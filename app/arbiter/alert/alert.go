@@ -0,0 +1,262 @@
+// Copyright (c) 2025 The bel2 developers
+
+// Package alert notifies operators of events that need attention. Alerts
+// are deduplicated and rate limited by a Dispatcher, then delivered to
+// every configured Notifier in the background.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Critical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "INFO"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	}
+	return "UNKNOWN"
+}
+
+// Alert kinds raised by the arbiter.
+const (
+	KindRequest        = "request"
+	KindSignFailed     = "sign_failed"
+	KindParked         = "parked"
+	KindDeadline       = "deadline"
+	KindDeadlineMissed = "deadline_missed"
+	KindLowBalance     = "low_balance"
+	KindStatus         = "status"
+	KindApproval       = "approval"
+)
+
+const (
+	// alerts waiting for delivery, more are dropped
+	queueSize = 100
+	// time limit for delivering one alert to one notifier
+	notifyTimeout = 15 * time.Second
+)
+
+type Alert struct {
+	Kind string
+	// identifies the subject within Kind for deduplication, like a txId
+	Key      string
+	Severity Severity
+	Title    string
+	Text     string
+	// arbiter that raised the alert, set by the Dispatcher
+	Source string
+	Time   time.Time
+}
+
+// Subject is the one line summary used as message title.
+func (a *Alert) Subject() string {
+	return "[" + a.Severity.String() + "] " + a.Title
+}
+
+// Body is the plain text message of the alert.
+func (a *Alert) Body() string {
+	var b strings.Builder
+	if a.Text != "" {
+		b.WriteString(a.Text)
+		b.WriteString("\n\n")
+	}
+	if a.Source != "" {
+		fmt.Fprintf(&b, "arbiter: %s\n", a.Source)
+	}
+	fmt.Fprintf(&b, "time: %s", a.Time.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// Notifier delivers alerts to one destination.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, a *Alert) error
+}
+
+type Config struct {
+	// repeats of an alert within this window are dropped unless their
+	// severity is higher, zero disables deduplication
+	DedupWindow time.Duration
+	// alerts accepted per minute, zero means unlimited
+	RatePerMinute int
+	// added to every alert, usually the arbiter address
+	Source string
+
+	Webhook  WebhookConfig
+	Slack    SlackConfig
+	Telegram TelegramConfig
+	SMTP     SMTPConfig
+}
+
+// Notifiers returns a notifier for every configured backend.
+func (c *Config) Notifiers() []Notifier {
+	var notifiers []Notifier
+	if c.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhook(c.Webhook))
+	}
+	if c.Slack.URL != "" {
+		notifiers = append(notifiers, NewSlack(c.Slack))
+	}
+	if c.Telegram.Token != "" {
+		notifiers = append(notifiers, NewTelegram(c.Telegram))
+	}
+	if c.SMTP.Addr != "" {
+		notifiers = append(notifiers, NewSMTP(c.SMTP))
+	}
+	return notifiers
+}
+
+type sentAlert struct {
+	at       time.Time
+	severity Severity
+}
+
+// Dispatcher filters alerts and hands them to the notifiers.
+type Dispatcher struct {
+	queue chan *Alert
+	now   func() time.Time
+
+	mu sync.Mutex
+	// replaced by Update
+	notifiers []Notifier
+	cfg       Config
+	sent      map[string]sentAlert
+	// start of the current rate limit window and alerts accepted in it
+	windowStart time.Time
+	inWindow    int
+	// alerts dropped by the rate limit since the last accepted one
+	suppressed int
+}
+
+func NewDispatcher(cfg Config, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{
+		notifiers: notifiers,
+		cfg:       cfg,
+		queue:     make(chan *Alert, queueSize),
+		now:       time.Now,
+		sent:      make(map[string]sentAlert),
+	}
+}
+
+// Enabled reports whether any notifier is configured.
+func (d *Dispatcher) Enabled() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.notifiers) > 0
+}
+
+// Update replaces the configuration and the notifiers. Alerts already
+// queued are delivered to the new notifiers.
+func (d *Dispatcher) Update(cfg Config, notifiers ...Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+	d.notifiers = notifiers
+}
+
+// Send queues a for delivery. It returns false if a was dropped as a
+// duplicate, by the rate limit or because the queue is full.
+func (d *Dispatcher) Send(a *Alert) bool {
+	if !d.Enabled() {
+		return false
+	}
+	now := d.now()
+	if a.Time.IsZero() {
+		a.Time = now
+	}
+
+	d.mu.Lock()
+	a.Source = d.cfg.Source
+	key := a.Kind + "/" + a.Key
+	if last, ok := d.sent[key]; ok && d.cfg.DedupWindow > 0 &&
+		now.Sub(last.at) < d.cfg.DedupWindow && a.Severity <= last.severity {
+		d.mu.Unlock()
+		return false
+	}
+	if d.cfg.RatePerMinute > 0 {
+		if now.Sub(d.windowStart) >= time.Minute {
+			d.windowStart = now
+			d.inWindow = 0
+		}
+		if d.inWindow >= d.cfg.RatePerMinute {
+			d.suppressed++
+			d.mu.Unlock()
+			return false
+		}
+		d.inWindow++
+	}
+	if d.suppressed > 0 {
+		a.Text = strings.TrimSpace(fmt.Sprintf("%s\n\n%d earlier alerts were dropped by the rate limit", a.Text, d.suppressed))
+		d.suppressed = 0
+	}
+	d.sent[key] = sentAlert{at: now, severity: a.Severity}
+	d.pruneLocked(now)
+	d.mu.Unlock()
+
+	select {
+	case d.queue <- a:
+		return true
+	default:
+		g.Log().Warning(context.Background(), "alert queue full, dropped:", a.Subject())
+		return false
+	}
+}
+
+// pruneLocked forgets alerts older than the dedup window.
+func (d *Dispatcher) pruneLocked(now time.Time) {
+	if len(d.sent) < queueSize {
+		return
+	}
+	for key, s := range d.sent {
+		if now.Sub(s.at) >= d.cfg.DedupWindow {
+			delete(d.sent, key)
+		}
+	}
+}
+
+// Run delivers queued alerts until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case a := <-d.queue:
+			d.deliver(ctx, a)
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, a *Alert) {
+	d.mu.Lock()
+	notifiers := d.notifiers
+	d.mu.Unlock()
+	for _, n := range notifiers {
+		nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err := n.Notify(nctx, a)
+		cancel()
+		if err != nil {
+			g.Log().Error(ctx, "send alert error", err, "notifier:", n.Name(), "alert:", a.Subject())
+		}
+	}
+}
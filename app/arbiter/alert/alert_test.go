@@ -0,0 +1,291 @@
+// Copyright (c) 2025 The bel2 developers
+
+package alert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	alerts []*Alert
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) Notify(ctx context.Context, a *Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func TestDedup(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	d := NewDispatcher(Config{DedupWindow: time.Hour}, &recorder{})
+	d.now = func() time.Time { return now }
+
+	warn := func(sev Severity) bool {
+		return d.Send(&Alert{Kind: KindDeadline, Key: "0xaa", Severity: sev, Title: "deadline"})
+	}
+	if !warn(Info) {
+		t.Fatal("first alert dropped")
+	}
+	if warn(Info) {
+		t.Fatal("duplicate sent")
+	}
+	if !warn(Critical) {
+		t.Fatal("escalation dropped")
+	}
+	if warn(Warning) {
+		t.Fatal("lower severity sent")
+	}
+	if !d.Send(&Alert{Kind: KindDeadline, Key: "0xbb", Severity: Info}) {
+		t.Fatal("other key dropped")
+	}
+	now = now.Add(time.Hour)
+	if !warn(Info) {
+		t.Fatal("repeat after window dropped")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	d := NewDispatcher(Config{RatePerMinute: 2}, &recorder{})
+	d.now = func() time.Time { return now }
+
+	send := func(key string) bool {
+		return d.Send(&Alert{Kind: KindRequest, Key: key, Text: key})
+	}
+	if !send("a") || !send("b") {
+		t.Fatal("alerts within the limit dropped")
+	}
+	if send("c") || send("d") {
+		t.Fatal("rate limit not applied")
+	}
+	now = now.Add(time.Minute)
+	if !send("e") {
+		t.Fatal("alert in next window dropped")
+	}
+	for i := 0; i < 2; i++ {
+		<-d.queue
+	}
+	a := <-d.queue
+	if !strings.Contains(a.Text, "2 earlier alerts were dropped") {
+		t.Fatalf("missing suppressed count: %q", a.Text)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	d := NewDispatcher(Config{})
+	if d.Enabled() || d.Send(&Alert{Kind: KindRequest}) {
+		t.Fatal("dispatcher without notifiers accepted an alert")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	d := NewDispatcher(Config{})
+	r := &recorder{}
+	d.Update(Config{Source: "0xarbiter"}, r)
+	if !d.Enabled() || !d.Send(&Alert{Kind: KindRequest, Key: "0xaa"}) {
+		t.Fatal("alert dropped after enabling a notifier")
+	}
+	d.deliver(context.Background(), <-d.queue)
+	if len(r.alerts) != 1 || r.alerts[0].Source != "0xarbiter" {
+		t.Fatalf("not delivered with the new config: %+v", r.alerts)
+	}
+	d.Update(Config{})
+	if d.Enabled() {
+		t.Fatal("enabled without notifiers")
+	}
+}
+
+func TestRun(t *testing.T) {
+	r := &recorder{}
+	d := NewDispatcher(Config{Source: "0xarbiter"}, r)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	d.Send(&Alert{Kind: KindParked, Key: "0xaa", Severity: Critical, Title: "parked"})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		n := len(r.alerts)
+		r.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("alert not delivered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if r.alerts[0].Source != "0xarbiter" {
+		t.Fatalf("source not set: %+v", r.alerts[0])
+	}
+}
+
+var testAlert = &Alert{
+	Kind:     KindSignFailed,
+	Key:      "0xaa",
+	Severity: Warning,
+	Title:    "signing failed",
+	Text:     "nonce too low",
+	Source:   "0xarbiter",
+	Time:     time.Unix(1700000000, 0),
+}
+
+// jsonServer records the path and JSON body of the last request.
+func jsonServer(t *testing.T, response string) (*httptest.Server, *string, *map[string]interface{}) {
+	var path string
+	body := map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &path, &body
+}
+
+func TestWebhook(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var p webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		if p.Kind != KindSignFailed || p.Key != "0xaa" || p.Severity != "warning" || p.Source != "0xarbiter" {
+			t.Errorf("payload: %+v", p)
+		}
+	}))
+	defer srv.Close()
+
+	w := NewWebhook(WebhookConfig{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}})
+	if err := w.Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer x" {
+		t.Fatalf("header not sent: %q", auth)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	if err := NewWebhook(WebhookConfig{URL: failing.URL}).Notify(context.Background(), testAlert); err == nil {
+		t.Fatal("error status accepted")
+	}
+}
+
+func TestSlack(t *testing.T) {
+	srv, _, body := jsonServer(t, "ok")
+	if err := NewSlack(SlackConfig{URL: srv.URL}).Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	text, _ := (*body)["text"].(string)
+	if !strings.HasPrefix(text, "*[WARNING] signing failed*") || !strings.Contains(text, "nonce too low") {
+		t.Fatalf("text: %q", text)
+	}
+}
+
+func TestTelegram(t *testing.T) {
+	srv, path, body := jsonServer(t, `{"ok":true}`)
+	tg := NewTelegram(TelegramConfig{Token: "123:abc", ChatID: "-42", APIURL: srv.URL})
+	if err := tg.Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	if *path != "/bot123:abc/sendMessage" || (*body)["chat_id"] != "-42" {
+		t.Fatalf("request: %s %v", *path, *body)
+	}
+
+	srv, _, _ = jsonServer(t, `{"ok":false,"description":"chat not found"}`)
+	tg = NewTelegram(TelegramConfig{Token: "123:abc", ChatID: "-42", APIURL: srv.URL})
+	if err := tg.Notify(context.Background(), testAlert); err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
+
+// smtpServer accepts one mail and returns its DATA section.
+func smtpServer(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var msg strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					data <- msg.String()
+					reply("250 OK")
+					continue
+				}
+				msg.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), data
+}
+
+func TestSMTP(t *testing.T) {
+	addr, data := smtpServer(t)
+	s := NewSMTP(SMTPConfig{Addr: addr, From: "arbiter@example.com", To: []string{"ops@example.com"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Notify(ctx, testAlert); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-data
+	for _, want := range []string{"Subject: [WARNING] signing failed\r\n", "To: ops@example.com\r\n", "nonce too low\r\n"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("missing %q in %q", want, msg)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2025 The bel2 developers
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTelegramAPI = "https://api.telegram.org"
+
+type WebhookConfig struct {
+	URL string
+	// extra request headers, like an Authorization token
+	Headers map[string]string
+}
+
+// Webhook posts every alert as a JSON object.
+type Webhook struct {
+	cfg WebhookConfig
+}
+
+func NewWebhook(cfg WebhookConfig) *Webhook {
+	return &Webhook{cfg: cfg}
+}
+
+func (w *Webhook) Name() string { return "webhook" }
+
+type webhookPayload struct {
+	Kind     string    `json:"kind"`
+	Key      string    `json:"key,omitempty"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Text     string    `json:"text,omitempty"`
+	Source   string    `json:"source,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+func (w *Webhook) Notify(ctx context.Context, a *Alert) error {
+	return postJSON(ctx, w.cfg.URL, w.cfg.Headers, &webhookPayload{
+		Kind:     a.Kind,
+		Key:      a.Key,
+		Severity: strings.ToLower(a.Severity.String()),
+		Title:    a.Title,
+		Text:     a.Text,
+		Source:   a.Source,
+		Time:     a.Time.UTC(),
+	}, nil)
+}
+
+type SlackConfig struct {
+	// incoming webhook URL, Mattermost and Rocket.Chat accept the same format
+	URL string
+}
+
+// Slack posts alerts to a Slack compatible incoming webhook.
+type Slack struct {
+	cfg SlackConfig
+}
+
+func NewSlack(cfg SlackConfig) *Slack {
+	return &Slack{cfg: cfg}
+}
+
+func (s *Slack) Name() string { return "slack" }
+
+func (s *Slack) Notify(ctx context.Context, a *Alert) error {
+	text := "*" + a.Subject() + "*\n" + a.Body()
+	return postJSON(ctx, s.cfg.URL, nil, map[string]string{"text": text}, nil)
+}
+
+type TelegramConfig struct {
+	// bot token from @BotFather
+	Token  string
+	ChatID string
+	// Bot API base URL, empty means api.telegram.org
+	APIURL string
+}
+
+// Telegram sends alerts as messages of a bot.
+type Telegram struct {
+	cfg TelegramConfig
+}
+
+func NewTelegram(cfg TelegramConfig) *Telegram {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultTelegramAPI
+	}
+	return &Telegram{cfg: cfg}
+}
+
+func (t *Telegram) Name() string { return "telegram" }
+
+func (t *Telegram) Notify(ctx context.Context, a *Alert) error {
+	url := strings.TrimRight(t.cfg.APIURL, "/") + "/bot" + t.cfg.Token + "/sendMessage"
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	err := postJSON(ctx, url, nil, map[string]string{
+		"chat_id": t.cfg.ChatID,
+		"text":    a.Subject() + "\n\n" + a.Body(),
+	}, &result)
+	if err != nil {
+		// the URL holds the bot token, keep it out of the logs
+		return fmt.Errorf("telegram sendMessage: %w", redact(err, t.cfg.Token))
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram sendMessage: %s", result.Description)
+	}
+	return nil
+}
+
+var httpClient = &http.Client{Timeout: notifyTimeout}
+
+// postJSON posts body as JSON to url and decodes the response into result
+// if it is not nil.
+func postJSON(ctx context.Context, url string, headers map[string]string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %s: %s", resp.Status, strings.TrimSpace(string(content)))
+	}
+	if result != nil {
+		return json.Unmarshal(content, result)
+	}
+	return nil
+}
+
+func redact(err error, secret string) error {
+	if secret == "" {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), secret, "<redacted>"))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 The bel2 developers
+
+package alert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPConfig struct {
+	// server "host:port", STARTTLS is used when the server offers it
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// SMTP mails alerts.
+type SMTP struct {
+	cfg SMTPConfig
+}
+
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	return &SMTP{cfg: cfg}
+}
+
+func (s *SMTP) Name() string { return "smtp" }
+
+func (s *SMTP) Notify(ctx context.Context, a *Alert) error {
+	if len(s.cfg.To) == 0 {
+		return fmt.Errorf("smtp: no recipient configured")
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(s.cfg.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)
+	}
+
+	// smtp.SendMail has no context, bound it by the context deadline instead
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.cfg.Addr, auth, s.cfg.From, s.cfg.To, s.message(a))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SMTP) message(a *Alert) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(a.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", a.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(a.Body(), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// headerValue keeps a value on one header line.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
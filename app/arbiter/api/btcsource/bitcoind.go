@@ -0,0 +1,192 @@
+// Copyright (c) 2025 The bel2 developers
+
+package btcsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// bitcoind RPC error codes
+const (
+	rpcInvalidAddressOrKey = -5
+)
+
+// bitcoind reads the chain from the JSON-RPC interface of a bitcoind node,
+// which needs txindex=1 to look up confirmed transactions it does not
+// watch.
+type bitcoind struct {
+	endpoint string
+	user     string
+	password string
+	// cookie file read on every call, bitcoind rewrites it on restart
+	cookieFile string
+	client     *http.Client
+	id         atomic.Uint64
+}
+
+// NewBitcoind returns a client of the bitcoind RPC at rawURL. The
+// credentials are the user info of rawURL, or the content of cookieFile.
+func NewBitcoind(rawURL, cookieFile string) (BTCSource, error) {
+	if rawURL == "" {
+		return nil, errors.New("bitcoind BTC source needs bitcoindUrl")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitcoindUrl: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid bitcoindUrl scheme %q, need http or https", u.Scheme)
+	}
+	s := &bitcoind{cookieFile: cookieFile, client: &http.Client{Timeout: time.Minute}}
+	if u.User != nil {
+		s.user = u.User.Username()
+		s.password, _ = u.User.Password()
+		u.User = nil
+	}
+	s.endpoint = u.String()
+	if s.user == "" && cookieFile == "" {
+		return nil, errors.New("bitcoind BTC source needs credentials in bitcoindUrl or a bitcoindCookieFile")
+	}
+	return s, nil
+}
+
+func (s *bitcoind) String() string {
+	return "bitcoind " + s.endpoint
+}
+
+// rpcError is the error of a JSON-RPC response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+func (s *bitcoind) credentials() (string, string, error) {
+	if s.cookieFile == "" {
+		return s.user, s.password, nil
+	}
+	cookie, err := os.ReadFile(s.cookieFile)
+	if err != nil {
+		return "", "", fmt.Errorf("read bitcoind cookie: %w", err)
+	}
+	user, password, ok := strings.Cut(strings.TrimSpace(string(cookie)), ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid bitcoind cookie file %s", s.cookieFile)
+	}
+	return user, password, nil
+}
+
+// call runs the RPC method and decodes its result into result.
+func (s *bitcoind) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"id":      s.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	user, password, err := s.credentials()
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(user, password)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bitcoind %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("bitcoind %s: %s, check the RPC credentials", method, resp.Status)
+	}
+	// RPC errors come with status 404 or 500 and a JSON body
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(&response); err != nil {
+		return fmt.Errorf("bitcoind %s: %s: %w", method, resp.Status, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("bitcoind %s: %w", method, response.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+func (s *bitcoind) GetTransaction(ctx context.Context, txid chainhash.Hash) (*wire.MsgTx, error) {
+	var txHex string
+	if err := s.call(ctx, "getrawtransaction", &txHex, txid.String(), false); err != nil {
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) && rpcErr.Code == rpcInvalidAddressOrKey {
+			return nil, fmt.Errorf("%v: %w", err, ErrNotFound)
+		}
+		return nil, err
+	}
+	return decodeTx(txHex, txid)
+}
+
+func (s *bitcoind) GetPrevout(ctx context.Context, outpoint wire.OutPoint) (*wire.TxOut, error) {
+	return getPrevout(ctx, s, outpoint)
+}
+
+func (s *bitcoind) GetTipHeight(ctx context.Context) (int64, error) {
+	var height int64
+	err := s.call(ctx, "getblockcount", &height)
+	return height, err
+}
+
+func (s *bitcoind) IsSpent(ctx context.Context, outpoint wire.OutPoint) (bool, error) {
+	// null for spent and for unknown outputs, mempool spends included
+	var out *json.RawMessage
+	if err := s.call(ctx, "gettxout", &out, outpoint.Hash.String(), outpoint.Index, true); err != nil {
+		return false, err
+	}
+	if out != nil {
+		return false, nil
+	}
+	if _, err := getPrevout(ctx, s, outpoint); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *bitcoind) Broadcast(ctx context.Context, tx *wire.MsgTx) (chainhash.Hash, error) {
+	txHex, err := encodeTx(tx)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	var txid string
+	if err := s.call(ctx, "sendrawtransaction", &txid, txHex); err != nil {
+		return chainhash.Hash{}, err
+	}
+	return checkBroadcast(tx, txid)
+}
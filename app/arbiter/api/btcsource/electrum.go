@@ -0,0 +1,228 @@
+// Copyright (c) 2025 The bel2 developers
+
+package btcsource
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// electrumProtocol is the protocol version negotiated with the server.
+const electrumProtocol = "1.4"
+
+// electrum reads the chain from an Electrum server, such as electrs,
+// ElectrumX or Fulcrum. Every call uses a connection of its own.
+type electrum struct {
+	addr string
+	tls  bool
+	id   atomic.Uint64
+}
+
+// NewElectrum returns a client of the Electrum server at addr,
+// "tcp://host:port" or "ssl://host:port".
+func NewElectrum(addr string) (BTCSource, error) {
+	if addr == "" {
+		return nil, errors.New("electrum BTC source needs electrumAddr")
+	}
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid electrumAddr %q, need tcp://host:port or ssl://host:port", addr)
+	}
+	s := &electrum{addr: u.Host}
+	switch u.Scheme {
+	case "tcp":
+	case "ssl", "tls":
+		s.tls = true
+	default:
+		return nil, fmt.Errorf("invalid electrumAddr scheme %q, need tcp or ssl", u.Scheme)
+	}
+	return s, nil
+}
+
+func (s *electrum) String() string {
+	if s.tls {
+		return "electrum ssl://" + s.addr
+	}
+	return "electrum tcp://" + s.addr
+}
+
+func (s *electrum) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if s.tls {
+		host, _, _ := net.SplitHostPort(s.addr)
+		return (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", s.addr)
+	}
+	return dialer.DialContext(ctx, "tcp", s.addr)
+}
+
+// call negotiates the protocol version and runs the method, decoding its
+// result into result.
+func (s *electrum) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("electrum %s: %w", method, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	if err := s.roundTrip(conn, reader, "server.version", nil, "arbiter", electrumProtocol); err != nil {
+		return err
+	}
+	return s.roundTrip(conn, reader, method, result, params...)
+}
+
+func (s *electrum) roundTrip(conn net.Conn, reader *bufio.Reader, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	id := s.id.Add(1)
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(request, '\n')); err != nil {
+		return fmt.Errorf("electrum %s: %w", method, err)
+	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("electrum %s: %w", method, err)
+		}
+		var response struct {
+			Id     *uint64         `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(line, &response); err != nil {
+			return fmt.Errorf("electrum %s: %w", method, err)
+		}
+		if response.Id == nil || *response.Id != id {
+			// a notification
+			continue
+		}
+		if len(response.Error) > 0 && string(response.Error) != "null" {
+			return fmt.Errorf("electrum %s: %w", method, electrumError(response.Error))
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(response.Result, result)
+	}
+}
+
+// electrumError decodes an error, an object with a message or a string
+// depending on the server.
+func electrumError(raw json.RawMessage) error {
+	var e struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	message := string(raw)
+	if json.Unmarshal(raw, &e) == nil && e.Message != "" {
+		message = e.Message
+	} else {
+		json.Unmarshal(raw, &message)
+	}
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, "not found") || strings.Contains(lower, "no such") {
+		return fmt.Errorf("%s: %w", message, ErrNotFound)
+	}
+	return errors.New(message)
+}
+
+func (s *electrum) GetTransaction(ctx context.Context, txid chainhash.Hash) (*wire.MsgTx, error) {
+	var txHex string
+	if err := s.call(ctx, "blockchain.transaction.get", &txHex, txid.String(), false); err != nil {
+		return nil, err
+	}
+	return decodeTx(txHex, txid)
+}
+
+func (s *electrum) GetPrevout(ctx context.Context, outpoint wire.OutPoint) (*wire.TxOut, error) {
+	return getPrevout(ctx, s, outpoint)
+}
+
+func (s *electrum) GetTipHeight(ctx context.Context) (int64, error) {
+	var header struct {
+		Height int64 `json:"height"`
+	}
+	err := s.call(ctx, "blockchain.headers.subscribe", &header)
+	return header.Height, err
+}
+
+// IsSpent looks for outpoint among the unspent outputs of its script, the
+// server indexes outputs by script only.
+func (s *electrum) IsSpent(ctx context.Context, outpoint wire.OutPoint) (bool, error) {
+	prevout, err := getPrevout(ctx, s, outpoint)
+	if err != nil {
+		return false, err
+	}
+	var unspent []struct {
+		TxHash string `json:"tx_hash"`
+		TxPos  uint32 `json:"tx_pos"`
+	}
+	if err := s.call(ctx, "blockchain.scripthash.listunspent", &unspent, scriptHash(prevout.PkScript)); err != nil {
+		return false, err
+	}
+	for _, u := range unspent {
+		if u.TxHash == outpoint.Hash.String() && u.TxPos == outpoint.Index {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *electrum) Broadcast(ctx context.Context, tx *wire.MsgTx) (chainhash.Hash, error) {
+	txHex, err := encodeTx(tx)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	var txid string
+	if err := s.call(ctx, "blockchain.transaction.broadcast", &txid, txHex); err != nil {
+		return chainhash.Hash{}, err
+	}
+	return checkBroadcast(tx, txid)
+}
+
+// scriptHash is the Electrum index key of script, its reversed SHA256 in
+// hex.
+func scriptHash(script []byte) string {
+	hash := sha256.Sum256(script)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hex.EncodeToString(hash[:])
+}
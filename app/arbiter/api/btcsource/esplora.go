@@ -0,0 +1,79 @@
+// Copyright (c) 2025 The bel2 developers
+
+package btcsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/mempool"
+)
+
+// esplora reads the chain from an Esplora REST API, such as mempool.space,
+// blockstream.info or a self-hosted electrs.
+type esplora struct {
+	url string
+	api *mempool.API
+}
+
+// NewEsplora returns a client of the Esplora API at url, through proxy if
+// it is set, keeping up to cacheSize confirmed transactions in memory.
+func NewEsplora(url, proxy string, cacheSize int) (BTCSource, error) {
+	cfg := mempool.Config{ApiBaseUrl: url, Proxy: proxy, CacheSize: cacheSize}
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	return &esplora{url: url, api: mempool.NewAPI(cfg)}, nil
+}
+
+func (s *esplora) String() string {
+	return "esplora " + s.url
+}
+
+func (s *esplora) GetTransaction(ctx context.Context, txid chainhash.Hash) (*wire.MsgTx, error) {
+	txHex, err := s.api.GetTransactionHex(ctx, txid.String())
+	if err != nil {
+		return nil, s.wrap(err)
+	}
+	return decodeTx(txHex, txid)
+}
+
+func (s *esplora) GetPrevout(ctx context.Context, outpoint wire.OutPoint) (*wire.TxOut, error) {
+	return getPrevout(ctx, s, outpoint)
+}
+
+func (s *esplora) GetTipHeight(ctx context.Context) (int64, error) {
+	return s.api.GetTipHeight(ctx)
+}
+
+func (s *esplora) IsSpent(ctx context.Context, outpoint wire.OutPoint) (bool, error) {
+	outspend, err := s.api.GetOutspend(ctx, outpoint.Hash.String(), outpoint.Index)
+	if err != nil {
+		return false, s.wrap(err)
+	}
+	return outspend.Spent, nil
+}
+
+func (s *esplora) Broadcast(ctx context.Context, tx *wire.MsgTx) (chainhash.Hash, error) {
+	txHex, err := encodeTx(tx)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	txid, err := s.api.Broadcast(ctx, txHex)
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	return checkBroadcast(tx, txid)
+}
+
+// wrap turns the not found error of the API into ErrNotFound.
+func (s *esplora) wrap(err error) error {
+	if errors.Is(err, mempool.ErrNotFound) {
+		return fmt.Errorf("esplora %s: %w", s.url, ErrNotFound)
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2025 The bel2 developers
+
+// Package btcsource reads the BTC chain and broadcasts transactions through
+// an Esplora API, a bitcoind node or an Electrum server.
+package btcsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Backends.
+const (
+	Esplora  = "esplora"
+	Bitcoind = "bitcoind"
+	Electrum = "electrum"
+)
+
+// ErrNotFound is returned for a transaction or output the backend does not
+// know.
+var ErrNotFound = errors.New("not found")
+
+// BTCSource is a view of the BTC chain.
+type BTCSource interface {
+	// GetTransaction returns the transaction txid from the chain or the
+	// mempool.
+	GetTransaction(ctx context.Context, txid chainhash.Hash) (*wire.MsgTx, error)
+	// GetPrevout returns the output spent by an input.
+	GetPrevout(ctx context.Context, outpoint wire.OutPoint) (*wire.TxOut, error)
+	// GetTipHeight returns the height of the best block.
+	GetTipHeight(ctx context.Context) (int64, error)
+	// IsSpent reports whether a confirmed or mempool transaction spends
+	// outpoint.
+	IsSpent(ctx context.Context, outpoint wire.OutPoint) (bool, error)
+	// Broadcast sends tx to the network and returns its txid.
+	Broadcast(ctx context.Context, tx *wire.MsgTx) (chainhash.Hash, error)
+	// String names the backend and its endpoint for logs, without
+	// credentials.
+	String() string
+}
+
+// Config selects and configures the backend.
+type Config struct {
+	// Esplora, Bitcoind or Electrum, empty means Esplora
+	Backend string
+	// Esplora API root, like "https://mempool.space/api"
+	EsploraURL string
+	// bitcoind JSON-RPC endpoint, with the credentials as user info
+	BitcoindURL string
+	// bitcoind cookie file, used instead of the user info if set
+	BitcoindCookieFile string
+	// Electrum server, "tcp://host:port" or "ssl://host:port"
+	ElectrumAddr string
+	// HTTP or SOCKS5 proxy for the Esplora API
+	Proxy string
+	// confirmed transactions the Esplora client keeps in memory, 0 for none
+	EsploraCacheSize int
+}
+
+// New returns the backend selected by cfg.
+func New(cfg Config) (BTCSource, error) {
+	switch strings.ToLower(cfg.Backend) {
+	case "", Esplora:
+		if cfg.EsploraURL == "" {
+			return nil, errors.New("esplora BTC source needs btcApi")
+		}
+		return NewEsplora(cfg.EsploraURL, cfg.Proxy, cfg.EsploraCacheSize)
+	case Bitcoind:
+		return NewBitcoind(cfg.BitcoindURL, cfg.BitcoindCookieFile)
+	case Electrum:
+		return NewElectrum(cfg.ElectrumAddr)
+	}
+	return nil, fmt.Errorf("unknown BTC source %q, need esplora, bitcoind or electrum", cfg.Backend)
+}
+
+// getPrevout looks up the output spent by outpoint with the transaction
+// lookup of a backend.
+func getPrevout(ctx context.Context, s BTCSource, outpoint wire.OutPoint) (*wire.TxOut, error) {
+	tx, err := s.GetTransaction(ctx, outpoint.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if int(outpoint.Index) >= len(tx.TxOut) {
+		return nil, fmt.Errorf("output %s: %w", outpoint, ErrNotFound)
+	}
+	return tx.TxOut[outpoint.Index], nil
+}
+
+// decodeTx decodes a hex serialized transaction and checks that it is
+// txid, a backend must not hand out another transaction.
+func decodeTx(txHex string, txid chainhash.Hash) (*wire.MsgTx, error) {
+	raw, err := hex.DecodeString(strings.TrimSpace(txHex))
+	if err != nil {
+		return nil, fmt.Errorf("decode transaction %s: %w", txid, err)
+	}
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("decode transaction %s: %w", txid, err)
+	}
+	if got := tx.TxHash(); got != txid {
+		return nil, fmt.Errorf("asked for transaction %s, got %s", txid, got)
+	}
+	return tx, nil
+}
+
+func encodeTx(tx *wire.MsgTx) (string, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// checkBroadcast checks that the txid a backend returned for tx is its
+// hash.
+func checkBroadcast(tx *wire.MsgTx, txid string) (chainhash.Hash, error) {
+	hash, err := chainhash.NewHashFromStr(strings.TrimSpace(txid))
+	if err != nil {
+		return chainhash.Hash{}, fmt.Errorf("broadcast returned %q: %w", txid, err)
+	}
+	if *hash != tx.TxHash() {
+		return chainhash.Hash{}, fmt.Errorf("broadcast returned txid %s, sent %s", hash, tx.TxHash())
+	}
+	return *hash, nil
+}
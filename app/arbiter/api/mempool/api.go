@@ -4,85 +4,350 @@ package mempool
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+const (
+	// largest response read, a hex serialized transaction of the maximum
+	// block weight fits
+	maxResponseSize = 8 << 20
+	// longest wait between two attempts, a Retry-After included
+	maxRetryWait = 30 * time.Second
+	// error response body kept in a StatusError
+	maxErrorBody = 512
+)
+
+var (
+	// ErrNotFound is returned when the API does not know the transaction or
+	// output asked for.
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when the API still answers 429 Too Many
+	// Requests after the retries.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrResponseTooLarge is returned for a response over maxResponseSize.
+	ErrResponseTooLarge = errors.New("response too large")
+)
+
+// StatusError is a response with a status other than 200 OK. It matches
+// ErrNotFound for 404 and ErrRateLimited for 429 with errors.Is.
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// start of the response body, the API explains the error there
+	Body string
+	// wait asked for by a Retry-After header, 0 if there is none
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed when retried.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
 type API struct {
-	config Config
+	config  Config
+	client  *http.Client
+	retries int
+	cache   *cache
+	// invalid proxy, returned by every request
+	err error
 }
 
+// NewAPI returns a client of the Esplora API at config.ApiBaseUrl, or of
+// mempool.space for the mainnet and testnet networks if it is empty. An
+// invalid config fails every request, Config.Check reports it up front.
 func NewAPI(config Config) *API {
-	switch strings.ToLower(config.Network) {
-	case "mainnet":
-		config.ApiBaseUrl = Mainnet_ApiBaseUrl
-	case "testnet":
-		config.ApiBaseUrl = Testnet_ApiBaseUrl
+	if config.ApiBaseUrl == "" {
+		switch strings.ToLower(config.Network) {
+		case "mainnet":
+			config.ApiBaseUrl = Mainnet_ApiBaseUrl
+		case "testnet":
+			config.ApiBaseUrl = Testnet_ApiBaseUrl
+		}
+	}
+	if !strings.HasSuffix(config.ApiBaseUrl, "/") {
+		config.ApiBaseUrl += "/"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultRetryBackoff
 	}
-	return &API{config: config}
+	api := &API{config: config, retries: config.MaxRetries}
+	switch {
+	case api.retries == 0:
+		api.retries = DefaultMaxRetries
+	case api.retries < 0:
+		api.retries = 0
+	}
+	if config.CacheSize > 0 {
+		api.cache = newCache(config.CacheSize)
+	}
+	api.client, api.err = api.getClient()
+	return api
 }
 
-func (api *API) GetRawTransaction(txid string) (*GetRawTransactionResponse, error) {
-	buf, err := api.doRequst(txid)
+// GetRawTransaction returns the transaction txid with its prevouts and
+// status. A confirmed one is cached if the cache is enabled.
+func (api *API) GetRawTransaction(ctx context.Context, txid string) (*GetRawTransactionResponse, error) {
+	path := "tx/" + txid
+	body, cached := api.cached(path)
+	if !cached {
+		var err error
+		body, err = api.doRequst(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := GetRawTransactionResponse{}
+	err := json.Unmarshal(body, &response)
 	if err != nil {
 		return nil, err
 	}
-	body := buf.String()
-	fmt.Println(body)
+	if !cached && response.Status.Confirmed {
+		api.store(path, body)
+	}
+	return &response, nil
+}
 
-	response := GetRawTransactionResponse{}
-	err = json.Unmarshal(buf.Bytes(), &response)
+// GetTransactionHex returns the serialized transaction txid in hex. With
+// the cache enabled, the status of the transaction is looked up as well
+// and a confirmed one is kept.
+func (api *API) GetTransactionHex(ctx context.Context, txid string) (string, error) {
+	path := "tx/" + txid + "/hex"
+	if body, ok := api.cached(path); ok {
+		return string(body), nil
+	}
+	body, err := api.doRequst(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	body = bytes.TrimSpace(body)
+	if api.cache != nil {
+		// an unconfirmed transaction may still change its witness
+		if status, err := api.GetTransactionStatus(ctx, txid); err == nil && status.Confirmed {
+			api.store(path, body)
+		}
+	}
+	return string(body), nil
+}
+
+// GetTransactionStatus returns the confirmation status of txid.
+func (api *API) GetTransactionStatus(ctx context.Context, txid string) (*TransactionStatus, error) {
+	body, err := api.doRequst(ctx, http.MethodGet, "tx/"+txid+"/status", nil)
 	if err != nil {
 		return nil, err
 	}
+	response := TransactionStatus{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
 	return &response, nil
 }
 
-func (api *API) doRequst(url string) (*bytes.Buffer, error) {
+// GetOutspend returns the spending status of output vout of txid.
+func (api *API) GetOutspend(ctx context.Context, txid string, vout uint32) (*OutspendResponse, error) {
+	body, err := api.doRequst(ctx, http.MethodGet, "tx/"+txid+"/outspend/"+strconv.FormatUint(uint64(vout), 10), nil)
+	if err != nil {
+		return nil, err
+	}
+	response := OutspendResponse{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetTipHeight returns the height of the best block.
+func (api *API) GetTipHeight(ctx context.Context) (int64, error) {
+	body, err := api.doRequst(ctx, http.MethodGet, "blocks/tip/height", nil)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(bytes.TrimSpace(body)), 10, 64)
+}
+
+// Broadcast sends the hex serialized transaction to the network and
+// returns its txid. Sending a transaction twice is harmless, so a failed
+// broadcast is retried like any other request.
+func (api *API) Broadcast(ctx context.Context, txHex string) (string, error) {
+	body, err := api.doRequst(ctx, http.MethodPost, "tx", []byte(txHex))
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(body)), nil
+}
+
+func (api *API) cached(path string) ([]byte, bool) {
+	if api.cache == nil {
+		return nil, false
+	}
+	return api.cache.get(path)
+}
+
+func (api *API) store(path string, body []byte) {
+	if api.cache != nil {
+		api.cache.put(path, body)
+	}
+}
+
+// doRequst runs the request, retrying with an exponential backoff while it
+// fails in transit or is answered 429 or 5xx.
+func (api *API) doRequst(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	if api.err != nil {
+		return nil, api.err
+	}
+	for attempt := 0; ; attempt++ {
+		response, err := api.doOnce(ctx, method, path, body)
+		if err == nil || attempt >= api.retries || !retryable(err) || ctx.Err() != nil {
+			return response, err
+		}
+		wait := api.config.RetryBackoff << attempt
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (api *API) doOnce(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
 	headersParams := api.getHeaders()
-	req := api.getRequest(headersParams, url)
+	req, err := api.getRequest(ctx, method, headersParams, path, reader)
+	if err != nil {
+		return nil, err
+	}
 
-	client := api.getClient()
-	resp, err := client.Do(req)
+	resp, err := api.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	buf := new(bytes.Buffer)
-	_, _ = buf.ReadFrom(resp.Body)
+	response, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Body:       errorBody(response),
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	if len(response) > maxResponseSize {
+		return nil, fmt.Errorf("%s %s: %w", method, path, ErrResponseTooLarge)
+	}
+
+	return response, nil
+}
+
+// retryable reports whether err is a temporary status or a transport
+// failure.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
 
-	return buf, nil
+func errorBody(body []byte) string {
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > maxErrorBody {
+		msg = msg[:maxErrorBody] + "..."
+	}
+	return msg
 }
 
-func (api *API) getRequest(headersParams map[string]string, url string) *http.Request {
-	req, err := http.NewRequest("GET", getRequestUrl(api.config.ApiBaseUrl, url), bytes.NewBuffer([]byte{}))
+// retryAfter parses a Retry-After header in seconds or as an HTTP date.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+func (api *API) getRequest(ctx context.Context, method string, headersParams map[string]string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, getRequestUrl(api.config.ApiBaseUrl, path), body)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	for key, value := range headersParams {
 		req.Header.Set(key, value)
 	}
 
-	return req
+	return req, nil
 }
 
-func (api *API) getClient() *http.Client {
-	client := &http.Client{}
+func (api *API) getClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	client := &http.Client{Timeout: api.config.Timeout, Transport: transport}
 
-	if api.config.Proxy != "" {
-		proxyUrl, _ := url.Parse(api.config.Proxy)
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-		client.Transport = transport
+	proxyUrl, err := parseProxy(api.config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	if proxyUrl != nil {
+		transport.Proxy = http.ProxyURL(proxyUrl)
 	}
 
-	return client
+	return client, nil
 }
 
 func (api *API) getHeaders() map[string]string {
@@ -93,8 +358,7 @@ func (api *API) getHeaders() map[string]string {
 }
 
 func getRequestUrl(apiBaseUrl string, api string) string {
-	u, _ := url.Parse(apiBaseUrl + api)
-	return u.String()
+	return apiBaseUrl + api
 }
 
 func (api *API) getUrl(url string, request map[string]interface{}) string {
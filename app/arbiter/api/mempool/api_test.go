@@ -3,16 +3,171 @@
 package mempool
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestGetFee(t *testing.T) {
 	t.Skip()
 	api := NewAPI(DefaultConfig)
-	response, err := api.GetRawTransaction("ad0d072993e4f482fe97097d3cdec2ba24068e07028f892ee39678f9e20c9f36")
+	response, err := api.GetRawTransaction(context.Background(), "ad0d072993e4f482fe97097d3cdec2ba24068e07028f892ee39678f9e20c9f36")
 	if err != nil {
 		t.Error(err)
 	}
 	fmt.Println(response)
 }
+
+func testAPI(url string) *API {
+	return NewAPI(Config{ApiBaseUrl: url, RetryBackoff: time.Millisecond, CacheSize: 10})
+}
+
+func TestStatusErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch r.URL.Path {
+		case "/tx/missing/hex":
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+		case "/blocks/tip/height":
+			if n < 3 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("850000"))
+		case "/tx":
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+	api := testAPI(srv.URL)
+	ctx := context.Background()
+
+	_, err := api.GetTransactionHex(ctx, "missing")
+	var statusErr *StatusError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &statusErr) || statusErr.Body != "Transaction not found" {
+		t.Errorf("not found: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("404 retried, %d calls", calls.Load())
+	}
+
+	calls.Store(0)
+	if height, err := api.GetTipHeight(ctx); err != nil || height != 850000 {
+		t.Errorf("retried 503: %d %v", height, err)
+	}
+
+	calls.Store(0)
+	if _, err := api.Broadcast(ctx, "00"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("429: %v", err)
+	}
+	if calls.Load() != DefaultMaxRetries+1 {
+		t.Errorf("429 tried %d times", calls.Load())
+	}
+}
+
+func TestLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tx/large/hex":
+			w.Write(bytes.Repeat([]byte("0"), maxResponseSize+1))
+		case "/tx/slow/hex":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := testAPI(srv.URL).GetTransactionHex(ctx, "large"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("large response: %v", err)
+	}
+	api := NewAPI(Config{ApiBaseUrl: srv.URL, Timeout: 20 * time.Millisecond, MaxRetries: -1})
+	if _, err := api.GetTransactionHex(ctx, "slow"); err == nil {
+		t.Error("no timeout")
+	}
+}
+
+func TestCacheConfirmed(t *testing.T) {
+	requests := make(map[string]int)
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/tx/"), "/")
+		switch parts[1] {
+		case "hex":
+			w.Write([]byte("0200\n"))
+		case "status":
+			fmt.Fprintf(w, `{"confirmed":%v}`, parts[0] == "confirmed")
+		}
+	}))
+	defer srv.Close()
+	api := testAPI(srv.URL)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		for _, txid := range []string{"confirmed", "unconfirmed"} {
+			if txHex, err := api.GetTransactionHex(ctx, txid); err != nil || txHex != "0200" {
+				t.Fatalf("%s: %q %v", txid, txHex, err)
+			}
+		}
+	}
+	if n := requests["/tx/confirmed/hex"]; n != 1 {
+		t.Errorf("confirmed transaction fetched %d times", n)
+	}
+	if n := requests["/tx/unconfirmed/hex"]; n != 2 {
+		t.Errorf("unconfirmed transaction fetched %d times", n)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	var proxied atomic.Bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.Host == "esplora.invalid")
+		w.Write([]byte("850000"))
+	}))
+	defer proxy.Close()
+
+	api := NewAPI(Config{ApiBaseUrl: "http://esplora.invalid/api", Proxy: proxy.URL})
+	if height, err := api.GetTipHeight(context.Background()); err != nil || height != 850000 || !proxied.Load() {
+		t.Errorf("proxied request: %d %v", height, err)
+	}
+
+	bad := Config{ApiBaseUrl: "http://esplora.invalid/api", Proxy: "127.0.0.1:9050"}
+	if err := bad.Check(); err == nil {
+		t.Error("invalid proxy accepted")
+	}
+	if _, err := NewAPI(bad).GetTipHeight(context.Background()); err == nil {
+		t.Error("request with an invalid proxy")
+	}
+}
+
+func TestGetRawTransactionCancel(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	api := NewAPI(Config{ApiBaseUrl: srv.URL, RetryBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := api.GetRawTransaction(ctx, "abc"); err == nil {
+		t.Fatal("no error")
+	}
+	if time.Since(start) > 10*time.Second || calls.Load() != 1 {
+		t.Errorf("cancelled retry took %s and %d calls", time.Since(start), calls.Load())
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 The bel2 developers
+
+package mempool
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cache keeps the most recently used responses, by request path. Only
+// responses that never change, those of confirmed transactions, go in.
+type cache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	path string
+	body []byte
+}
+
+func newCache(size int) *cache {
+	return &cache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *cache) get(path string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[path]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).body, true
+}
+
+func (c *cache) put(path string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[path]; ok {
+		e.Value.(*cacheEntry).body = body
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[path] = c.order.PushFront(&cacheEntry{path: path, body: body})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).path)
+	}
+}
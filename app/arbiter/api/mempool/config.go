@@ -2,16 +2,78 @@
 
 package mempool
 
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
 type Config struct {
 	Network string
 
+	// root of the Esplora API, like "https://mempool.space/api/"
 	ApiBaseUrl string
-	Proxy      string
+	// HTTP or SOCKS5 proxy, like "socks5://127.0.0.1:9050", empty uses the
+	// proxy environment variables
+	Proxy string
+
+	// time limit of one request, 0 means DefaultTimeout
+	Timeout time.Duration
+	// retries of a request that failed in transit or was answered 429 or
+	// 5xx, 0 means DefaultMaxRetries and a negative number none
+	MaxRetries int
+	// wait before the first retry, doubled for every further one, 0 means
+	// DefaultRetryBackoff
+	RetryBackoff time.Duration
+	// confirmed transactions kept in memory, 0 disables the cache
+	CacheSize int
 }
 
-const Mainnet_ApiBaseUrl = "https://mempool.space/api/tx/"
-const Testnet_ApiBaseUrl = "https://mempool.space/testnet/api/tx/"
+const Mainnet_ApiBaseUrl = "https://mempool.space/api/"
+const Testnet_ApiBaseUrl = "https://mempool.space/testnet/api/"
+
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 500 * time.Millisecond
+)
 
 var DefaultConfig = Config{
 	ApiBaseUrl: Testnet_ApiBaseUrl,
 }
+
+// Check validates the API and proxy URLs.
+func (c Config) Check() error {
+	if _, err := parseURL(c.ApiBaseUrl, "http", "https"); err != nil {
+		return fmt.Errorf("invalid BTC API URL: %w", err)
+	}
+	if _, err := parseProxy(c.Proxy); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseProxy returns the proxy URL, nil if proxy is empty.
+func parseProxy(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	u, err := parseURL(proxy, "http", "https", "socks5", "socks5h")
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	return u, nil
+}
+
+func parseURL(rawURL string, schemes ...string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme && u.Host != "" {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("%q needs a host and one of the schemes %v", u.Redacted(), schemes)
+}
@@ -28,14 +28,26 @@ type GetRawTransactionResponse struct {
 		ScriptpubkeyAddress string `json:"scriptpubkey_address"`
 		Value               int64  `json:"value"`
 	} `json:"vout"`
-	Size   int64 `json:"size"`
-	Weight int64 `json:"weight"`
-	Sigops int64 `json:"sigops"`
-	Fee    int64 `json:"fee"`
+	Size   int64             `json:"size"`
+	Weight int64             `json:"weight"`
+	Sigops int64             `json:"sigops"`
+	Fee    int64             `json:"fee"`
+	Status TransactionStatus `json:"status"`
+}
+
+type TransactionStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   int64  `json:"block_time"`
+}
+
+type OutspendResponse struct {
+	Spent  bool   `json:"spent"`
+	Txid   string `json:"txid"`
+	Vin    int    `json:"vin"`
 	Status struct {
-		Confirmed   bool   `json:"confirmed"`
-		BlockHeight int64  `json:"block_height"`
-		BlockHash   string `json:"block_hash"`
-		BlockTime   int64  `json:"block_time"`
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int64 `json:"block_height"`
 	} `json:"status"`
 }
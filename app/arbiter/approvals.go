@@ -0,0 +1,248 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/glog"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const approvalsUsage = `Usage: arbiter approvals <command> [<key>] [flags] [run flags]
+
+Reviews the requests held for approval through the admin API of the
+running arbiter, configured by adminApiAddr and adminApiToken. Accepts the
+flags and ARBITER_* environment variables of "arbiter run".
+
+Commands:
+  list            requests awaiting approval
+  show <key>      summary and review state of a request
+  approve <key>   sign the request
+  reject <key>    cancel the request
+
+Flags:
+  -by <name>        reviewer recorded with the decision (default: $USER)
+  -reason <text>    reason recorded with the decision
+  -yes              do not ask for confirmation
+`
+
+// approvalRequest is the part of an admin API request view shown by the
+// approvals command.
+type approvalRequest struct {
+	Key       string      `json:"key"`
+	TxId      string      `json:"txId"`
+	State     queue.State `json:"state"`
+	LastError string      `json:"lastError"`
+	Dapp      string      `json:"dapp"`
+	Deadline  *time.Time  `json:"deadline"`
+	Approval  *struct {
+		Summary     *arbiter.RequestSummary `json:"summary"`
+		RequestedAt time.Time               `json:"requestedAt"`
+		Decision    string                  `json:"decision"`
+		DecidedBy   string                  `json:"decidedBy"`
+		DecidedAt   *time.Time              `json:"decidedAt"`
+		Reason      string                  `json:"reason"`
+	} `json:"approval"`
+}
+
+func runApprovals(args []string) error {
+	if len(args) == 0 {
+		fmt.Print(approvalsUsage)
+		return errors.New("missing approvals command")
+	}
+	command, args := strings.ToLower(args[0]), args[1:]
+	var key string
+	switch command {
+	case "list":
+	case "show", "approve", "reject":
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+			return fmt.Errorf("approvals %s needs a request key", command)
+		}
+		key, args = args[0], args[1:]
+	default:
+		fmt.Print(approvalsUsage)
+		return fmt.Errorf("unknown approvals command %q", command)
+	}
+
+	f := newRunFlags("approvals " + command)
+	f.fs.Usage = func() {
+		fmt.Print(approvalsUsage)
+		printRunUsage()
+	}
+	by := f.fs.String("by", os.Getenv("USER"), "reviewer recorded with the decision")
+	reason := f.fs.String("reason", "", "reason recorded with the decision")
+	yes := f.fs.Bool("yes", false, "skip confirmation")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	g.Log().SetLevel(glog.LEVEL_WARN)
+	ctx := gctx.New()
+	if _, err := f.load(ctx); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := newAdminClient(cfg.AdminAPIAddr, cfg.AdminAPIToken)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		var requests []approvalRequest
+		if err := client.do(ctx, http.MethodGet, "/v1/requests?state="+string(queue.StateAwaitingApproval), nil, &requests); err != nil {
+			return err
+		}
+		printApprovals(requests)
+		return nil
+	case "show":
+		var request approvalRequest
+		if err := client.do(ctx, http.MethodGet, "/v1/requests/"+url.PathEscape(key), nil, &request); err != nil {
+			return err
+		}
+		printApproval(&request)
+		return nil
+	}
+
+	var request approvalRequest
+	if err := client.do(ctx, http.MethodGet, "/v1/requests/"+url.PathEscape(key), nil, &request); err != nil {
+		return err
+	}
+	printApproval(&request)
+	if request.State != queue.StateAwaitingApproval {
+		return fmt.Errorf("request is %s, not awaiting approval", request.State)
+	}
+	if !*yes && !confirm(strings.ToUpper(command[:1])+command[1:]+" this request?") {
+		return errors.New("aborted")
+	}
+	body := map[string]string{"by": *by, "reason": *reason}
+	if err := client.do(ctx, http.MethodPost, "/v1/requests/"+url.PathEscape(key)+"/"+command, body, &request); err != nil {
+		return err
+	}
+	fmt.Println("State:       ", request.State)
+	return nil
+}
+
+func printApprovals(requests []approvalRequest) {
+	if len(requests) == 0 {
+		fmt.Println("No requests awaiting approval.")
+		return
+	}
+	fmt.Printf("%-70s %-42s %16s  %s\n", "KEY", "DAPP", "VALUE", "DEADLINE")
+	for _, r := range requests {
+		value, deadline := "", ""
+		if r.Approval != nil && r.Approval.Summary != nil {
+			value = btcutil.Amount(r.Approval.Summary.Value()).String()
+		}
+		if r.Deadline != nil {
+			deadline = fmt.Sprintf("%s (%s left)", r.Deadline.UTC().Format(time.RFC3339), time.Until(*r.Deadline).Round(time.Second))
+		}
+		fmt.Printf("%-70s %-42s %16s  %s\n", r.Key, r.Dapp, value, deadline)
+	}
+}
+
+func printApproval(r *approvalRequest) {
+	fmt.Println("Key:         ", r.Key)
+	fmt.Println("State:       ", r.State)
+	if r.LastError != "" {
+		fmt.Println("Last error:  ", r.LastError)
+	}
+	if r.Approval == nil {
+		fmt.Println("Approval:     not required")
+		return
+	}
+	if r.Approval.Summary != nil {
+		fmt.Print(r.Approval.Summary.Text())
+	}
+	fmt.Println("Requested:   ", r.Approval.RequestedAt.UTC().Format(time.RFC3339))
+	if r.Approval.Decision != "" {
+		decision := r.Approval.Decision + " by " + r.Approval.DecidedBy
+		if r.Approval.DecidedAt != nil {
+			decision += " at " + r.Approval.DecidedAt.UTC().Format(time.RFC3339)
+		}
+		if r.Approval.Reason != "" {
+			decision += ": " + r.Approval.Reason
+		}
+		fmt.Println("Decision:    ", decision)
+	}
+}
+
+// adminClient calls the admin API of the running arbiter.
+type adminClient struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// newAdminClient returns a client of the admin API listening on addr, a
+// TCP address or a "unix:<path>" socket.
+func newAdminClient(addr, token string) (*adminClient, error) {
+	if addr == "" {
+		return nil, errors.New("the admin API is disabled, set adminApiAddr")
+	}
+	c := &adminClient{base: "http://" + addr, token: token, client: &http.Client{Timeout: 30 * time.Second}}
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		c.base = "http://arbiter"
+		c.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}
+	}
+	return c, nil
+}
+
+func (c *adminClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("admin API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error == "" {
+			return fmt.Errorf("admin API: %s", resp.Status)
+		}
+		return fmt.Errorf("admin API: %s", apiErr.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
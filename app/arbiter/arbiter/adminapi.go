@@ -0,0 +1,484 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	// prefix of an admin API address that is a Unix socket path
+	unixAddrPrefix = "unix:"
+	// time given to open admin connections on shutdown
+	adminShutdownTimeout = 5 * time.Second
+	// largest request body accepted by the admin API
+	adminMaxBody = 1 << 16
+)
+
+// ValidateAdminAPI checks that an admin API listening on addr is protected:
+// anything but a Unix socket or a loopback address needs a token.
+func ValidateAdminAPI(addr, token string) error {
+	if addr == "" || token != "" || strings.HasPrefix(addr, unixAddrPrefix) {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid admin API address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("admin API address %q is not local, an admin API token is required", addr)
+}
+
+// serveAdminAPI serves the admin API on AdminAPIAddr until shutdown.
+func (v *Arbiter) serveAdminAPI() error {
+	listener, err := listenAdmin(v.config.AdminAPIAddr)
+	if err != nil {
+		return err
+	}
+	api := &adminAPI{
+		ctx:     v.ctx,
+		queue:   v.queue,
+		loanABI: v.escNode.Loan_abi,
+		token:   v.config.AdminAPIToken,
+		// without a token, a page in a browser could reach a loopback
+		// address through DNS rebinding
+		checkHost: v.config.AdminAPIToken == "" && !strings.HasPrefix(v.config.AdminAPIAddr, unixAddrPrefix),
+	}
+	if v.config.Listener {
+		api.rescan = v.escNode.Rescan
+	}
+	server := &http.Server{
+		Handler:           api.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		g.Log().Info(v.ctx, "admin API listening on", v.config.AdminAPIAddr)
+		errCh <- server.Serve(listener)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-v.ctx.Done():
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), adminShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// listenAdmin listens on a TCP address or on a "unix:<path>" socket that is
+// only accessible to the arbiter user.
+func listenAdmin(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixAddrPrefix) {
+		return net.Listen("tcp", addr)
+	}
+	path := strings.TrimPrefix(addr, unixAddrPrefix)
+	// left behind by a previous run that did not shut down
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// adminAPI is the HTTP/JSON API operators use to inspect the request queue
+// and to act on single requests.
+type adminAPI struct {
+	ctx     context.Context
+	queue   *queue.Queue
+	loanABI abi.ABI
+	// required as bearer token if set
+	token string
+	// accept only loopback names in the Host header
+	checkHost bool
+	// nil if the listener is disabled
+	rescan func(height uint64)
+}
+
+// requestView is a queued request with its decoded ArbitrationRequested
+// event.
+type requestView struct {
+	Key          string             `json:"key"`
+	TxId         string             `json:"txId"`
+	State        queue.State        `json:"state"`
+	Attempts     int                `json:"attempts"`
+	LastError    string             `json:"lastError,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	UpdatedAt    time.Time          `json:"updatedAt"`
+	NextAttempt  *time.Time         `json:"nextAttempt,omitempty"`
+	Deadline     *time.Time         `json:"deadline,omitempty"`
+	SubmitTxHash string             `json:"submitTxHash,omitempty"`
+	Block        uint64             `json:"block"`
+	EscTxHash    string             `json:"escTxHash"`
+	Dapp         string             `json:"dapp,omitempty"`
+	Arbitrator   string             `json:"arbitrator,omitempty"`
+	BtcTx        string             `json:"btcTx,omitempty"`
+	Script       string             `json:"script,omitempty"`
+	DecodeError  string             `json:"decodeError,omitempty"`
+	Transitions  []queue.Transition `json:"transitions,omitempty"`
+	Approval     *approvalView      `json:"approval,omitempty"`
+}
+
+// approvalView is the operator review of a request in approval mode.
+type approvalView struct {
+	Summary     *RequestSummary `json:"summary,omitempty"`
+	RequestedAt time.Time       `json:"requestedAt"`
+	Decision    string          `json:"decision,omitempty"`
+	DecidedBy   string          `json:"decidedBy,omitempty"`
+	DecidedAt   *time.Time      `json:"decidedAt,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+	// hash of the BTC transaction an approval allows to sign
+	SigHash string `json:"sigHash,omitempty"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func (a *adminAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/requests", a.handleRequests)
+	mux.HandleFunc("/v1/requests/", a.handleRequest)
+	mux.HandleFunc("/v1/transactions/", a.handleTransaction)
+	mux.HandleFunc("/v1/rescan", a.handleRescan)
+	return a.authenticate(mux)
+}
+
+// authenticate checks the token and turns away requests a browser may have
+// sent on behalf of another site: cross-site requests, requests through a
+// rebound DNS name and POSTs of forms.
+func (a *adminAPI) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkBrowserRequest(r, a.checkHost); err != nil {
+			a.writeError(w, r, http.StatusForbidden, err)
+			return
+		}
+		if a.token != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(a.token)) != 1 {
+				a.writeError(w, r, http.StatusUnauthorized, errors.New("invalid or missing token"))
+				return
+			}
+		}
+		if r.Method == http.MethodPost {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				a.writeError(w, r, http.StatusUnsupportedMediaType, errors.New("use Content-Type application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkBrowserRequest rejects requests with an Origin or Sec-Fetch-Site
+// header of another site and, if checkHost is set, requests for a Host that
+// is not a loopback name. The admin clients send none of these headers.
+func checkBrowserRequest(r *http.Request, checkHost bool) error {
+	switch site := r.Header.Get("Sec-Fetch-Site"); site {
+	case "", "same-origin", "none":
+	default:
+		return fmt.Errorf("%s request rejected", site)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return fmt.Errorf("request from origin %q rejected", origin)
+		}
+	}
+	if checkHost && !isLoopbackHost(r.Host) {
+		return fmt.Errorf("host %q rejected, use a loopback address", r.Host)
+	}
+	return nil
+}
+
+func isLoopbackHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.Trim(hostport, "[]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleRequests lists the requests, optionally filtered by a comma
+// separated state parameter.
+func (a *adminAPI) handleRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.writeError(w, r, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	var states []queue.State
+	if s := r.URL.Query().Get("state"); s != "" {
+		for _, state := range strings.Split(s, ",") {
+			states = append(states, queue.State(strings.TrimSpace(state)))
+		}
+	}
+	items, err := a.queue.List(states...)
+	if err != nil {
+		a.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	views := make([]*requestView, 0, len(items))
+	for _, item := range items {
+		view := a.view(item)
+		// the list stays short, use GET /v1/requests/<key> for the history
+		view.Transitions = nil
+		views = append(views, view)
+	}
+	a.writeJSON(w, r, http.StatusOK, views)
+}
+
+// handleRequest serves GET /v1/requests/<key> and the actions
+// POST /v1/requests/<key>/retry, /cancel, /approve and /reject.
+func (a *adminAPI) handleRequest(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v1/requests/")
+	rawKey, action, _ := strings.Cut(path, "/")
+	key, err := queue.ParseKey(rawKey)
+	if err != nil {
+		a.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+	case action == "retry" && r.Method == http.MethodPost:
+		err = a.queue.Retry(key)
+	case action == "cancel" && r.Method == http.MethodPost:
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := a.readJSON(r, &body); err != nil {
+			a.writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if body.Reason == "" {
+			body.Reason = "cancelled by operator"
+		}
+		err = a.queue.Cancel(key, body.Reason)
+	case (action == "approve" || action == "reject") && r.Method == http.MethodPost:
+		var body struct {
+			By     string `json:"by"`
+			Reason string `json:"reason"`
+		}
+		if err := a.readJSON(r, &body); err != nil {
+			a.writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if body.By == "" {
+			body.By = "admin API"
+		}
+		if action == "approve" {
+			err = a.queue.Approve(key, body.By, body.Reason)
+		} else {
+			err = a.queue.Reject(key, body.By, body.Reason)
+		}
+	default:
+		a.writeError(w, r, http.StatusNotFound, fmt.Errorf("no %s action %q", r.Method, action))
+		return
+	}
+	if err != nil {
+		a.writeQueueError(w, r, err)
+		return
+	}
+	if action != "" {
+		g.Log().Notice(a.ctx, "admin API", action, "request", key, "from", r.RemoteAddr)
+	}
+
+	item, err := a.queue.Get(key)
+	if err != nil {
+		a.writeQueueError(w, r, err)
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, a.view(item))
+}
+
+// handleTransaction serves GET /v1/transactions/<txId>, every request of
+// the arbitration with its state history.
+func (a *adminAPI) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.writeError(w, r, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	raw := strings.TrimPrefix(r.URL.Path, "/v1/transactions/")
+	id, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+	if err != nil || len(id) != common.HashLength {
+		a.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid txId %q", raw))
+		return
+	}
+	txId := common.BytesToHash(id)
+
+	items, err := a.queue.List()
+	if err != nil {
+		a.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	var views []*requestView
+	for _, item := range items {
+		if len(item.Event.Topics) > 1 && item.Event.Topics[1] == txId {
+			views = append(views, a.view(item))
+		}
+	}
+	if len(views) == 0 {
+		a.writeError(w, r, http.StatusNotFound, fmt.Errorf("no request for txId %s", txId.String()))
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, views)
+}
+
+// handleRescan serves POST /v1/rescan with a {"height": <block>} body.
+func (a *adminAPI) handleRescan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		a.writeError(w, r, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+	if a.rescan == nil {
+		a.writeError(w, r, http.StatusConflict, errors.New("listener is disabled"))
+		return
+	}
+	var body struct {
+		Height *uint64 `json:"height"`
+	}
+	if err := a.readJSON(r, &body); err != nil {
+		a.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if body.Height == nil {
+		a.writeError(w, r, http.StatusBadRequest, errors.New("missing height"))
+		return
+	}
+	g.Log().Notice(a.ctx, "admin API rescan from block", *body.Height, "from", r.RemoteAddr)
+	a.rescan(*body.Height)
+	a.writeJSON(w, r, http.StatusAccepted, map[string]uint64{"height": *body.Height})
+}
+
+func (a *adminAPI) view(item *queue.Item) *requestView {
+	event := item.Event
+	view := &requestView{
+		Key:         item.Key.String(),
+		State:       item.State,
+		Attempts:    item.Attempts,
+		LastError:   item.LastError,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+		Block:       event.Block,
+		EscTxHash:   event.TxHash.String(),
+		Transitions: item.Transitions,
+	}
+	if !item.NextAttempt.IsZero() {
+		view.NextAttempt = &item.NextAttempt
+	}
+	if !item.Deadline.IsZero() {
+		view.Deadline = &item.Deadline
+	}
+	if item.SubmitTxHash != (common.Hash{}) {
+		view.SubmitTxHash = item.SubmitTxHash.String()
+	}
+	if approval := item.Approval; approval != nil {
+		view.Approval = &approvalView{
+			RequestedAt: approval.RequestedAt,
+			SigHash:     approval.SigHash.Hex(),
+			Decision:    approval.Decision,
+			DecidedBy:   approval.DecidedBy,
+			Reason:      approval.Reason,
+		}
+		if !approval.DecidedAt.IsZero() {
+			view.Approval.DecidedAt = &approval.DecidedAt
+		}
+		if len(approval.Summary) > 0 {
+			summary := &RequestSummary{}
+			if err := json.Unmarshal(approval.Summary, summary); err == nil {
+				view.Approval.Summary = summary
+			}
+		}
+	}
+	if len(event.Topics) > 2 {
+		view.TxId = event.Topics[1].String()
+		view.Dapp = common.BytesToAddress(event.Topics[2].Bytes()).String()
+	}
+
+	ev := make(map[string]interface{})
+	if err := a.loanABI.UnpackIntoMap(ev, "ArbitrationRequested", event.EventData); err != nil {
+		view.DecodeError = err.Error()
+		return view
+	}
+	if btcTx, ok := ev["btcTx"].([]byte); ok {
+		view.BtcTx = hex.EncodeToString(btcTx)
+	}
+	if script, ok := ev["script"].([]byte); ok {
+		view.Script = hex.EncodeToString(script)
+	}
+	if arbitrator, ok := ev["arbitrator"].(common.Address); ok {
+		view.Arbitrator = arbitrator.String()
+	}
+	return view
+}
+
+func (a *adminAPI) readJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, adminMaxBody)).Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func (a *adminAPI) writeQueueError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, queue.ErrNotFound):
+		a.writeError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, queue.ErrInvalidState):
+		a.writeError(w, r, http.StatusConflict, err)
+	default:
+		a.writeError(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+func (a *adminAPI) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	a.writeJSON(w, r, status, apiError{Error: err.Error()})
+}
+
+func (a *adminAPI) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		g.Log().Warning(r.Context(), "write admin API response error", err, "path:", r.URL.Path)
+	}
+}
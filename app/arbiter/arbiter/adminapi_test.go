@@ -0,0 +1,245 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+func newTestAdminAPI(t *testing.T) (*adminAPI, *queue.Item) {
+	t.Helper()
+	loanABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := queue.Open(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+
+	arbitrator := common.HexToAddress("0x0262aB0ED65373cC855C34529fDdeAa0e686D913")
+	data, err := loanABI.Events["ArbitrationRequested"].Inputs.NonIndexed().Pack(
+		arbitrator, []byte{0x02, 0x00}, []byte{0x51}, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &events.ContractLogEvent{
+		EventData: data,
+		TxHash:    common.Hash{1},
+		Topics:    []common.Hash{events.ArbitrationRequested, {0xaa}, common.BytesToHash(arbitrator.Bytes())},
+		Block:     100,
+	}
+	if _, err := q.Enqueue(event); err != nil {
+		t.Fatal(err)
+	}
+	item, err := q.Get(queue.KeyOf(event))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &adminAPI{ctx: context.Background(), queue: q, loanABI: loanABI}, item
+}
+
+func doAdmin(t *testing.T, api *adminAPI, method, path, body string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return serveAdmin(t, api, req, out)
+}
+
+func serveAdmin(t *testing.T, api *adminAPI, req *http.Request, out interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, req)
+	if out != nil && rec.Code < 300 {
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAdminAPIRequests(t *testing.T) {
+	api, item := newTestAdminAPI(t)
+	path := "/v1/requests/" + item.Key.String()
+
+	var list []requestView
+	if code := doAdmin(t, api, http.MethodGet, "/v1/requests?state=pending", "", &list); code != http.StatusOK {
+		t.Fatalf("list: %d", code)
+	}
+	if len(list) != 1 || list[0].BtcTx != "0200" || list[0].Script != "51" || list[0].DecodeError != "" {
+		t.Fatalf("list: %+v", list)
+	}
+
+	if code := doAdmin(t, api, http.MethodPost, path+"/retry", "", nil); code != http.StatusConflict {
+		t.Fatalf("retry pending: %d", code)
+	}
+	var view requestView
+	if code := doAdmin(t, api, http.MethodPost, path+"/cancel", `{"reason":"duplicate"}`, &view); code != http.StatusOK {
+		t.Fatalf("cancel: %d", code)
+	}
+	if view.State != queue.StateCancelled || view.LastError != "duplicate" {
+		t.Fatalf("cancel: %+v", view)
+	}
+	if code := doAdmin(t, api, http.MethodPost, path+"/retry", "", &view); code != http.StatusOK || view.State != queue.StatePending {
+		t.Fatalf("retry cancelled: %d %+v", code, view)
+	}
+
+	var history []requestView
+	txId := common.Hash{0xaa}.String()
+	if code := doAdmin(t, api, http.MethodGet, "/v1/transactions/"+txId, "", &history); code != http.StatusOK {
+		t.Fatalf("transaction: %d", code)
+	}
+	if len(history) != 1 || len(history[0].Transitions) != 3 {
+		t.Fatalf("transaction: %+v", history)
+	}
+	if code := doAdmin(t, api, http.MethodGet, "/v1/transactions/"+common.Hash{0xbb}.String(), "", nil); code != http.StatusNotFound {
+		t.Fatalf("unknown transaction: %d", code)
+	}
+}
+
+func TestAdminAPIApproval(t *testing.T) {
+	api, item := newTestAdminAPI(t)
+	path := "/v1/requests/" + item.Key.String()
+
+	if code := doAdmin(t, api, http.MethodPost, path+"/approve", "", nil); code != http.StatusConflict {
+		t.Fatalf("approve pending: %d", code)
+	}
+	if err := api.queue.AwaitApproval(item.Key, []byte(`{"dapp":"0x00000000000000000000000000000000000000d1","inputValue":5000}`), common.Hash{0x51}); err != nil {
+		t.Fatal(err)
+	}
+	var list []requestView
+	if code := doAdmin(t, api, http.MethodGet, "/v1/requests?state=awaiting_approval", "", &list); code != http.StatusOK {
+		t.Fatalf("list: %d", code)
+	}
+	if len(list) != 1 || list[0].Approval == nil || list[0].Approval.Summary == nil || list[0].Approval.Summary.Value() != 5000 || list[0].Approval.SigHash != (common.Hash{0x51}).Hex() {
+		t.Fatalf("list: %+v", list)
+	}
+
+	var view requestView
+	if code := doAdmin(t, api, http.MethodPost, path+"/reject", `{"by":"alice","reason":"unknown payout"}`, &view); code != http.StatusOK {
+		t.Fatalf("reject: %d", code)
+	}
+	if view.State != queue.StateCancelled || view.Approval.Decision != queue.Rejected || view.Approval.DecidedBy != "alice" || view.Approval.DecidedAt == nil {
+		t.Fatalf("reject: %+v %+v", view, view.Approval)
+	}
+
+	if err := api.queue.Retry(item.Key); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.queue.AwaitApproval(item.Key, nil, common.Hash{0x51}); err != nil {
+		t.Fatal(err)
+	}
+	if code := doAdmin(t, api, http.MethodPost, path+"/approve", "", &view); code != http.StatusOK {
+		t.Fatalf("approve: %d", code)
+	}
+	if view.State != queue.StatePending || view.Approval.Decision != queue.Approved || view.Approval.DecidedBy != "admin API" {
+		t.Fatalf("approve: %+v %+v", view, view.Approval)
+	}
+}
+
+func TestAdminAPIRescan(t *testing.T) {
+	api, _ := newTestAdminAPI(t)
+	if code := doAdmin(t, api, http.MethodPost, "/v1/rescan", `{"height":5}`, nil); code != http.StatusConflict {
+		t.Fatalf("rescan without listener: %d", code)
+	}
+
+	var got uint64
+	api.rescan = func(height uint64) { got = height }
+	if code := doAdmin(t, api, http.MethodPost, "/v1/rescan", `{}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("rescan without height: %d", code)
+	}
+	if code := doAdmin(t, api, http.MethodPost, "/v1/rescan", `{"height":5}`, nil); code != http.StatusAccepted || got != 5 {
+		t.Fatalf("rescan: %d, height %d", code, got)
+	}
+}
+
+func TestAdminAPIToken(t *testing.T) {
+	api, _ := newTestAdminAPI(t)
+	api.token = "other"
+	if code := doAdmin(t, api, http.MethodGet, "/v1/requests", "", nil); code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: %d", code)
+	}
+	api.token = "secret"
+	if code := doAdmin(t, api, http.MethodGet, "/v1/requests", "", nil); code != http.StatusOK {
+		t.Fatalf("token: %d", code)
+	}
+}
+
+func TestAdminAPIBrowserRequests(t *testing.T) {
+	api, item := newTestAdminAPI(t)
+	api.checkHost = true
+	path := "/v1/requests/" + item.Key.String() + "/cancel"
+
+	for _, tc := range []struct {
+		name    string
+		host    string
+		headers map[string]string
+		code    int
+	}{
+		{"form post", "127.0.0.1:9181", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusUnsupportedMediaType},
+		{"text post", "127.0.0.1:9181", map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"no content type", "127.0.0.1:9181", nil, http.StatusUnsupportedMediaType},
+		{"cross-site", "127.0.0.1:9181", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"same-site", "127.0.0.1:9181", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "same-site"}, http.StatusForbidden},
+		{"foreign origin", "127.0.0.1:9181", map[string]string{"Content-Type": "application/json", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"rebound host", "evil.example:9181", map[string]string{"Content-Type": "application/json"}, http.StatusForbidden},
+		{"same origin", "localhost:9181", map[string]string{"Content-Type": "application/json", "Origin": "http://localhost:9181", "Sec-Fetch-Site": "same-origin"}, http.StatusOK},
+		{"ipv6 loopback", "[::1]:9181", map[string]string{"Content-Type": "application/json; charset=utf-8"}, http.StatusConflict},
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}"))
+		req.Host = tc.host
+		req.Header.Set("Authorization", "Bearer secret")
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+		if code := serveAdmin(t, api, req, nil); code != tc.code {
+			t.Errorf("%s: %d, want %d", tc.name, code, tc.code)
+		}
+	}
+
+	// a token stands in for the host check
+	api.checkHost = false
+	req := httptest.NewRequest(http.MethodGet, "/v1/requests", nil)
+	req.Host = "arbiter"
+	if code := serveAdmin(t, api, req, nil); code != http.StatusOK {
+		t.Errorf("socket host: %d", code)
+	}
+}
+
+func TestValidateAdminAPI(t *testing.T) {
+	for _, tc := range []struct {
+		addr, token string
+		ok          bool
+	}{
+		{"", "", true},
+		{"127.0.0.1:9181", "", true},
+		{"[::1]:9181", "", true},
+		{"localhost:9181", "", true},
+		{"unix:/run/arbiter/admin.sock", "", true},
+		{"0.0.0.0:9181", "", false},
+		{"10.0.0.5:9181", "", false},
+		{"0.0.0.0:9181", "secret", true},
+		{"9181", "", false},
+	} {
+		if err := ValidateAdminAPI(tc.addr, tc.token); (err == nil) != tc.ok {
+			t.Errorf("ValidateAdminAPI(%q, %q) = %v", tc.addr, tc.token, err)
+		}
+	}
+}
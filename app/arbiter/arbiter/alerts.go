@@ -0,0 +1,144 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const statusCheckInterval = 5 * time.Minute
+
+// alertConfig returns the alert settings of cfg. The source defaults to
+// the arbiter address and is marked in a dry run.
+func alertConfig(cfg *config.Config) alert.Config {
+	c := cfg.Alert
+	if c.Source == "" {
+		c.Source = cfg.ESCArbiterAddress
+	}
+	if cfg.DryRun {
+		c.Source += " (dry run)"
+	}
+	return c
+}
+
+// requestAlert returns an alert about the arbitration request of item.
+func requestAlert(item *queue.Item, kind string, severity alert.Severity, title string) *alert.Alert {
+	a := &alert.Alert{Kind: kind, Key: item.Key.String(), Severity: severity, Title: title}
+	if item.Event == nil {
+		return a
+	}
+	fields, _ := logging.ArbitrationFrom(logging.WithEvent(context.Background(), item.Event))
+	if fields.TxId != "" {
+		a.Key = fields.TxId
+	}
+	var text []string
+	for _, f := range []struct{ name, value string }{
+		{"txId", fields.TxId},
+		{"dapp", fields.Dapp},
+		{"escTx", fields.EscTx},
+	} {
+		if f.value != "" {
+			text = append(text, f.name+": "+f.value)
+		}
+	}
+	if !item.Deadline.IsZero() {
+		text = append(text, "deadline: "+item.Deadline.UTC().Format(time.RFC3339))
+	}
+	a.Text = strings.Join(text, "\n")
+	return a
+}
+
+// alertQueueChange raises an alert for every request added to the queue.
+func (v *Arbiter) alertQueueChange(item *queue.Item) {
+	if item.State == queue.StatePending && len(item.Transitions) == 1 {
+		v.alerts.Send(requestAlert(item, alert.KindRequest, alert.Info, "new arbitration request"))
+	}
+}
+
+// watchStatus alerts when the operator balance runs low and when the
+// arbitrator is frozen, paused or deactivated in the arbiter manager.
+func (v *Arbiter) watchStatus() error {
+	g.Log().Info(v.ctx, "watchStatus start")
+
+	var last *contract.ArbitratorFlags
+	for {
+		if v.alerts.Enabled() {
+			v.checkOperatorBalance()
+			last = v.checkArbitratorFlags(last)
+		}
+
+		if !v.sleep(statusCheckInterval) {
+			return nil
+		}
+	}
+}
+
+func (v *Arbiter) checkOperatorBalance() {
+	lowBalance := v.cfg().AlertLowBalance
+	if lowBalance == nil || lowBalance.Sign() <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(v.ctx, time.Minute)
+	defer cancel()
+	balance, err := v.escNode.OperatorBalance(ctx)
+	if err != nil {
+		g.Log().Error(v.ctx, "alert OperatorBalance error", err)
+		return
+	}
+	if balance.Cmp(lowBalance) >= 0 {
+		return
+	}
+	g.Log().Warning(v.ctx, "operator balance low:", balance, "wei")
+	v.logger.Warning(v.ctx, "ALERT: operator balance low:", balance.String(), "wei")
+	v.alerts.Send(&alert.Alert{
+		Kind:     alert.KindLowBalance,
+		Key:      v.escNode.GetSubmiterAddress(),
+		Severity: alert.Warning,
+		Title:    "operator gas balance low",
+		Text: fmt.Sprintf("operator %s holds %s wei, below the alert threshold of %s wei",
+			v.escNode.GetSubmiterAddress(), balance, lowBalance),
+	})
+}
+
+// checkArbitratorFlags alerts when the flags differ from last, or on the
+// first check when the arbitrator cannot work. It returns the flags to
+// compare with next time.
+func (v *Arbiter) checkArbitratorFlags(last *contract.ArbitratorFlags) *contract.ArbitratorFlags {
+	ctx, cancel := context.WithTimeout(v.ctx, time.Minute)
+	defer cancel()
+	flags, err := v.escNode.GetArbitratorFlags(ctx)
+	if err != nil {
+		g.Log().Error(v.ctx, "alert GetArbitratorFlags error", err)
+		return last
+	}
+	healthy := flags.Active && !flags.Frozen && !flags.Paused
+	if (last == nil && healthy) || (last != nil && *last == *flags) {
+		return flags
+	}
+
+	text := fmt.Sprintf("arbitrator %s active: %t, frozen: %t, paused: %t",
+		v.config.ESCArbiterAddress, flags.Active, flags.Frozen, flags.Paused)
+	a := &alert.Alert{Kind: alert.KindStatus, Key: text, Severity: alert.Critical, Title: "arbitrator status changed", Text: text}
+	if healthy {
+		a.Severity = alert.Info
+		a.Title = "arbitrator active again"
+		g.Log().Notice(v.ctx, text)
+		v.logger.Notice(v.ctx, "ALERT:", text)
+	} else {
+		g.Log().Critical(v.ctx, text)
+		v.logger.Critical(v.ctx, "ALERT:", text)
+	}
+	v.alerts.Send(a)
+	return flags
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+// approver recorded for requests approved by the deadline safety margin
+const autoApprover = "auto-approve"
+
+// time limit for the BTC source lookups of one request
+const btcLookupTimeout = 30 * time.Second
+
+// RequestSummary describes an arbitration request for an operator review.
+type RequestSummary struct {
+	TxId       common.Hash     `json:"txId"`
+	Dapp       common.Address  `json:"dapp"`
+	Arbitrator common.Address  `json:"arbitrator"`
+	Deadline   time.Time       `json:"deadline"`
+	BtcTxId    string          `json:"btcTxId"`
+	Inputs     []SummaryInput  `json:"inputs"`
+	Outputs    []SummaryOutput `json:"outputs"`
+	// satoshis spent by the inputs whose prevout is on record
+	InputValue  int64 `json:"inputValue"`
+	OutputValue int64 `json:"outputValue"`
+	// inputs whose amount is not known
+	UnknownInputs int `json:"unknownInputs,omitempty"`
+	// result of the automated checks
+	Verdict string `json:"verdict"`
+}
+
+type SummaryInput struct {
+	Outpoint string `json:"outpoint"`
+	Address  string `json:"address,omitempty"`
+	// zero if the prevout is not on record
+	Amount int64 `json:"amount"`
+}
+
+type SummaryOutput struct {
+	Address string `json:"address,omitempty"`
+	Script  string `json:"script"`
+	Amount  int64  `json:"amount"`
+}
+
+// Value returns the satoshis the request moves, the larger of the known
+// input value and the output value.
+func (s *RequestSummary) Value() int64 {
+	if s.InputValue > s.OutputValue {
+		return s.InputValue
+	}
+	return s.OutputValue
+}
+
+// Text formats the summary for a terminal.
+func (s *RequestSummary) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Transaction:  %s\n", s.TxId.Hex())
+	fmt.Fprintf(&b, "Dapp:         %s\n", s.Dapp.Hex())
+	fmt.Fprintf(&b, "Arbitrator:   %s\n", s.Arbitrator.Hex())
+	fmt.Fprintf(&b, "Deadline:     %s (%s left)\n", s.Deadline.UTC().Format(time.RFC3339), time.Until(s.Deadline).Round(time.Second))
+	fmt.Fprintf(&b, "BTC tx:       %s\n", s.BtcTxId)
+	for i, in := range s.Inputs {
+		amount := "unknown amount"
+		if in.Amount > 0 {
+			amount = btcutil.Amount(in.Amount).String()
+		}
+		from := ""
+		if in.Address != "" {
+			from = " from " + in.Address
+		}
+		fmt.Fprintf(&b, "  input[%d]:   %s%s (%s)\n", i, in.Outpoint, from, amount)
+	}
+	for i, out := range s.Outputs {
+		to := out.Address
+		if to == "" {
+			to = "script " + out.Script
+		}
+		fmt.Fprintf(&b, "  output[%d]:  %s to %s\n", i, btcutil.Amount(out.Amount), to)
+	}
+	fmt.Fprintf(&b, "Value:        %s in, %s out\n", btcutil.Amount(s.InputValue), btcutil.Amount(s.OutputValue))
+	fmt.Fprintf(&b, "Verdict:      %s\n", s.Verdict)
+	return b.String()
+}
+
+// summarize describes the arbitration info, which passed the automated
+// checks, with the addresses of the BTC network params.
+func summarize(txId common.Hash, info *contract.TransactionInfo, params *chaincfg.Params) (*RequestSummary, error) {
+	tx, err := decodeTx(info.BtcTx)
+	if err != nil {
+		return nil, fmt.Errorf("decode BTC transaction: %w", err)
+	}
+	s := &RequestSummary{
+		TxId:       txId,
+		Dapp:       info.Dapp,
+		Arbitrator: info.Arbitrator,
+		BtcTxId:    tx.TxHash().String(),
+	}
+	if info.Deadline != nil {
+		s.Deadline = time.Unix(info.Deadline.Int64(), 0)
+	}
+	record := BundleTransaction{}
+	for _, utxo := range info.Utxos {
+		record.Prevouts = append(record.Prevouts, BundlePrevout{TxHash: utxo.TxHash, Index: utxo.Index, Script: utxo.Script, Amount: utxo.Amount})
+	}
+	for _, in := range tx.TxIn {
+		input := SummaryInput{Outpoint: in.PreviousOutPoint.String()}
+		if prevout := record.prevout(in.PreviousOutPoint); prevout != nil {
+			input.Address = scriptAddress(prevout.Script, params)
+			if prevout.Amount != nil {
+				input.Amount = prevout.Amount.Int64()
+			}
+		}
+		if input.Amount == 0 {
+			s.UnknownInputs++
+		}
+		s.InputValue += input.Amount
+		s.Inputs = append(s.Inputs, input)
+	}
+	for _, out := range tx.TxOut {
+		s.Outputs = append(s.Outputs, SummaryOutput{
+			Address: scriptAddress(out.PkScript, params),
+			Script:  fmt.Sprintf("%x", out.PkScript),
+			Amount:  out.Value,
+		})
+		s.OutputValue += out.Value
+	}
+	s.Verdict = fmt.Sprintf("passed: assigned to %s, arbitrated, not signed yet, deadline %s",
+		info.Arbitrator.Hex(), s.Deadline.UTC().Format(time.RFC3339))
+	return s, nil
+}
+
+// scriptAddress returns the address paid by script, or "" if it is not a
+// single address script.
+func scriptAddress(script []byte, params *chaincfg.Params) string {
+	if params == nil {
+		return ""
+	}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, params)
+	if err != nil || len(addrs) != 1 {
+		return ""
+	}
+	return addrs[0].EncodeAddress()
+}
+
+// needsApproval reports whether cfg stops the request s for a review, and
+// why.
+func needsApproval(cfg config.ApprovalConfig, s *RequestSummary) (bool, string) {
+	if cfg.All {
+		return true, "approval required for every request"
+	}
+	for _, dapp := range cfg.Dapps {
+		if common.HexToAddress(dapp) == s.Dapp {
+			return true, "approval required for dapp " + s.Dapp.Hex()
+		}
+	}
+	if cfg.MinValue > 0 && s.Value() >= cfg.MinValue {
+		return true, fmt.Sprintf("approval required from %s, request moves %s", btcutil.Amount(cfg.MinValue), btcutil.Amount(s.Value()))
+	}
+	// the inputs may hold more than the outputs pay
+	if cfg.MinValue > 0 && s.UnknownInputs > 0 {
+		return true, fmt.Sprintf("approval required from %s, amount of %d inputs unknown", btcutil.Amount(cfg.MinValue), s.UnknownInputs)
+	}
+	return false, ""
+}
+
+// holdForApproval stops item for an operator review if the approval
+// config selects it. It returns whether the request waits. An approval
+// covers the BTC transaction reviewed, another one is reviewed again.
+func (v *Arbiter) holdForApproval(logCtx context.Context, item *queue.Item, info *contract.TransactionInfo) (bool, error) {
+	sigHash := SigHash(info.BtcTx)
+	if item.Approved(sigHash) {
+		return false, nil
+	}
+	cfg := v.cfg()
+	if cfg.Approval.All || len(cfg.Approval.Dapps) > 0 || cfg.Approval.MinValue > 0 {
+		info = v.withPrevouts(logCtx, info)
+	}
+	summary, err := summarize(item.Event.Topics[1], info, cfg.BTCParams)
+	if err != nil {
+		return false, permanent(err)
+	}
+	required, reason := needsApproval(cfg.Approval, summary)
+	if !required {
+		return false, nil
+	}
+	content, err := json.Marshal(summary)
+	if err != nil {
+		return false, err
+	}
+	if err := v.queue.AwaitApproval(item.Key, content, sigHash); err != nil {
+		return false, err
+	}
+	g.Log().Notice(logCtx, "request awaiting approval:", reason)
+	if v.config.DryRun {
+		v.reportRequest(item, dryRunApproval, nil, reason)
+	}
+	v.logger.Notice(logCtx, "APPROVAL: awaiting approval,", reason)
+	a := requestAlert(item, alert.KindApproval, alert.Warning, "arbitration request awaiting approval")
+	a.Text = summary.Text() + reason
+	v.alerts.Send(a)
+	return true, nil
+}
+
+// withPrevouts returns info with the prevouts missing from the on-chain
+// record looked up from the BTC source, so that a review shows the amount
+// of every input. Failed lookups leave the input amount unknown.
+func (v *Arbiter) withPrevouts(ctx context.Context, info *contract.TransactionInfo) *contract.TransactionInfo {
+	tx, err := decodeTx(info.BtcTx)
+	if err != nil {
+		return info
+	}
+	record := BundleTransaction{}
+	for _, utxo := range info.Utxos {
+		record.Prevouts = append(record.Prevouts, BundlePrevout{TxHash: utxo.TxHash, Index: utxo.Index})
+	}
+	ctx, cancel := context.WithTimeout(ctx, btcLookupTimeout)
+	defer cancel()
+	var found []contract.UTXO
+	for _, in := range tx.TxIn {
+		if record.prevout(in.PreviousOutPoint) != nil {
+			continue
+		}
+		out, err := v.btc.GetPrevout(ctx, in.PreviousOutPoint)
+		if err != nil {
+			g.Log().Warning(ctx, "look up prevout", in.PreviousOutPoint, "from", v.btc, "error:", err)
+			continue
+		}
+		found = append(found, contract.UTXO{
+			TxHash: in.PreviousOutPoint.Hash,
+			Index:  in.PreviousOutPoint.Index,
+			Script: out.PkScript,
+			Amount: big.NewInt(out.Value),
+		})
+	}
+	if len(found) == 0 {
+		return info
+	}
+	withUtxos := *info
+	withUtxos.Utxos = append(append([]contract.UTXO(nil), info.Utxos...), found...)
+	return &withUtxos
+}
+
+// autoApprove approves the requests awaiting approval whose deadline is
+// within the configured safety margin. It returns how long until the next
+// one is due, or signerIdleDelay.
+func (v *Arbiter) autoApprove(items []*queue.Item, now time.Time) time.Duration {
+	wait := signerIdleDelay
+	margin := v.cfg().Approval.AutoApproveMargin
+	if margin <= 0 {
+		return wait
+	}
+	for _, item := range items {
+		if item.State != queue.StateAwaitingApproval || item.Deadline.IsZero() {
+			continue
+		}
+		if at := item.Deadline.Add(-margin); now.Before(at) {
+			if left := at.Sub(now); left < wait {
+				wait = left
+			}
+			continue
+		}
+		reason := fmt.Sprintf("deadline %s within %s", item.Deadline.UTC().Format(time.RFC3339), margin)
+		if err := v.queue.Approve(item.Key, autoApprover, reason); err != nil {
+			g.Log().Error(v.ctx, "auto-approve error", err, "key:", item.Key)
+			continue
+		}
+		logCtx := logging.WithEvent(v.ctx, item.Event)
+		g.Log().Warning(logCtx, "request approved automatically,", reason)
+		v.logger.Warning(logCtx, "APPROVAL: approved automatically,", reason)
+	}
+	return wait
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/btcsource"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+func testSummary(t *testing.T) *RequestSummary {
+	t.Helper()
+	prevHash := chainhash.Hash{7}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 1), nil, nil))
+	// P2WPKH output
+	tx.AddTxOut(wire.NewTxOut(90000, append([]byte{0x00, 0x14}, bytes.Repeat([]byte{1}, 20)...)))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	info := &contract.TransactionInfo{
+		Dapp:       common.HexToAddress("0x00000000000000000000000000000000000000d1"),
+		Arbitrator: common.HexToAddress("0x0262aB0ED65373cC855C34529fDdeAa0e686D913"),
+		Deadline:   big.NewInt(time.Now().Add(time.Hour).Unix()),
+		BtcTx:      buf.Bytes(),
+		Utxos:      []contract.UTXO{{TxHash: prevHash, Index: 1, Amount: big.NewInt(100000)}},
+	}
+	s, err := summarize(common.Hash{0xaa}, info, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSummarize(t *testing.T) {
+	s := testSummary(t)
+	if s.InputValue != 100000 || s.OutputValue != 90000 || s.Value() != 100000 {
+		t.Errorf("values: %+v", s)
+	}
+	if len(s.Outputs) != 1 || !strings.HasPrefix(s.Outputs[0].Address, "bc1q") {
+		t.Errorf("outputs: %+v", s.Outputs)
+	}
+	text := s.Text()
+	for _, want := range []string{s.Dapp.Hex(), s.Outputs[0].Address, "0.00100000 BTC in", "passed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text misses %q:\n%s", want, text)
+		}
+	}
+}
+
+// memSource is a BTCSource of prevouts held in memory.
+type memSource struct {
+	btcsource.BTCSource
+	prevouts map[wire.OutPoint]*wire.TxOut
+}
+
+func (s *memSource) String() string { return "memory" }
+
+func (s *memSource) GetPrevout(_ context.Context, outpoint wire.OutPoint) (*wire.TxOut, error) {
+	if out, ok := s.prevouts[outpoint]; ok {
+		return out, nil
+	}
+	return nil, btcsource.ErrNotFound
+}
+
+func TestWithPrevouts(t *testing.T) {
+	recorded, missing, unknown := chainhash.Hash{7}, chainhash.Hash{8}, chainhash.Hash{9}
+	tx := wire.NewMsgTx(2)
+	for _, hash := range []chainhash.Hash{recorded, missing, unknown} {
+		hash := hash
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&hash, 0), nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	info := &contract.TransactionInfo{
+		BtcTx: buf.Bytes(),
+		Utxos: []contract.UTXO{{TxHash: recorded, Amount: big.NewInt(3000)}},
+	}
+	v := &Arbiter{ctx: context.Background(), btc: &memSource{prevouts: map[wire.OutPoint]*wire.TxOut{
+		{Hash: missing}: wire.NewTxOut(2000, []byte{0x51}),
+	}}}
+
+	s, err := summarize(common.Hash{0xaa}, v.withPrevouts(context.Background(), info), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.InputValue != 5000 || s.Inputs[1].Amount != 2000 || s.Inputs[2].Amount != 0 || s.UnknownInputs != 1 {
+		t.Errorf("inputs: %+v", s.Inputs)
+	}
+	if len(info.Utxos) != 1 {
+		t.Errorf("on-chain record changed: %+v", info.Utxos)
+	}
+}
+
+func TestNeedsApproval(t *testing.T) {
+	s := testSummary(t)
+	for _, tc := range []struct {
+		name string
+		cfg  config.ApprovalConfig
+		want bool
+	}{
+		{"disabled", config.ApprovalConfig{}, false},
+		{"all", config.ApprovalConfig{All: true}, true},
+		{"dapp", config.ApprovalConfig{Dapps: []string{"0x00000000000000000000000000000000000000D1"}}, true},
+		{"other dapp", config.ApprovalConfig{Dapps: []string{"0x00000000000000000000000000000000000000d2"}}, false},
+		{"above threshold", config.ApprovalConfig{MinValue: 100000}, true},
+		{"below threshold", config.ApprovalConfig{MinValue: 100001}, false},
+	} {
+		if got, _ := needsApproval(tc.cfg, s); got != tc.want {
+			t.Errorf("%s: got %v", tc.name, got)
+		}
+	}
+
+	// outputs above the known inputs count
+	outputs := &RequestSummary{InputValue: 1000, OutputValue: 200000}
+	if outputs.Value() != 200000 {
+		t.Errorf("value %d, want the outputs", outputs.Value())
+	}
+	if got, _ := needsApproval(config.ApprovalConfig{MinValue: 100000}, outputs); !got {
+		t.Error("outputs above threshold not held")
+	}
+	// an input of unknown amount may hold any value
+	unknown := &RequestSummary{InputValue: 1000, OutputValue: 900, UnknownInputs: 1}
+	if got, _ := needsApproval(config.ApprovalConfig{MinValue: 100000}, unknown); !got {
+		t.Error("unknown input amount not held")
+	}
+	if got, _ := needsApproval(config.ApprovalConfig{}, unknown); got {
+		t.Error("unknown input amount held without threshold")
+	}
+}
+
+func TestAutoApprove(t *testing.T) {
+	q, err := queue.Open(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+	logger, err := logging.NewEventLog(logging.Config{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Approval: config.ApprovalConfig{All: true, AutoApproveMargin: time.Hour}}
+	v := &Arbiter{ctx: context.Background(), config: cfg, queue: q, logger: logger, alerts: alert.NewDispatcher(alert.Config{})}
+
+	now := time.Now()
+	for i, deadline := range []time.Time{now.Add(30 * time.Minute), now.Add(time.Hour + 30*time.Second)} {
+		event := &events.ContractLogEvent{TxHash: common.Hash{byte(i + 1)}, Topics: []common.Hash{events.ArbitrationRequested, {0xaa}}}
+		if _, err := q.Enqueue(event); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.SetDeadline(queue.KeyOf(event), deadline); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.AwaitApproval(queue.KeyOf(event), nil, common.Hash{0x51}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items, err := q.List(queue.StateAwaitingApproval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait := v.autoApprove(items, now)
+	if wait != 30*time.Second {
+		t.Errorf("wait %s, want the second request due in 30s", wait)
+	}
+	pending, _ := q.List(queue.StatePending)
+	if len(pending) != 1 || !pending[0].Approved(common.Hash{0x51}) || pending[0].Approval.DecidedBy != autoApprover {
+		t.Fatalf("pending: %+v", pending)
+	}
+
+	// without a margin the operator decides
+	cfg.Approval.AutoApproveMargin = 0
+	items, _ = q.List(queue.StateAwaitingApproval)
+	if v.autoApprove(items, now.Add(2*time.Hour)); len(items) != 1 {
+		t.Fatalf("waiting: %+v", items)
+	}
+	if items, _ = q.List(queue.StateAwaitingApproval); len(items) != 1 {
+		t.Errorf("approved without a margin: %+v", items)
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/gob"
 	"encoding/hex"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -21,28 +24,53 @@ import (
 	_ "github.com/gogf/gf/contrib/drivers/pgsql/v2"
 	"github.com/gogf/gf/os/gfile"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/glog"
 
-	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/mempool"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/btcsource"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/history"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/metrics"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
 )
 
 const DELAY_BLOCK uint64 = 3
 
+const receiptTimeout = 10 * time.Minute
+
 type account struct {
 	PrivateKey string `json:"privKey"`
 }
 
 type Arbiter struct {
-	ctx     context.Context
-	config  *config.Config
+	ctx context.Context
+	// config at start, read through cfg for the settings Reload changes
+	config *config.Config
+	live   atomic.Pointer[config.Config]
+	// serializes Reload
+	reloadMu sync.Mutex
+
 	escNode *contract.ArbitratorContract
 	account *account
 
-	mempoolAPI *mempool.API
+	btc     btcsource.BTCSource
+	queue   *queue.Queue
+	history history.Recorder
+
+	// supervised subsystems, done once they stopped after shutdown
+	wg     sync.WaitGroup
+	health *healthState
+
+	// configured start height, before it is replaced by the listened block
+	escStartHeight uint64
 
-	logger *log.Logger
+	// event log, the operator record of requests, signatures and alerts
+	logger *glog.Logger
+	// operator notifications, disabled while no notifier is configured
+	alerts *alert.Dispatcher
 }
 
 func NewArbiter(ctx context.Context, config *config.Config) *Arbiter {
@@ -71,38 +99,115 @@ func NewArbiter(ctx context.Context, config *config.Config) *Arbiter {
 		g.Log().Fatal(ctx, "create dir error", err)
 	}
 
-	logFilePath := gfile.Join(config.LoanLogPath, "event.log")
-	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	logger, err := logging.NewEventLog(config.Log, config.LoanLogPath)
 	if err != nil {
-		g.Log().Fatal(ctx, "create log file error", err)
+		g.Log().Fatal(ctx, "create event log error", err)
 	}
-	logger := log.New(logFile, "", log.Ldate|log.Ltime)
 
-	escNode := newESCNode(ctx, config, escAccount.PrivateKey, logger)
+	requestQueue, err := queue.Open(config.QueueDBPath)
+	if err != nil {
+		g.Log().Fatal(ctx, "open queue error", err, " path ", config.QueueDBPath)
+	}
+	imported, skipped, err := requestQueue.MigrateDirs(map[queue.State]string{
+		queue.StatePending: config.LoanNeedSignReqPath,
+		queue.StateFailed:  config.LoanNeedSignFailedPath,
+		queue.StateSigned:  config.LoanNeedSignSignedPath,
+	})
+	if err != nil {
+		g.Log().Fatal(ctx, "migrate request directories error", err)
+	}
+	if imported > 0 || len(skipped) > 0 {
+		g.Log().Notice(ctx, "imported", imported, "requests from directory queue, skipped:", skipped)
+	}
 
-	mempoolAPI := mempool.NewAPI(mempool.Config{Network: config.Network})
+	recorder, err := newRecorder(ctx, config)
+	if err != nil {
+		g.Log().Fatal(ctx, "open history database error", err)
+	}
+	requestQueue.OnChange(func(item *queue.Item) {
+		if err := recorder.RecordQueueItem(ctx, item); err != nil {
+			g.Log().Error(ctx, "RecordQueueItem error", err, "key:", item.Key)
+		}
+	})
 
-	return &Arbiter{
-		ctx:        ctx,
-		config:     config,
-		account:    &arbiterAccount,
-		escNode:    escNode,
-		mempoolAPI: mempoolAPI,
-		logger:     logger,
+	escStartHeight := config.ESCStartHeight
+	escNode := newESCNode(ctx, config, escAccount.PrivateKey, requestQueue, recorder, logger)
+
+	btc, err := btcsource.New(config.BTCSourceConfig())
+	if err != nil {
+		g.Log().Fatal(ctx, "BTC source error", err)
 	}
+
+	alertSettings := alertConfig(config)
+	alerts := alert.NewDispatcher(alertSettings, alertSettings.Notifiers()...)
+
+	v := &Arbiter{
+		ctx:     ctx,
+		config:  config,
+		account: &arbiterAccount,
+		escNode: escNode,
+		btc:     btc,
+		queue:   requestQueue,
+		history: recorder,
+		logger:  logger,
+		health:  newHealthState(),
+		alerts:  alerts,
+
+		escStartHeight: escStartHeight,
+	}
+	requestQueue.OnChange(v.alertQueueChange)
+	return v
 }
 
+// Start runs the enabled subsystems under supervision. They stop when the
+// context given to NewArbiter is cancelled.
 func (v *Arbiter) Start() {
+	if v.config.Signer && !v.config.DryRun {
+		v.requeueSimulated()
+	}
 	if v.config.Signer {
-		go v.processArbiterSig()
+		v.supervise("signer", v.processArbiterSig)
+		v.supervise("inbox", v.watchInbox)
+		v.supervise("watchdog", v.watchDeadlines)
 	}
 
 	if v.config.Listener {
-		go v.listenESCContract()
+		v.supervise("listener", v.listenESCContract)
+		v.supervise("reconciler", v.reconcileArbitrations)
+	}
+
+	if v.config.FeeClaim {
+		v.supervise("feeClaim", v.claimArbitrationFees)
+	}
+
+	if v.config.MonitorAddr != "" {
+		v.supervise("monitor", v.serveMonitor)
+		v.supervise("metrics", v.collectMetrics)
+		v.supervise("readiness", v.probeReadiness)
+	}
+
+	if v.config.AdminAPIAddr != "" {
+		v.supervise("adminAPI", v.serveAdminAPI)
+	}
+
+	// always started, a config reload may enable alerting
+	v.supervise("alerts", func() error { return v.alerts.Run(v.ctx) })
+	v.supervise("status", v.watchStatus)
+}
+
+// Wait blocks until every subsystem stopped after shutdown.
+func (v *Arbiter) Wait() {
+	v.wg.Wait()
+}
+
+// Close releases the queue database. Call it after Wait.
+func (v *Arbiter) Close() {
+	if err := v.queue.Close(); err != nil {
+		g.Log().Error(v.ctx, "close queue error", err)
 	}
 }
 
-func (v *Arbiter) listenESCContract() {
+func (v *Arbiter) listenESCContract() error {
 	g.Log().Info(v.ctx, "listenESCContract start")
 
 	startHeight, _ := events.GetCurrentBlock(v.config.DataDir)
@@ -113,18 +218,18 @@ func (v *Arbiter) listenESCContract() {
 	keyfile := v.config.EscKeyFilePath
 	data, err := os.ReadFile(keyfile)
 	if err != nil {
-		g.Log().Fatal(v.ctx, "get keyfile error", err, " private key path ", keyfile)
+		return fmt.Errorf("get keyfile error: %w, private key path %s", err, keyfile)
 	}
 	var a account
 	err = json.Unmarshal(data, &a)
 	if err != nil {
-		g.Log().Fatal(v.ctx, "Unmarshal keyfile error", err, " content ", string(data))
+		return fmt.Errorf("unmarshal keyfile error: %w", err)
 	}
 
-	v.escNode.Start(startHeight)
+	return v.escNode.Start(startHeight)
 }
 
-func (v *Arbiter) processArbiterSig() {
+func (v *Arbiter) processArbiterSig() error {
 	g.Log().Info(v.ctx, "processArbiterSignature start")
 
 	// var netWorkParams = chaincfg.MainNetParams
@@ -132,144 +237,266 @@ func (v *Arbiter) processArbiterSig() {
 	// 	netWorkParams = chaincfg.TestNet3Params
 	// }
 
+	pool := newSignerPool(v.config.SignWorkers)
 	for {
-		// get all deploy file
-		files, err := os.ReadDir(v.config.LoanNeedSignReqPath)
-		if err != nil {
-			continue
+		v.health.beat()
+		wait := v.dispatchRequests(pool)
+		select {
+		case <-v.ctx.Done():
+			// let in-flight requests finish, each is bounded by SignTimeout
+			g.Log().Info(v.ctx, "waiting for in-flight requests")
+			pool.Wait()
+			return nil
+		case <-v.queue.Wake():
+		case <-pool.Done():
+		case <-time.After(wait):
 		}
+	}
+}
+
+func (v *Arbiter) processRequest(ctx context.Context, item *queue.Item) {
+	started := time.Now()
+	logEvt := item.Event
+	// log lines about the request carry its arbitration fields
+	logCtx := logging.WithEvent(v.ctx, logEvt)
+	ctx = logging.WithEvent(ctx, logEvt)
+	if err := v.queue.MarkAttempt(item.Key); err != nil {
+		g.Log().Error(logCtx, "MarkAttempt error", err, "key:", item.Key)
+		return
+	}
+	var ev = make(map[string]interface{})
+	err := v.escNode.Loan_abi.UnpackIntoMap(ev, "ArbitrationRequested", logEvt.EventData)
+	if err != nil {
+		g.Log().Error(logCtx, "UnpackIntoMap error", err)
+		v.markFailed(item, permanent(fmt.Errorf("unpack event into map failed: %w", err)))
+		return
+	}
+	queryId := logEvt.Topics[1]
+	rawData := ev["btcTx"].([]byte)
+	script := ev["script"].([]byte)
+	arbitratorAddress := ev["arbitrator"].(common.Address)
+
+	g.Log().Info(logCtx, "rawData", hex.EncodeToString(rawData))
+	g.Log().Info(logCtx, "script", hex.EncodeToString(script))
+	g.Log().Info(logCtx, "arbitratorAddress", arbitratorAddress)
+
+	info, err := v.checkRequest(ctx, item, queryId, rawData, script)
+	if err != nil {
+		g.Log().Error(logCtx, "checkRequest error", err)
+		v.markFailed(item, err)
+		return
+	}
+	if waiting, err := v.holdForApproval(logCtx, item, info); err != nil {
+		g.Log().Error(logCtx, "holdForApproval error", err)
+		v.markFailed(item, err)
+		return
+	} else if waiting {
+		return
+	}
+
+	// sign btc tx
+	// tx, err := decodeTx(rawData)
+	// if err != nil {
+	// 	g.Log().Error(v.ctx, "decodeTx error", err, "rawData:", rawData)
+	// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".decodeRawDataFailed")
+	// 	v.logger.Println("[ERR]  SIGN: decode event failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
+	// 	continue
+	// }
+	// script1Hash := sha256.Sum256(script)
+	// wsh, err := btcutil.NewAddressWitnessScriptHash(script1Hash[:], &netWorkParams)
+	// if err != nil {
+	// 	g.Log().Error(v.ctx, "NewAddressWitnessScriptHash err:", err)
+	// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".newAddressWitnessScriptHashFailed")
+	// 	v.logger.Println("[ERR]  SIGN: new addr witness sh failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
+	// 	continue
+	// }
+	// payAddress, err := btcutil.DecodeAddress(wsh.EncodeAddress(), &netWorkParams)
+	// if err != nil {
+	// 	g.Log().Error(v.ctx, "DecodeAddress err:", err)
+	// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".DecodeAddressFailed")
+	// 	v.logger.Println("[ERR]  SIGN: decode address failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
+	// 	continue
+	// }
+	// g.Log().Info(v.ctx, "payAddress", payAddress.String())
+	// p2wsh, err := txscript.PayToAddrScript(payAddress)
+	// if err != nil {
+	// 	g.Log().Error(v.ctx, "PayToAddrScript err:", err)
+	// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".PayToAddrScriptFailed")
+	// 	v.logger.Println("[ERR]  SIGN: get ptaddr script failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
+	// 	continue
+	// }
+
+	// // get preOutput by tx.Inputs(idx)
+	// // only one input
+	// idx := 0
+	// input := tx.TxIn[idx]
+	// g.Log().Info(v.ctx, "input.PreviousOutPoint.Hash", input.PreviousOutPoint.Hash.String())
+	// preTx, err := v.btc.GetTransaction(input.PreviousOutPoint.Hash.String())
+	// if err != nil {
+	// 	g.Log().Error(v.ctx, "GetRawTransaction error", err)
+	// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".GetRawTransactionFailed")
+	// 	v.logger.Println("[ERR]  SIGN: get raw tx failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
+	// 	continue
+	// }
+
+	// preAmount := int64(preTx.Vout[input.PreviousOutPoint.Index].Value)
+	// prevFetcher := txscript.NewCannedPrevOutputFetcher(
+	// 	p2wsh, preAmount,
+	// )
+	// sigHashes := txscript.NewTxSigHashes(tx, prevFetcher)
+	// sigHash, err := txscript.CalcWitnessSigHash(script, sigHashes, txscript.SigHashAll, tx, idx, preAmount)
+	// if err != nil {
+	// 	g.Log().Error(v.ctx, "CalcWitnessSigHash error", err)
+	// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".CalcWitnessSigHashFailed")
+	// 	v.logger.Println("[ERR]  SIGN: calculate sigHash failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
+	// 	continue
+	// }
+	// var sigDataHash [32]byte
+	// copy(sigDataHash[:], sigHash)
+	// g.Log().Info(v.ctx, "sigHash", hex.EncodeToString(sigDataHash[:]))
+	// g.Log().Info(v.ctx, "script", hex.EncodeToString(script))
+
+	// sign the transaction of the contract record, which was reviewed
+	signatureBytes, err := signBtcTx(v.account.PrivateKey, info.BtcTx)
+	if err != nil {
+		g.Log().Error(logCtx, "sign error", err)
+		v.markFailed(item, permanent(err))
+		return
+	}
+	// signatureBytes = append(signatureBytes, byte(txscript.SigHashAll))
+	g.Log().Info(logCtx, "arbiter signature:", hex.EncodeToString(signatureBytes))
+	if v.config.DryRun {
+		v.simulateSubmission(ctx, logCtx, item, queryId, info.BtcTx, signatureBytes)
+		return
+	}
+	if err := v.history.RecordSignature(v.ctx, item.Key, queryId, signatureBytes); err != nil {
+		g.Log().Error(logCtx, "RecordSignature error", err)
+	}
 
-		for _, file := range files {
-			// read file
-			filePath := v.config.LoanNeedSignReqPath + "/" + file.Name()
-			fileContent, err := os.ReadFile(filePath)
-			if err != nil {
-				g.Log().Error(v.ctx, "read file error", err)
-				continue
-			}
-			logEvt, err := v.decodeLogEvtByFileContent(fileContent)
-			if err != nil {
-				g.Log().Error(v.ctx, "decodeLogEvtByFileContent error", err)
-				v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".failed")
-				v.logger.Println("[ERR]  SIGN: decode event failed, file:", filePath)
-				continue
-			}
-			var ev = make(map[string]interface{})
-			err = v.escNode.Loan_abi.UnpackIntoMap(ev, "ArbitrationRequested", logEvt.EventData)
-			if err != nil {
-				g.Log().Error(v.ctx, "UnpackIntoMap error", err)
-				v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".failed")
-				v.logger.Println("[ERR]  SIGN: unpack event into map failed, file:", filePath)
-				continue
-			}
-			g.Log().Info(v.ctx, "ev", ev)
-			queryId := logEvt.Topics[1]
-			dappAddress := logEvt.Topics[2]
-			rawData := ev["btcTx"].([]byte)
-			script := ev["script"].([]byte)
-			arbitratorAddress := ev["arbitrator"].(common.Address)
-
-			g.Log().Info(v.ctx, "dappAddress", dappAddress)
-			g.Log().Info(v.ctx, "queryId", hex.EncodeToString(queryId[:]))
-			g.Log().Info(v.ctx, "rawData", hex.EncodeToString(rawData))
-			g.Log().Info(v.ctx, "script", hex.EncodeToString(script))
-			g.Log().Info(v.ctx, "arbitratorAddress", arbitratorAddress)
-
-			// sign btc tx
-			// tx, err := decodeTx(rawData)
-			// if err != nil {
-			// 	g.Log().Error(v.ctx, "decodeTx error", err, "rawData:", rawData)
-			// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".decodeRawDataFailed")
-			// 	v.logger.Println("[ERR]  SIGN: decode event failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			// 	continue
-			// }
-			// script1Hash := sha256.Sum256(script)
-			// wsh, err := btcutil.NewAddressWitnessScriptHash(script1Hash[:], &netWorkParams)
-			// if err != nil {
-			// 	g.Log().Error(v.ctx, "NewAddressWitnessScriptHash err:", err)
-			// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".newAddressWitnessScriptHashFailed")
-			// 	v.logger.Println("[ERR]  SIGN: new addr witness sh failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			// 	continue
-			// }
-			// payAddress, err := btcutil.DecodeAddress(wsh.EncodeAddress(), &netWorkParams)
-			// if err != nil {
-			// 	g.Log().Error(v.ctx, "DecodeAddress err:", err)
-			// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".DecodeAddressFailed")
-			// 	v.logger.Println("[ERR]  SIGN: decode address failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			// 	continue
-			// }
-			// g.Log().Info(v.ctx, "payAddress", payAddress.String())
-			// p2wsh, err := txscript.PayToAddrScript(payAddress)
-			// if err != nil {
-			// 	g.Log().Error(v.ctx, "PayToAddrScript err:", err)
-			// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".PayToAddrScriptFailed")
-			// 	v.logger.Println("[ERR]  SIGN: get ptaddr script failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			// 	continue
-			// }
-
-			// // get preOutput by tx.Inputs(idx)
-			// // only one input
-			// idx := 0
-			// input := tx.TxIn[idx]
-			// g.Log().Info(v.ctx, "input.PreviousOutPoint.Hash", input.PreviousOutPoint.Hash.String())
-			// preTx, err := v.mempoolAPI.GetRawTransaction(input.PreviousOutPoint.Hash.String())
-			// if err != nil {
-			// 	g.Log().Error(v.ctx, "GetRawTransaction error", err)
-			// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".GetRawTransactionFailed")
-			// 	v.logger.Println("[ERR]  SIGN: get raw tx failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			// 	continue
-			// }
-
-			// preAmount := int64(preTx.Vout[input.PreviousOutPoint.Index].Value)
-			// prevFetcher := txscript.NewCannedPrevOutputFetcher(
-			// 	p2wsh, preAmount,
-			// )
-			// sigHashes := txscript.NewTxSigHashes(tx, prevFetcher)
-			// sigHash, err := txscript.CalcWitnessSigHash(script, sigHashes, txscript.SigHashAll, tx, idx, preAmount)
-			// if err != nil {
-			// 	g.Log().Error(v.ctx, "CalcWitnessSigHash error", err)
-			// 	v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".CalcWitnessSigHashFailed")
-			// 	v.logger.Println("[ERR]  SIGN: calculate sigHash failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			// 	continue
-			// }
-			// var sigDataHash [32]byte
-			// copy(sigDataHash[:], sigHash)
-			// g.Log().Info(v.ctx, "sigHash", hex.EncodeToString(sigDataHash[:]))
-			// g.Log().Info(v.ctx, "script", hex.EncodeToString(script))
-
-			first := sha256.Sum256(rawData)
-			sigHash := sha256.Sum256(first[:])
-
-			// ecdsa sign
-			priKeyBytes, _ := hex.DecodeString(v.account.PrivateKey)
-			priKey, _ := btcec.PrivKeyFromBytes(priKeyBytes)
-			signatureArbiter := ecdsa.Sign(priKey, sigHash[:])
-			ok := signatureArbiter.Verify(sigHash[:], priKey.PubKey())
-			if !ok {
-				g.Log().Error(v.ctx, "self ecdsa sign verify failed")
-				v.moveToDirectory(filePath, v.config.LoanNeedSignFailedPath+"/"+file.Name()+".SigVerifyFailed")
-				v.logger.Println("[ERR]  SIGN: signature verify failed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-				continue
-			}
-			signatureBytes := signatureArbiter.Serialize()
-			// signatureBytes = append(signatureBytes, byte(txscript.SigHashAll))
-			g.Log().Info(v.ctx, "arbiter signature:", hex.EncodeToString(signatureBytes))
-
-			// feedback signature to contract
-			txhash, err := v.escNode.SubmitArbitrationSignature(signatureBytes, queryId)
-			g.Log().Notice(v.ctx, "submitArbitrationSignature", "txhash ", txhash.String(), " error ", err)
-			if err != nil {
-				v.moveToDirectory(v.config.LoanNeedSignReqPath+"/"+file.Name(), v.config.LoanNeedSignFailedPath+"/"+file.Name()+".SubmitSignatureFailed")
-				v.logger.Println("[ERR]  SIGN: SubmitArbitrationSignature failed, block:", logEvt.Block, "tx:", logEvt.TxHash, "err:", err.Error())
-			} else {
-				v.moveToDirectory(v.config.LoanNeedSignReqPath+"/"+file.Name(), v.config.LoanNeedSignSignedPath+"/"+file.Name()+".Succeed")
-				v.logger.Println("[INF]  SIGN: SubmitArbitrationSignature succeed, block:", logEvt.Block, "tx:", logEvt.TxHash)
-			}
+	// feedback signature to contract
+	txhash, err := v.escNode.SubmitArbitrationSignature(ctx, signatureBytes, queryId)
+	g.Log().Notice(logCtx, "submitArbitrationSignature", "txhash ", txhash.String(), " error ", err)
+	if err := v.history.RecordSubmission(v.ctx, item.Key, queryId, txhash, err); err != nil {
+		g.Log().Error(logCtx, "RecordSubmission error", err)
+	}
+	if err != nil {
+		metrics.Submissions.WithLabelValues(metrics.SubmissionFailed).Inc()
+		v.markFailed(item, fmt.Errorf("SubmitSignatureFailed: %w", err))
+		return
+	}
+	metrics.Submissions.WithLabelValues(metrics.SubmissionSucceeded).Inc()
+	metrics.SignDuration.Observe(time.Since(started).Seconds())
+	if err := v.queue.MarkSigned(item.Key, txhash); err != nil {
+		// the signature is on its way, the reconciler catches a stale pending item
+		g.Log().Critical(logCtx, "MarkSigned error", err, "key:", item.Key, "submit tx:", txhash.String())
+	}
+	v.logger.Info(logCtx, "SIGN: SubmitArbitrationSignature succeed, submit tx:", txhash.String())
+	go v.recordReceipt(logCtx, txhash)
+}
+
+// checkRequest verifies on chain that the arbitration is assigned to us,
+// still waiting for a signature and for the btcTx and script of the event,
+// and records its deadline on item. It returns the on-chain record.
+func (v *Arbiter) checkRequest(ctx context.Context, item *queue.Item, queryId common.Hash, btcTx, script []byte) (*contract.TransactionInfo, error) {
+	info, err := v.escNode.GetTransactionById(ctx, queryId)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionByIdFailed: %w", err)
+	}
+	deadline := time.Unix(info.Deadline.Int64(), 0)
+	if !deadline.Equal(item.Deadline) {
+		item.Deadline = deadline
+		if err := v.queue.SetDeadline(item.Key, deadline); err != nil {
+			g.Log().Error(ctx, "SetDeadline error", err, "key:", item.Key)
 		}
+	}
+	if err := checkPolicy(info, v.config.ESCArbiterAddress, time.Now()); err != nil {
+		return info, err
+	}
+	// the event may come from the inbox, only the contract record is trusted
+	if !bytes.Equal(btcTx, info.BtcTx) || !bytes.Equal(script, info.Script) {
+		return info, permanent(errors.New("PolicyRejected: BTC transaction or script differ from the contract record"))
+	}
+	return info, nil
+}
+
+// checkPolicy decides from the on-chain record of an arbitration whether
+// arbitrator may sign it at now. The online signer and offline-sign apply
+// the same checks.
+func checkPolicy(info *contract.TransactionInfo, arbitrator string, now time.Time) error {
+	if !strings.EqualFold(info.Arbitrator.String(), arbitrator) {
+		return permanent(fmt.Errorf("PolicyRejected: arbitrator is %s", info.Arbitrator.String()))
+	}
+	if status := info.TxStatus(); status != contract.TransactionArbitrated {
+		return permanent(fmt.Errorf("PolicyRejected: transaction status is %s", status))
+	}
+	if len(info.Signature) > 0 {
+		return permanent(errors.New("PolicyRejected: signature already submitted"))
+	}
+	if info.Deadline == nil || now.After(time.Unix(info.Deadline.Int64(), 0)) {
+		return permanent(errors.New("PolicyRejected: arbitration deadline passed"))
+	}
+	return nil
+}
 
-		// sleep 10s to check and process next files
-		time.Sleep(time.Second * 10)
+// SigHash returns the hash the arbiter signs for the BTC transaction of an
+// arbitration, the double SHA-256 of btcTx.
+func SigHash(btcTx []byte) common.Hash {
+	first := sha256.Sum256(btcTx)
+	return sha256.Sum256(first[:])
+}
+
+// signBtcTx signs the SigHash of btcTx with the hex encoded arbiter key and
+// checks the signature. It returns the DER encoded signature.
+func signBtcTx(privateKey string, btcTx []byte) ([]byte, error) {
+	priKeyBytes, err := hex.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode arbiter key: %w", err)
+	}
+	priKey, _ := btcec.PrivKeyFromBytes(priKeyBytes)
+	sigHash := SigHash(btcTx)
+	signature := ecdsa.Sign(priKey, sigHash[:])
+	if !signature.Verify(sigHash[:], priKey.PubKey()) {
+		return nil, errors.New("SigVerifyFailed")
 	}
+	return signature.Serialize(), nil
+}
+
+// recordReceipt waits for the receipt of an ESC transaction we sent and
+// stores it in the history.
+func (v *Arbiter) recordReceipt(logCtx context.Context, hash common.Hash) {
+	ctx, cancel := context.WithTimeout(v.ctx, receiptTimeout)
+	defer cancel()
+	receipt, err := v.escNode.WaitForReceipt(ctx, hash)
+	if err != nil {
+		g.Log().Warning(logCtx, "wait receipt error", err, "txhash:", hash.String())
+		return
+	}
+	metrics.AddGas(metrics.GasSignature, receipt.GasUsed, receipt.EffectiveGasPrice)
+	if err := v.history.RecordReceipt(v.ctx, receipt); err != nil {
+		g.Log().Error(logCtx, "RecordReceipt error", err)
+	}
+}
+
+func newRecorder(ctx context.Context, config *config.Config) (history.Recorder, error) {
+	if config.DryRun {
+		// a shadow arbiter stays out of the history of the one that sends
+		return history.Nop{}, nil
+	}
+	switch config.Storage {
+	case "", history.StorageFile:
+		return history.Nop{}, nil
+	case history.StoragePostgres:
+		// g.DB() only fails on its first query without a database section
+		database, err := g.Cfg().Get(ctx, "database")
+		if err != nil {
+			return nil, err
+		}
+		if database.IsEmpty() {
+			return nil, errors.New(`storage "pgsql" needs a database section with the PostgreSQL link`)
+		}
+		return history.NewPostgres(ctx, g.DB(), config.ESCArbiterAddress)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", config.Storage)
 }
 
 func decodeTx(txBytes []byte) (*wire.MsgTx, error) {
@@ -283,8 +510,7 @@ func decodeTx(txBytes []byte) (*wire.MsgTx, error) {
 }
 
 func (v *Arbiter) decodeLogEvtByFileContent(content []byte) (*events.ContractLogEvent, error) {
-	logEvt := &events.ContractLogEvent{}
-	err := gob.NewDecoder(bytes.NewReader(content)).Decode(logEvt)
+	logEvt, err := events.LoadContractEvent(content)
 	if err != nil {
 		g.Log().Error(v.ctx, "NewDecoder deployBRC20 error", err)
 		return nil, err
@@ -314,6 +540,27 @@ func createDir(config *config.Config) error {
 		}
 	}
 
+	if !gfile.Exists(config.LoanImportedPath) {
+		err := gfile.Mkdir(config.LoanImportedPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !gfile.Exists(config.LoanCompletedEventPath) {
+		err := gfile.Mkdir(config.LoanCompletedEventPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !gfile.Exists(config.LoanFeeClaimedPath) {
+		err := gfile.Mkdir(config.LoanFeeClaimedPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	if !gfile.Exists(config.LoanLogPath) {
 		err := gfile.Mkdir(config.LoanLogPath)
 		if err != nil {
@@ -341,13 +588,13 @@ func (v *Arbiter) moveToDirectory(oldPath, newPath string) {
 	}
 }
 
-func newESCNode(ctx context.Context, config *config.Config, privateKey string, logger *log.Logger) *contract.ArbitratorContract {
+func newESCNode(ctx context.Context, config *config.Config, privateKey string, requestQueue *queue.Queue, recorder history.Recorder, logger *glog.Logger) *contract.ArbitratorContract {
 	startHeight, err := events.GetCurrentBlock(config.DataDir)
 	if err == nil {
 		config.ESCStartHeight = startHeight
 	}
 
-	contractNode, err := contract.New(ctx, config, privateKey, logger)
+	contractNode, err := contract.New(ctx, config, privateKey, requestQueue, recorder, logger)
 	if err != nil {
 		g.Log().Fatal(ctx, err)
 	}
@@ -0,0 +1,38 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gctx"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/history"
+)
+
+func TestNewRecorderNeedsDatabase(t *testing.T) {
+	adapter, err := gcfg.NewAdapterContent("arbiter:\n  storage: pgsql\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(previous) })
+
+	ctx := gctx.New()
+	_, err = newRecorder(ctx, &config.Config{Storage: history.StoragePostgres})
+	if err == nil || !strings.Contains(err.Error(), "database section") {
+		t.Errorf("pgsql without database section: %v", err)
+	}
+	if _, err := newRecorder(ctx, &config.Config{Storage: "mysql"}); err == nil {
+		t.Error("unknown storage accepted")
+	}
+	recorder, err := newRecorder(ctx, &config.Config{Storage: history.StoragePostgres, DryRun: true})
+	if err != nil || recorder != (history.Nop{}) {
+		t.Errorf("dry run recorder: %v %v", recorder, err)
+	}
+}
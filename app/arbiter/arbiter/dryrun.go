@@ -0,0 +1,153 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+// Dry run report actions.
+const (
+	dryRunSubmit   = "submitArbitration"
+	dryRunRetry    = "retry"
+	dryRunPark     = "park"
+	dryRunApproval = "awaitApproval"
+	dryRunFeeClaim = "transferArbitrationFee"
+)
+
+// dryRunRecord is one line of the dry run report, something the arbiter
+// would have done. Amounts are in wei of ELA on ESC.
+type dryRunRecord struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Key    string    `json:"key,omitempty"`
+	TxId   string    `json:"txId,omitempty"`
+	// BTC transaction and its signature for submitArbitration
+	BtcTxId   string `json:"btcTxId,omitempty"`
+	SigHash   string `json:"sigHash,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	// fees paid out by transferArbitrationFee
+	ArbitratorFee string `json:"arbitratorFee,omitempty"`
+	SystemFee     string `json:"systemFee,omitempty"`
+	// gas the transaction would use, zero if its simulation failed
+	Gas uint64 `json:"gas,omitempty"`
+	// upper bound of the fee claim gas cost
+	MaxGasCost string `json:"maxGasCost,omitempty"`
+	// why the simulation failed or the request was not signed
+	Error string `json:"error,omitempty"`
+	// retry time or approval reason
+	Detail string `json:"detail,omitempty"`
+}
+
+// serializes the report lines of concurrent signer workers
+var dryRunMu sync.Mutex
+
+// report appends record to the dry run report.
+func (v *Arbiter) report(record *dryRunRecord) {
+	record.Time = time.Now().UTC()
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	if err := appendJSONLine(v.config.DryRunReportPath, record); err != nil {
+		g.Log().Error(v.ctx, "append dry run report error", err, "record:", record)
+	}
+}
+
+// reportRequest appends a report record about item.
+func (v *Arbiter) reportRequest(item *queue.Item, action string, reason error, detail string) {
+	record := &dryRunRecord{Action: action, Key: item.Key.String(), Detail: detail}
+	if len(item.Event.Topics) > 1 {
+		record.TxId = item.Event.Topics[1].String()
+	}
+	if reason != nil {
+		record.Error = reason.Error()
+	}
+	v.report(record)
+}
+
+// simulateSubmission stands in for submitting the signature in a dry run.
+// It simulates submitArbitration, reports the signature with the result
+// and marks the request simulated.
+func (v *Arbiter) simulateSubmission(ctx, logCtx context.Context, item *queue.Item, queryId common.Hash, btcTx, signature []byte) {
+	record := &dryRunRecord{
+		Action:    dryRunSubmit,
+		Key:       item.Key.String(),
+		TxId:      queryId.String(),
+		Signature: hex.EncodeToString(signature),
+	}
+	if tx, err := decodeTx(btcTx); err == nil {
+		record.BtcTxId = tx.TxHash().String()
+	}
+	record.SigHash = SigHash(btcTx).Hex()
+	gas, err := v.escNode.SimulateArbitrationSignature(ctx, signature, queryId)
+	if err != nil {
+		record.Error = err.Error()
+		g.Log().Warning(logCtx, "dry run submitArbitration would fail:", err)
+		v.logger.Warning(logCtx, "DRYRUN: submitArbitration would fail, err:", err.Error())
+	} else {
+		record.Gas = gas
+		g.Log().Notice(logCtx, "dry run submitArbitration simulated, gas:", gas)
+		v.logger.Info(logCtx, "DRYRUN: submitArbitration simulated, gas:", gas)
+	}
+	v.report(record)
+	if err := v.queue.MarkSimulated(item.Key); err != nil {
+		g.Log().Error(logCtx, "MarkSimulated error", err, "key:", item.Key)
+	}
+}
+
+// reportedFeeClaims are the fee claims already in the dry run report, the
+// fee claimer sees their event files on every pass.
+var reportedFeeClaims = make(map[common.Hash]bool)
+
+// reportFeeClaim stands in for sending the fee claim tx in a dry run. It
+// reports every claim once and returns whether it did now.
+func (v *Arbiter) reportFeeClaim(txId common.Hash, arbitratorFee, systemFee, maxGasCost *big.Int) bool {
+	dryRunMu.Lock()
+	reported := reportedFeeClaims[txId]
+	reportedFeeClaims[txId] = true
+	dryRunMu.Unlock()
+	if reported {
+		return false
+	}
+	v.report(&dryRunRecord{
+		Action:        dryRunFeeClaim,
+		TxId:          txId.String(),
+		ArbitratorFee: arbitratorFee.String(),
+		SystemFee:     systemFee.String(),
+		MaxGasCost:    maxGasCost.String(),
+	})
+	return true
+}
+
+// requeueSimulated moves the requests a dry run only simulated back to
+// pending, for a shadow data dir that went live. The signer parks the ones
+// another arbiter already signed.
+func (v *Arbiter) requeueSimulated() {
+	items, err := v.queue.List(queue.StateSimulated)
+	if err != nil {
+		g.Log().Error(v.ctx, "list simulated requests error", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		if err := v.queue.Retry(item.Key); err != nil {
+			g.Log().Error(v.ctx, "requeue simulated request error", err, "key:", item.Key)
+			continue
+		}
+		keys = append(keys, item.Key.String())
+	}
+	g.Log().Warning(v.ctx, "requeued", len(keys), "requests simulated in a dry run:", strings.Join(keys, ", "))
+	v.logger.Warning(v.ctx, "DRYRUN: requeued simulated requests:", strings.Join(keys, ", "))
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+func TestDryRunReport(t *testing.T) {
+	dir := t.TempDir()
+	q, err := queue.Open(filepath.Join(dir, "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+	logger, err := logging.NewEventLog(logging.Config{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{DryRun: true, DryRunReportPath: filepath.Join(dir, "dry_run_report.jsonl")}
+	v := &Arbiter{ctx: context.Background(), config: cfg, queue: q, logger: logger, alerts: alert.NewDispatcher(alert.Config{})}
+
+	var items []*queue.Item
+	for i := 0; i < 2; i++ {
+		event := &events.ContractLogEvent{TxHash: common.Hash{byte(i + 1)}, Topics: []common.Hash{events.ArbitrationRequested, {0xaa}}}
+		if _, err := q.Enqueue(event); err != nil {
+			t.Fatal(err)
+		}
+		item, err := q.Get(queue.KeyOf(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	v.markFailed(items[0], errors.New("connection refused"))
+	v.markFailed(items[1], permanent(errors.New("PolicyRejected: signature already submitted")))
+	if !v.reportFeeClaim(common.Hash{0xbb}, big.NewInt(100), big.NewInt(10), big.NewInt(5)) {
+		t.Error("fee claim not reported")
+	}
+	// the event file stays, the next pass finds it again
+	if v.reportFeeClaim(common.Hash{0xbb}, big.NewInt(100), big.NewInt(10), big.NewInt(5)) {
+		t.Error("fee claim reported twice")
+	}
+
+	f, err := os.Open(cfg.DryRunReportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []dryRunRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record dryRunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records: %+v", records)
+	}
+	if r := records[0]; r.Action != dryRunRetry || r.Key != items[0].Key.String() || r.Error != "connection refused" || r.Detail == "" {
+		t.Errorf("retry record: %+v", r)
+	}
+	if r := records[1]; r.Action != dryRunPark || r.TxId != (common.Hash{0xaa}).String() || r.Error == "" {
+		t.Errorf("park record: %+v", r)
+	}
+	if r := records[2]; r.Action != dryRunFeeClaim || r.ArbitratorFee != "100" || r.MaxGasCost != "5" || r.Time.IsZero() {
+		t.Errorf("fee claim record: %+v", r)
+	}
+}
+
+func TestRequeueSimulated(t *testing.T) {
+	dir := t.TempDir()
+	q, err := queue.Open(filepath.Join(dir, "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+	logger, err := logging.NewEventLog(logging.Config{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Arbiter{ctx: context.Background(), config: &config.Config{}, queue: q, logger: logger}
+
+	simulated := &events.ContractLogEvent{TxHash: common.Hash{1}, Topics: []common.Hash{events.ArbitrationRequested, {0xaa}}}
+	signed := &events.ContractLogEvent{TxHash: common.Hash{2}, Topics: []common.Hash{events.ArbitrationRequested, {0xbb}}}
+	for _, event := range []*events.ContractLogEvent{simulated, signed} {
+		if _, err := q.Enqueue(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.MarkSimulated(queue.KeyOf(simulated)); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkSigned(queue.KeyOf(signed), common.Hash{3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// live, a simulated request is waiting for our signature again
+	v.requeueSimulated()
+	for event, want := range map[*events.ContractLogEvent]queue.State{simulated: queue.StatePending, signed: queue.StateSigned} {
+		item, err := q.Get(queue.KeyOf(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.State != want {
+			t.Errorf("%s: state %s, want %s", item.Key, item.State, want)
+		}
+	}
+}
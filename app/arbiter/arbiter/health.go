@@ -0,0 +1,226 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	readinessInterval = 15 * time.Second
+	// the signer loop wakes at least every signerIdleDelay, it is considered
+	// stalled for readiness and hung for liveness after these
+	signerStaleAfter = 3 * signerIdleDelay
+	signerHungAfter  = 10 * signerIdleDelay
+	// a subsystem crashing crashLoopCount times within crashLoopWindow fails
+	// liveness, a single crash only readiness
+	crashLoopCount  = 5
+	crashLoopWindow = 15 * time.Minute
+)
+
+// check is the result of one health or readiness check.
+type check struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type healthReport struct {
+	Status string           `json:"status"`
+	Time   time.Time        `json:"time"`
+	Checks map[string]check `json:"checks"`
+}
+
+// healthState is shared between the subsystems reporting in and the probe
+// handlers.
+type healthState struct {
+	mu         sync.Mutex
+	signerBeat time.Time
+	// crashed subsystems waiting for their restart, with the reason
+	down map[string]string
+	// recent crash times by subsystem, within crashLoopWindow
+	crashes map[string][]time.Time
+	// readiness checks of the last probe
+	ready     map[string]check
+	checkedAt time.Time
+}
+
+func newHealthState() *healthState {
+	// the signer counts as alive until its first loop
+	return &healthState{signerBeat: time.Now(), down: make(map[string]string), crashes: make(map[string][]time.Time)}
+}
+
+func (h *healthState) beat() {
+	h.mu.Lock()
+	h.signerBeat = time.Now()
+	h.mu.Unlock()
+}
+
+func (h *healthState) setDown(name, reason string) {
+	h.mu.Lock()
+	h.down[name] = reason
+	h.crashes[name] = append(recentCrashes(h.crashes[name], time.Now()), time.Now())
+	h.mu.Unlock()
+}
+
+// recentCrashes drops the crash times before crashLoopWindow.
+func recentCrashes(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) > crashLoopWindow {
+		i++
+	}
+	return times[i:]
+}
+
+func (h *healthState) setUp(name string) {
+	h.mu.Lock()
+	delete(h.down, name)
+	h.mu.Unlock()
+}
+
+// probeReadiness runs the readiness checks periodically, they involve RPC
+// calls and are too slow to run on every probe request.
+func (v *Arbiter) probeReadiness() error {
+	for {
+		checks := v.readinessChecks()
+		v.health.mu.Lock()
+		v.health.ready = checks
+		v.health.checkedAt = time.Now()
+		v.health.mu.Unlock()
+
+		if !v.sleep(readinessInterval) {
+			return nil
+		}
+	}
+}
+
+func (v *Arbiter) readinessChecks() map[string]check {
+	ctx, cancel := context.WithTimeout(v.ctx, readinessInterval)
+	defer cancel()
+	checks := make(map[string]check)
+
+	head, err := v.escNode.GetLatestHeight(ctx)
+	if err != nil {
+		checks["rpc"] = check{Detail: err.Error()}
+	} else {
+		checks["rpc"] = check{OK: true, Detail: fmt.Sprintf("head %d", head)}
+	}
+
+	if v.account != nil && v.account.PrivateKey != "" {
+		checks["keys"] = check{OK: true}
+	} else {
+		checks["keys"] = check{Detail: "arbiter key not loaded"}
+	}
+
+	if err := v.escNode.VerifyOperator(ctx); err != nil {
+		checks["operator"] = check{Detail: err.Error()}
+	} else {
+		checks["operator"] = check{OK: true, Detail: v.escNode.GetSubmiterAddress()}
+	}
+
+	if v.config.Listener {
+		height, seenHead := v.escNode.ListenerHeight()
+		if head < seenHead {
+			head = seenHead
+		}
+		switch {
+		case height == 0:
+			checks["listener"] = check{Detail: "no block scanned yet"}
+		case head > height && head-height > v.cfg().ReadyMaxLag:
+			checks["listener"] = check{Detail: fmt.Sprintf("height %d is %d blocks behind head %d", height, head-height, head)}
+		default:
+			checks["listener"] = check{OK: true, Detail: fmt.Sprintf("height %d", height)}
+		}
+	}
+
+	if v.config.Signer {
+		v.health.mu.Lock()
+		beat := v.health.signerBeat
+		v.health.mu.Unlock()
+		if since := time.Since(beat); since > signerStaleAfter {
+			checks["signer"] = check{Detail: fmt.Sprintf("last heartbeat %s ago", since.Round(time.Second))}
+		} else {
+			checks["signer"] = check{OK: true}
+		}
+	}
+	return checks
+}
+
+// handleReadyz reports whether the arbiter can answer arbitrations now.
+func (v *Arbiter) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	v.health.mu.Lock()
+	checks := make(map[string]check, len(v.health.ready))
+	for name, c := range v.health.ready {
+		checks[name] = c
+	}
+	checkedAt := v.health.checkedAt
+	v.health.mu.Unlock()
+
+	if checkedAt.IsZero() {
+		checks["probe"] = check{Detail: "not checked yet"}
+	} else if age := time.Since(checkedAt); age > 3*readinessInterval {
+		checks["probe"] = check{Detail: fmt.Sprintf("last check %s ago", age.Round(time.Second))}
+	}
+	v.health.mu.Lock()
+	for name, reason := range v.health.down {
+		checks[name] = check{Detail: "crashed: " + reason}
+	}
+	v.health.mu.Unlock()
+	writeHealth(w, r, checks)
+}
+
+// handleHealthz reports whether the process is alive: no subsystem is stuck
+// in a crash loop and the signer loop is not hung. A restart fixes neither
+// a single crash nor a failing dependency, /readyz reports those.
+func (v *Arbiter) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	checks := make(map[string]check)
+	now := time.Now()
+	v.health.mu.Lock()
+	names := make([]string, 0, len(v.health.crashes))
+	for name := range v.health.crashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		crashes := recentCrashes(v.health.crashes[name], now)
+		v.health.crashes[name] = crashes
+		if len(crashes) >= crashLoopCount {
+			checks[name] = check{Detail: fmt.Sprintf("crashed %d times in %s", len(crashes), crashLoopWindow)}
+		}
+	}
+	beat := v.health.signerBeat
+	v.health.mu.Unlock()
+
+	if v.config.Signer {
+		if since := now.Sub(beat); since > signerHungAfter {
+			checks["signer"] = check{Detail: fmt.Sprintf("last heartbeat %s ago", since.Round(time.Second))}
+		} else {
+			checks["signer"] = check{OK: true}
+		}
+	}
+	writeHealth(w, r, checks)
+}
+
+func writeHealth(w http.ResponseWriter, r *http.Request, checks map[string]check) {
+	report := healthReport{Status: "ok", Time: time.Now().UTC(), Checks: checks}
+	status := http.StatusOK
+	for _, c := range checks {
+		if !c.OK {
+			report.Status = "fail"
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(&report); err != nil {
+		g.Log().Warning(r.Context(), "write health report error", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+)
+
+func serveHealth(t *testing.T, handler http.HandlerFunc) (int, healthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var report healthReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, report
+}
+
+func TestHealthz(t *testing.T) {
+	v := &Arbiter{config: &config.Config{Signer: true}, health: newHealthState()}
+
+	if code, report := serveHealth(t, v.handleHealthz); code != http.StatusOK || report.Status != "ok" {
+		t.Fatalf("fresh arbiter: %d %+v", code, report)
+	}
+
+	// a single crash is for readiness, a restart would not help
+	v.health.setDown("listener", "boom")
+	if code, report := serveHealth(t, v.handleHealthz); code != http.StatusOK {
+		t.Fatalf("crashed listener: %d %+v", code, report)
+	}
+	v.health.setUp("listener")
+
+	for i := 1; i < crashLoopCount; i++ {
+		v.health.setDown("listener", "boom")
+		v.health.setUp("listener")
+	}
+	code, report := serveHealth(t, v.handleHealthz)
+	if code != http.StatusServiceUnavailable || report.Checks["listener"].OK {
+		t.Fatalf("listener crash loop: %d %+v", code, report)
+	}
+	// crashes age out of the window
+	for i := range v.health.crashes["listener"] {
+		v.health.crashes["listener"][i] = time.Now().Add(-crashLoopWindow - time.Minute)
+	}
+	if code, report := serveHealth(t, v.handleHealthz); code != http.StatusOK {
+		t.Fatalf("old crashes: %d %+v", code, report)
+	}
+
+	v.health.signerBeat = time.Now().Add(-signerHungAfter - time.Minute)
+	if code, _ := serveHealth(t, v.handleHealthz); code != http.StatusServiceUnavailable {
+		t.Fatalf("hung signer: %d", code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	v := &Arbiter{config: &config.Config{}, health: newHealthState()}
+
+	code, report := serveHealth(t, v.handleReadyz)
+	if code != http.StatusServiceUnavailable || report.Checks["probe"].OK {
+		t.Fatalf("not checked yet: %d %+v", code, report)
+	}
+
+	v.health.ready = map[string]check{"rpc": {OK: true}, "keys": {OK: true}}
+	v.health.checkedAt = time.Now()
+	if code, report := serveHealth(t, v.handleReadyz); code != http.StatusOK {
+		t.Fatalf("ready: %d %+v", code, report)
+	}
+
+	v.health.ready["rpc"] = check{Detail: "dial error"}
+	if code, _ := serveHealth(t, v.handleReadyz); code != http.StatusServiceUnavailable {
+		t.Fatalf("rpc down: %d", code)
+	}
+
+	v.health.ready["rpc"] = check{OK: true}
+	v.health.setDown("reconciler", "boom")
+	code, report = serveHealth(t, v.handleReadyz)
+	if code != http.StatusServiceUnavailable || report.Checks["reconciler"].Detail != "crashed: boom" {
+		t.Fatalf("crashed reconciler: %d %+v", code, report)
+	}
+	v.health.setUp("reconciler")
+	if code, _ := serveHealth(t, v.handleReadyz); code != http.StatusOK {
+		t.Fatalf("restarted reconciler: %d", code)
+	}
+
+	v.health.checkedAt = time.Now().Add(-time.Hour)
+	if code, _ := serveHealth(t, v.handleReadyz); code != http.StatusServiceUnavailable {
+		t.Fatalf("stale probe: %d", code)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+)
+
+// inboxPollInterval is used when the inbox cannot be watched.
+const inboxPollInterval = 10 * time.Second
+
+// watchInbox imports arbitration requests dropped into LoanNeedSignReqPath
+// by other tools into the request queue. Imported files are moved to
+// LoanImportedPath. The listener enqueues directly, the inbox only serves
+// external producers.
+func (v *Arbiter) watchInbox() error {
+	g.Log().Info(v.ctx, "watchInbox start")
+
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = watcher.Add(v.config.LoanNeedSignReqPath)
+	}
+	if err != nil {
+		g.Log().Error(v.ctx, "watch inbox error, polling instead", err)
+		for {
+			v.importInbox()
+			if !v.sleep(inboxPollInterval) {
+				return nil
+			}
+		}
+	}
+	defer watcher.Close()
+
+	v.importInbox()
+	for {
+		select {
+		case <-v.ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("inbox watcher closed")
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+				v.importInboxFile(event.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("inbox watcher closed")
+			}
+			// events may have been dropped, rescan the directory
+			g.Log().Error(v.ctx, "watch inbox error", err)
+			v.importInbox()
+		}
+	}
+}
+
+func (v *Arbiter) importInbox() {
+	files, err := os.ReadDir(v.config.LoanNeedSignReqPath)
+	if err != nil {
+		g.Log().Error(v.ctx, "read inbox error", err)
+		return
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			v.importInboxFile(filepath.Join(v.config.LoanNeedSignReqPath, file.Name()))
+		}
+	}
+}
+
+// importInboxFile enqueues the request stored at path. A file that cannot
+// be decoded is left alone, it may still be being written; the next write
+// event retries it. An event that is not an arbitration request is moved to
+// LoanNeedSignFailedPath.
+func (v *Arbiter) importInboxFile(path string) {
+	if strings.HasSuffix(path, ".tmp") {
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			g.Log().Error(v.ctx, "read inbox file error", err, "path:", path)
+		}
+		return
+	}
+	event, err := events.LoadContractEvent(content)
+	if err != nil {
+		g.Log().Warning(v.ctx, "inbox file not decodable yet", err, "path:", path)
+		return
+	}
+	if err := checkInboxEvent(event); err != nil {
+		g.Log().Error(v.ctx, "invalid inbox request", err, "path:", path)
+		v.logger.Error(v.ctx, "INBOX: invalid request, err:", err.Error(), "file:", filepath.Base(path))
+		v.moveToDirectory(path, filepath.Join(v.config.LoanNeedSignFailedPath, filepath.Base(path)+".InvalidEvent"))
+		return
+	}
+	created, err := v.queue.Enqueue(event)
+	if err != nil {
+		g.Log().Error(v.ctx, "enqueue inbox request error", err, "path:", path)
+		return
+	}
+	if created {
+		v.logger.Info(logging.WithEvent(v.ctx, event), "INBOX: imported request")
+	}
+	v.moveToDirectory(path, filepath.Join(v.config.LoanImportedPath, filepath.Base(path)))
+}
+
+// checkInboxEvent checks that event is an ArbitrationRequested event with
+// the arbitration id and dapp topics.
+func checkInboxEvent(event *events.ContractLogEvent) error {
+	if len(event.Topics) == 0 || event.Topics[0] != events.ArbitrationRequested {
+		return errors.New("not an ArbitrationRequested event")
+	}
+	if len(event.Topics) < 3 {
+		return fmt.Errorf("ArbitrationRequested event with %d topics", len(event.Topics))
+	}
+	return nil
+}
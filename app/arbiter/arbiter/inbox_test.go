@@ -0,0 +1,85 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+func TestImportInboxFile(t *testing.T) {
+	dir := t.TempDir()
+	q, err := queue.Open(filepath.Join(dir, "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+	logger, err := logging.NewEventLog(logging.Config{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		LoanNeedSignReqPath:    filepath.Join(dir, "request"),
+		LoanNeedSignFailedPath: filepath.Join(dir, "failed"),
+		LoanImportedPath:       filepath.Join(dir, "imported"),
+	}
+	v := &Arbiter{ctx: context.Background(), config: cfg, queue: q, logger: logger}
+
+	write := func(name string, event *events.ContractLogEvent) string {
+		path := filepath.Join(cfg.LoanNeedSignReqPath, name)
+		if err := events.SaveContractEvent(path, event); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	valid := &events.ContractLogEvent{TxHash: common.Hash{1}, Topics: []common.Hash{events.ArbitrationRequested, {0xaa}, {0xbb}}}
+	v.importInboxFile(write("valid", valid))
+	if !exists(filepath.Join(cfg.LoanImportedPath, "valid")) {
+		t.Error("valid request not moved to imported")
+	}
+	if item, err := q.Get(queue.KeyOf(valid)); err != nil || item.State != queue.StatePending {
+		t.Errorf("valid request not queued: %v", err)
+	}
+
+	for name, event := range map[string]*events.ContractLogEvent{
+		"other":    {TxHash: common.Hash{2}, Topics: []common.Hash{events.ArbitrationResultSubmitted, {0xaa}, {0xbb}}},
+		"short":    {TxHash: common.Hash{3}, Topics: []common.Hash{events.ArbitrationRequested, {0xaa}}},
+		"notopics": {TxHash: common.Hash{4}},
+	} {
+		path := write(name, event)
+		v.importInboxFile(path)
+		if exists(path) || exists(filepath.Join(cfg.LoanImportedPath, name)) {
+			t.Errorf("%s: invalid request not moved out", name)
+		}
+		if !exists(filepath.Join(cfg.LoanNeedSignFailedPath, name+".InvalidEvent")) {
+			t.Errorf("%s: invalid request not moved to failed", name)
+		}
+		if _, err := q.Get(queue.KeyOf(event)); err != queue.ErrNotFound {
+			t.Errorf("%s: invalid request queued: %v", name, err)
+		}
+	}
+
+	// a file still being written stays in the inbox
+	partial := filepath.Join(cfg.LoanNeedSignReqPath, "partial")
+	if err := os.WriteFile(partial, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	v.importInboxFile(partial)
+	if !exists(partial) {
+		t.Error("undecodable file moved")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/metrics"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	metricsInterval = 15 * time.Second
+	// time given to open monitor connections on shutdown
+	monitorShutdownTimeout = 5 * time.Second
+)
+
+// serveMonitor serves the metrics and probe endpoints on MonitorAddr until
+// shutdown.
+func (v *Arbiter) serveMonitor() error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", v.handleHealthz)
+	mux.HandleFunc("/readyz", v.handleReadyz)
+	server := &http.Server{
+		Addr:              v.config.MonitorAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		g.Log().Info(v.ctx, "monitor listening on", v.config.MonitorAddr)
+		errCh <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-v.ctx.Done():
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), monitorShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// collectMetrics periodically updates the metrics that are read rather than
+// counted: queue depth, operator balance and the nearest deadline.
+func (v *Arbiter) collectMetrics() error {
+	for {
+		v.updateQueueMetrics()
+
+		ctx, cancel := context.WithTimeout(v.ctx, metricsInterval)
+		balance, err := v.escNode.OperatorBalance(ctx)
+		cancel()
+		if err != nil {
+			g.Log().Warning(v.ctx, "metrics OperatorBalance error", err)
+		} else {
+			metrics.OperatorBalance.Set(metrics.Wei(balance))
+		}
+
+		if !v.sleep(metricsInterval) {
+			return nil
+		}
+	}
+}
+
+func (v *Arbiter) updateQueueMetrics() {
+	items, err := v.queue.List()
+	if err != nil {
+		g.Log().Error(v.ctx, "metrics list queue error", err)
+		return
+	}
+	depth := map[queue.State]int{
+		queue.StatePending:          0,
+		queue.StateFailed:           0,
+		queue.StateSigned:           0,
+		queue.StateParked:           0,
+		queue.StateCancelled:        0,
+		queue.StateAwaitingApproval: 0,
+		queue.StateSimulated:        0,
+	}
+	nearest := math.Inf(1)
+	for _, item := range items {
+		depth[item.State]++
+		switch item.State {
+		case queue.StateSigned, queue.StateCancelled, queue.StateSimulated:
+			continue
+		}
+		if item.Deadline.IsZero() {
+			continue
+		}
+		left := time.Until(item.Deadline).Seconds()
+		if item.State == queue.StateParked && left < 0 {
+			// missed and given up, reported by the watchdog
+			continue
+		}
+		if left < nearest {
+			nearest = left
+		}
+	}
+	for state, n := range depth {
+		metrics.QueueDepth.WithLabelValues(string(state)).Set(float64(n))
+	}
+	metrics.NearestDeadline.Set(nearest)
+}
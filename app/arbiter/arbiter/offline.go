@@ -0,0 +1,285 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+// BundleVersion is the version of the offline signing bundle and signature
+// formats.
+const BundleVersion = 1
+
+// Bundle is an arbitration request exported for signing on a host without
+// network access. It holds everything the signing policy is checked
+// against.
+type Bundle struct {
+	Version   int         `json:"version"`
+	Network   string      `json:"network"`
+	CreatedAt time.Time   `json:"createdAt"`
+	TxId      common.Hash `json:"txId"`
+	// ArbitrationRequested event in the event archive format
+	Event json.RawMessage `json:"event"`
+	// on-chain record of the arbitration at export
+	Transaction BundleTransaction `json:"transaction"`
+	// BTC key the arbiter manager expects the signature from
+	OperatorBtcPubKey hexutil.Bytes `json:"operatorBtcPubKey"`
+	// hash to sign, the double SHA-256 of the BTC transaction
+	SigHash common.Hash `json:"sigHash"`
+}
+
+// BundleTransaction is the JSON form of contract.TransactionInfo.
+type BundleTransaction struct {
+	Dapp       common.Address `json:"dapp"`
+	Arbitrator common.Address `json:"arbitrator"`
+	StartTime  int64          `json:"startTime"`
+	Deadline   int64          `json:"deadline"`
+	Status     uint8          `json:"status"`
+	BtcTx      hexutil.Bytes  `json:"btcTx"`
+	BtcTxHash  common.Hash    `json:"btcTxHash"`
+	Script     hexutil.Bytes  `json:"script"`
+	Signature  hexutil.Bytes  `json:"signature,omitempty"`
+	// outputs the BTC transaction spends
+	Prevouts []BundlePrevout `json:"prevouts"`
+}
+
+type BundlePrevout struct {
+	TxHash common.Hash   `json:"txHash"`
+	Index  uint32        `json:"index"`
+	Script hexutil.Bytes `json:"script"`
+	Amount *big.Int      `json:"amount"`
+}
+
+// BundleSignature is the result of signing a Bundle, imported on the online
+// host.
+type BundleSignature struct {
+	Version   int           `json:"version"`
+	TxId      common.Hash   `json:"txId"`
+	SigHash   common.Hash   `json:"sigHash"`
+	Signature hexutil.Bytes `json:"signature"`
+	PubKey    hexutil.Bytes `json:"pubKey"`
+	SignedAt  time.Time     `json:"signedAt"`
+}
+
+// NewBundle exports the arbitration request event with its on-chain record
+// info. It fails if arbitrator may not sign it.
+func NewBundle(network, arbitrator string, event *events.ContractLogEvent, info *contract.TransactionInfo, operatorBtcPubKey []byte, now time.Time) (*Bundle, error) {
+	archived, err := events.MarshalArchive(event)
+	if err != nil {
+		return nil, err
+	}
+	if len(event.Topics) < 2 {
+		return nil, errors.New("not an ArbitrationRequested event")
+	}
+	tx := BundleTransaction{
+		Dapp:       info.Dapp,
+		Arbitrator: info.Arbitrator,
+		Status:     info.Status,
+		BtcTx:      info.BtcTx,
+		BtcTxHash:  info.BtcTxHash,
+		Script:     info.Script,
+		Signature:  info.Signature,
+	}
+	if info.StartTime != nil {
+		tx.StartTime = info.StartTime.Int64()
+	}
+	if info.Deadline != nil {
+		tx.Deadline = info.Deadline.Int64()
+	}
+	for _, utxo := range info.Utxos {
+		tx.Prevouts = append(tx.Prevouts, BundlePrevout{TxHash: utxo.TxHash, Index: utxo.Index, Script: utxo.Script, Amount: utxo.Amount})
+	}
+	b := &Bundle{
+		Version:           BundleVersion,
+		Network:           network,
+		CreatedAt:         now.UTC(),
+		TxId:              event.Topics[1],
+		Event:             archived,
+		Transaction:       tx,
+		OperatorBtcPubKey: operatorBtcPubKey,
+		SigHash:           SigHash(info.BtcTx),
+	}
+	if _, err := b.Verify(arbitrator, now); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// BundleRequest is the content of a verified Bundle.
+type BundleRequest struct {
+	Event *events.ContractLogEvent
+	BtcTx *wire.MsgTx
+	// amount spent by each input, nil where the record has no prevout
+	InputAmounts []*big.Int
+}
+
+// Verify checks that the event, the on-chain record and the hash to sign of
+// the bundle agree, and applies the signing policy for arbitrator at now.
+func (b *Bundle) Verify(arbitrator string, now time.Time) (*BundleRequest, error) {
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	event, err := events.UnmarshalArchive(b.Event)
+	if err != nil {
+		return nil, fmt.Errorf("decode event: %w", err)
+	}
+	if len(event.Topics) < 2 || event.Topics[0] != events.ArbitrationRequested || event.Topics[1] != b.TxId {
+		return nil, errors.New("event is not the ArbitrationRequested event of txId")
+	}
+	loanABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterABI))
+	if err != nil {
+		return nil, err
+	}
+	ev := make(map[string]interface{})
+	if err := loanABI.UnpackIntoMap(ev, "ArbitrationRequested", event.EventData); err != nil {
+		return nil, fmt.Errorf("decode event: %w", err)
+	}
+	btcTx, _ := ev["btcTx"].([]byte)
+	script, _ := ev["script"].([]byte)
+	eventArbitrator, _ := ev["arbitrator"].(common.Address)
+	if !strings.EqualFold(eventArbitrator.String(), arbitrator) {
+		return nil, fmt.Errorf("PolicyRejected: request is for arbitrator %s", eventArbitrator)
+	}
+	// the contract stores the BTC transaction of the request, a different
+	// one in the record means the bundle was tampered with
+	if !bytes.Equal(btcTx, b.Transaction.BtcTx) || !bytes.Equal(script, b.Transaction.Script) {
+		return nil, errors.New("BTC transaction or script of the event and the record differ")
+	}
+	if SigHash(btcTx) != b.SigHash {
+		return nil, errors.New("sigHash is not the hash of the BTC transaction")
+	}
+	if err := checkPolicy(b.Transaction.info(), arbitrator, now); err != nil {
+		return nil, err
+	}
+
+	tx, err := decodeTx(btcTx)
+	if err != nil {
+		return nil, fmt.Errorf("decode BTC transaction: %w", err)
+	}
+	req := &BundleRequest{Event: event, BtcTx: tx}
+	for _, in := range tx.TxIn {
+		prevout := b.Transaction.prevout(in.PreviousOutPoint)
+		if prevout == nil && len(b.Transaction.Prevouts) > 0 {
+			return nil, fmt.Errorf("input %s spends no output of the record", in.PreviousOutPoint)
+		}
+		var amount *big.Int
+		if prevout != nil {
+			amount = prevout.Amount
+		}
+		req.InputAmounts = append(req.InputAmounts, amount)
+	}
+	return req, nil
+}
+
+// info returns the record in the form checkPolicy takes.
+func (t *BundleTransaction) info() *contract.TransactionInfo {
+	return &contract.TransactionInfo{
+		Dapp:       t.Dapp,
+		Arbitrator: t.Arbitrator,
+		StartTime:  big.NewInt(t.StartTime),
+		Deadline:   big.NewInt(t.Deadline),
+		BtcTx:      t.BtcTx,
+		BtcTxHash:  t.BtcTxHash,
+		Status:     t.Status,
+		Signature:  t.Signature,
+		Script:     t.Script,
+	}
+}
+
+// prevout returns the recorded output spent by outpoint, or nil.
+func (t *BundleTransaction) prevout(outpoint wire.OutPoint) *BundlePrevout {
+	for i, p := range t.Prevouts {
+		if p.Index != outpoint.Index {
+			continue
+		}
+		// txids are shown byte reversed, accept the record in either order
+		reversed := p.TxHash
+		for l, r := 0, len(reversed)-1; l < r; l, r = l+1, r-1 {
+			reversed[l], reversed[r] = reversed[r], reversed[l]
+		}
+		if bytes.Equal(p.TxHash[:], outpoint.Hash[:]) || bytes.Equal(reversed[:], outpoint.Hash[:]) {
+			return &t.Prevouts[i]
+		}
+	}
+	return nil
+}
+
+// SignBundle verifies b for arbitrator at now and signs it with the hex
+// encoded arbiter key, which must be the operator key of the bundle.
+func SignBundle(b *Bundle, arbitrator, privateKey string, now time.Time) (*BundleSignature, error) {
+	if _, err := b.Verify(arbitrator, now); err != nil {
+		return nil, err
+	}
+	pubKey, err := GetPubKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(pubKey, hex.EncodeToString(b.OperatorBtcPubKey)) {
+		return nil, fmt.Errorf("key %s is not the operator BTC key %s", pubKey, hex.EncodeToString(b.OperatorBtcPubKey))
+	}
+	signature, err := signBtcTx(privateKey, b.Transaction.BtcTx)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyBytes, _ := hex.DecodeString(pubKey)
+	return &BundleSignature{
+		Version:   BundleVersion,
+		TxId:      b.TxId,
+		SigHash:   b.SigHash,
+		Signature: signature,
+		PubKey:    pubKeyBytes,
+		SignedAt:  now.UTC(),
+	}, nil
+}
+
+// Verify checks that s is a signature of sigHash by the operator key
+// pubKey.
+func (s *BundleSignature) Verify(sigHash common.Hash, pubKey []byte) error {
+	if s.Version != BundleVersion {
+		return fmt.Errorf("unsupported signature version %d", s.Version)
+	}
+	if s.SigHash != sigHash {
+		return fmt.Errorf("signature is for sigHash %s, the BTC transaction on chain hashes to %s", s.SigHash, sigHash)
+	}
+	key, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("parse operator BTC key: %w", err)
+	}
+	signature, err := ecdsa.ParseDERSignature(s.Signature)
+	if err != nil {
+		return fmt.Errorf("parse signature: %w", err)
+	}
+	if !signature.Verify(sigHash[:], key) {
+		return errors.New("signature does not verify against the operator BTC key")
+	}
+	return nil
+}
+
+// VerifyImport checks a signature made offline against the current
+// on-chain record of its arbitration before it is submitted: the record
+// must still pass the signing policy and s must sign its BTC transaction
+// with the operator key.
+func VerifyImport(s *BundleSignature, info *contract.TransactionInfo, arbitrator string, operatorBtcPubKey []byte, now time.Time) error {
+	if err := checkPolicy(info, arbitrator, now); err != nil {
+		return err
+	}
+	return s.Verify(SigHash(info.BtcTx), operatorBtcPubKey)
+}
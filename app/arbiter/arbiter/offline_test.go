@@ -0,0 +1,154 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+const testOfflineKey = "1f3b7e0bbd0a2d3c6f4a5e1d8c9b0a7f6e5d4c3b2a19080706050403020100ff"
+
+func testBundle(t *testing.T, now time.Time) (*Bundle, string) {
+	t.Helper()
+	loanABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arbitrator := common.HexToAddress("0x0262aB0ED65373cC855C34529fDdeAa0e686D913")
+
+	prevHash := chainhash.Hash{7}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 1), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	btcTx := buf.Bytes()
+
+	data, err := loanABI.Events["ArbitrationRequested"].Inputs.NonIndexed().Pack(
+		arbitrator, btcTx, []byte{0x51}, common.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &events.ContractLogEvent{
+		EventData: data,
+		TxHash:    common.Hash{1},
+		Topics:    []common.Hash{events.ArbitrationRequested, {0xaa}, common.BytesToHash(arbitrator.Bytes())},
+		Block:     100,
+	}
+	info := &contract.TransactionInfo{
+		Arbitrator: arbitrator,
+		StartTime:  big.NewInt(now.Add(-time.Hour).Unix()),
+		Deadline:   big.NewInt(now.Add(time.Hour).Unix()),
+		BtcTx:      btcTx,
+		Status:     uint8(contract.TransactionArbitrated),
+		Script:     []byte{0x51},
+		Utxos:      []contract.UTXO{{TxHash: prevHash, Index: 1, Script: []byte{0x51}, Amount: big.NewInt(2000)}},
+	}
+	pubKey, err := GetPubKey(testOfflineKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKeyBytes, _ := hex.DecodeString(pubKey)
+	b, err := NewBundle("mainnet", arbitrator.String(), event, info, pubKeyBytes, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, arbitrator.String()
+}
+
+func TestBundleSignRoundTrip(t *testing.T) {
+	now := time.Now()
+	b, arbitrator := testBundle(t, now)
+
+	// the bundle crosses the air gap as JSON
+	content, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported Bundle
+	if err := json.Unmarshal(content, &imported); err != nil {
+		t.Fatal(err)
+	}
+	req, err := imported.Verify(arbitrator, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.InputAmounts) != 1 || req.InputAmounts[0].Int64() != 2000 {
+		t.Errorf("input amounts: %v", req.InputAmounts)
+	}
+
+	sig, err := SignBundle(&imported, arbitrator, testOfflineKey, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := imported.Transaction.info()
+	if err := VerifyImport(sig, info, arbitrator, b.OperatorBtcPubKey, now); err != nil {
+		t.Fatal(err)
+	}
+	info.Signature = sig.Signature
+	if err := VerifyImport(sig, info, arbitrator, b.OperatorBtcPubKey, now); err == nil {
+		t.Error("signature imported twice")
+	}
+	if err := sig.Verify(common.Hash{1}, b.OperatorBtcPubKey); err == nil {
+		t.Error("signature accepted for another sigHash")
+	}
+	other, _ := GetPubKey(strings.Repeat("11", 32))
+	otherBytes, _ := hex.DecodeString(other)
+	if err := sig.Verify(b.SigHash, otherBytes); err == nil {
+		t.Error("signature accepted for another key")
+	}
+}
+
+func TestBundleVerifyRejects(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		modify func(b *Bundle)
+		at     time.Time
+		key    string
+	}{
+		{name: "btcTx replaced", modify: func(b *Bundle) { b.Transaction.BtcTx = append(b.Transaction.BtcTx, 0) }},
+		{name: "sigHash replaced", modify: func(b *Bundle) { b.SigHash = common.Hash{1} }},
+		{name: "other request", modify: func(b *Bundle) { b.TxId = common.Hash{0xbb} }},
+		{name: "already signed", modify: func(b *Bundle) { b.Transaction.Signature = []byte{1} }},
+		{name: "not arbitrated", modify: func(b *Bundle) { b.Transaction.Status = uint8(contract.TransactionCompleted) }},
+		{name: "unknown prevout", modify: func(b *Bundle) { b.Transaction.Prevouts[0].Index = 5 }},
+		{name: "deadline passed", at: now.Add(2 * time.Hour)},
+		{name: "other key", key: strings.Repeat("11", 32)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, arbitrator := testBundle(t, now)
+			if tt.modify != nil {
+				tt.modify(b)
+			}
+			at, key := now, testOfflineKey
+			if !tt.at.IsZero() {
+				at = tt.at
+			}
+			if tt.key != "" {
+				key = tt.key
+			}
+			if _, err := SignBundle(b, arbitrator, key, at); err == nil {
+				t.Error("bundle signed")
+			}
+		})
+	}
+}
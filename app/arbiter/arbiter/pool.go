@@ -0,0 +1,153 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	// the signer wakes up at least this often without a queue change
+	signerIdleDelay = time.Minute
+	// delay before retrying after the queue could not be read
+	signerErrorDelay = 10 * time.Second
+)
+
+// signerPool bounds the number of requests processed at once and keeps a
+// request from being picked up twice while it is in flight.
+type signerPool struct {
+	slots chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[queue.Key]struct{}
+}
+
+func newSignerPool(workers int) *signerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &signerPool{
+		slots:    make(chan struct{}, workers),
+		done:     make(chan struct{}, 1),
+		inflight: make(map[queue.Key]struct{}),
+	}
+}
+
+// Done receives after a worker finished and a slot is free again.
+func (p *signerPool) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *signerPool) busy(key queue.Key) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.inflight[key]
+	return ok
+}
+
+// acquire takes a free slot for key without blocking.
+func (p *signerPool) acquire(key queue.Key) bool {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		return false
+	}
+	p.wg.Add(1)
+	p.mu.Lock()
+	p.inflight[key] = struct{}{}
+	p.mu.Unlock()
+	return true
+}
+
+func (p *signerPool) release(key queue.Key) {
+	p.mu.Lock()
+	delete(p.inflight, key)
+	p.mu.Unlock()
+	<-p.slots
+	p.wg.Done()
+	select {
+	case p.done <- struct{}{}:
+	default:
+	}
+}
+
+// Wait blocks until no request is in flight.
+func (p *signerPool) Wait() {
+	p.wg.Wait()
+}
+
+// dispatchRequests hands the pending requests and the failed ones due for
+// a retry to free workers, most urgent first, and auto-approves waiting
+// requests close to their deadline. It returns how long to wait for the
+// next retry or auto-approval.
+func (v *Arbiter) dispatchRequests(pool *signerPool) time.Duration {
+	items, err := v.queue.List(queue.StatePending, queue.StateFailed, queue.StateAwaitingApproval)
+	if err != nil {
+		g.Log().Error(v.ctx, "list pending requests error", err)
+		return signerErrorDelay
+	}
+	now := time.Now()
+	// an approved request is pending and wakes the signer again
+	wait := v.autoApprove(items, now)
+	var due []*queue.Item
+	for _, item := range items {
+		if item.State == queue.StateAwaitingApproval || pool.busy(item.Key) {
+			continue
+		}
+		if item.State == queue.StateFailed && !item.Due(now) {
+			if left := item.NextAttempt.Sub(now); left < wait {
+				wait = left
+			}
+			continue
+		}
+		due = append(due, item)
+	}
+
+	sortByUrgency(due)
+	for _, item := range due {
+		if !pool.acquire(item.Key) {
+			// all workers busy, the next pass starts when one is done
+			break
+		}
+		go func(item *queue.Item) {
+			defer pool.release(item.Key)
+			// not cancelled on shutdown, a started submission is finished
+			ctx, cancel := context.WithTimeout(gctx.NeverDone(v.ctx), v.cfg().SignTimeout)
+			defer cancel()
+			err := runRecovered(func() error {
+				v.processRequest(ctx, item)
+				return nil
+			})
+			if err != nil {
+				g.Log().Critical(v.ctx, "processRequest crashed, key:", item.Key, "reason:", err)
+				v.markFailed(item, permanent(err))
+			}
+		}(item)
+	}
+	return wait
+}
+
+// sortByUrgency orders requests by arbitration deadline. Requests whose
+// deadline is not known yet come first, their deadline may be the closest.
+func sortByUrgency(items []*queue.Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Deadline, items[j].Deadline
+		if a.IsZero() != b.IsZero() {
+			return a.IsZero()
+		}
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+func TestSortByUrgency(t *testing.T) {
+	now := time.Now()
+	item := func(tx byte, deadline time.Time, created time.Duration) *queue.Item {
+		return &queue.Item{
+			Key:       queue.Key{TxHash: common.Hash{tx}},
+			Deadline:  deadline,
+			CreatedAt: now.Add(created),
+		}
+	}
+	items := []*queue.Item{
+		item(1, now.Add(2*time.Hour), 0),
+		item(2, time.Time{}, time.Minute),
+		item(3, now.Add(time.Hour), time.Minute),
+		item(4, now.Add(time.Hour), 0),
+		item(5, time.Time{}, 0),
+	}
+	sortByUrgency(items)
+
+	expected := []byte{5, 2, 4, 3, 1}
+	for i, tx := range expected {
+		if items[i].Key.TxHash[0] != tx {
+			t.Fatalf("position %d: expected request %d, got %d", i, tx, items[i].Key.TxHash[0])
+		}
+	}
+}
+
+func TestSignerPoolBounds(t *testing.T) {
+	pool := newSignerPool(2)
+	a, b, c := queue.Key{LogIndex: 1}, queue.Key{LogIndex: 2}, queue.Key{LogIndex: 3}
+	if !pool.acquire(a) || !pool.acquire(b) {
+		t.Fatal("expected two free slots")
+	}
+	if pool.acquire(c) {
+		t.Fatal("acquired more slots than workers")
+	}
+	if !pool.busy(a) || pool.busy(c) {
+		t.Fatal("unexpected in flight requests")
+	}
+
+	pool.release(a)
+	select {
+	case <-pool.Done():
+	default:
+		t.Fatal("no done signal after release")
+	}
+	if pool.busy(a) || !pool.acquire(c) {
+		t.Fatal("released slot not reusable")
+	}
+}
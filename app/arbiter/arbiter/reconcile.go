@@ -0,0 +1,227 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	reconcileInterval = 30 * time.Minute
+	// a signed request still waiting on chain after this long is requeued
+	signedGracePeriod = 10 * time.Minute
+	// file in the data directory keeping the reconciler progress
+	reconcileStateFile = "reconciled.json"
+)
+
+// engagement is a transaction registered with us as arbitrator.
+type engagement struct {
+	id    common.Hash
+	block uint64
+}
+
+// reconcileState is the reconciler progress saved across restarts: the last
+// reconciled block and the engagements that had not reached a final status.
+type reconcileState struct {
+	Height      uint64            `json:"height"`
+	Engagements []savedEngagement `json:"engagements"`
+}
+
+type savedEngagement struct {
+	Id    common.Hash `json:"id"`
+	Block uint64      `json:"block"`
+}
+
+// loadReconcileState returns the engagements saved in dataDir and the block
+// to continue from, start if nothing was saved or start is beyond the saved
+// height. Engagements registered before start are dropped.
+func loadReconcileState(dataDir string, start uint64) (map[common.Hash]*engagement, uint64, error) {
+	engagements := make(map[common.Hash]*engagement)
+	data, err := os.ReadFile(filepath.Join(dataDir, reconcileStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return engagements, start, nil
+	}
+	if err != nil {
+		return engagements, start, err
+	}
+	var state reconcileState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return engagements, start, err
+	}
+	for _, e := range state.Engagements {
+		if e.Block >= start {
+			engagements[e.Id] = &engagement{id: e.Id, block: e.Block}
+		}
+	}
+	if state.Height+1 > start {
+		start = state.Height + 1
+	}
+	return engagements, start, nil
+}
+
+// saveReconcileState saves the engagements reconciled up to height in
+// dataDir.
+func saveReconcileState(dataDir string, height uint64, engagements map[common.Hash]*engagement) error {
+	state := reconcileState{Height: height, Engagements: make([]savedEngagement, 0, len(engagements))}
+	for _, e := range engagements {
+		state.Engagements = append(state.Engagements, savedEngagement{Id: e.id, Block: e.block})
+	}
+	return writeJSONFile(filepath.Join(dataDir, reconcileStateFile), state)
+}
+
+// reconcileArbitrations lists every transaction registered with us as
+// arbitrator from chain and requeues the ones still waiting for our
+// signature that are missing from the local request queue. It runs once at
+// startup and then periodically, so requests dropped by the listener are
+// recovered. The progress is saved in the data directory, a restart goes on
+// from the last reconciled block.
+func (v *Arbiter) reconcileArbitrations() error {
+	g.Log().Info(v.ctx, "reconcileArbitrations start")
+
+	engagements, from, err := loadReconcileState(v.config.DataDir, v.escStartHeight)
+	if err != nil {
+		g.Log().Error(v.ctx, "load reconcile state error, starting from", v.escStartHeight, err)
+		engagements, from = make(map[common.Hash]*engagement), v.escStartHeight
+	}
+	for {
+		to, err := v.escNode.GetLatestHeight(v.ctx)
+		if err != nil {
+			g.Log().Error(v.ctx, "reconcile GetLatestHeight error", err)
+		} else if to >= from {
+			if err := v.reconcile(engagements, from, to); err != nil {
+				g.Log().Error(v.ctx, "reconcile error", err)
+			} else {
+				from = to + 1
+				if err := saveReconcileState(v.config.DataDir, to, engagements); err != nil {
+					g.Log().Error(v.ctx, "save reconcile state error", err)
+				}
+			}
+		}
+
+		if !v.sleep(reconcileInterval) {
+			return nil
+		}
+	}
+}
+
+// reconcile adds the engagements registered between from and to and
+// requeues the ones waiting for a signature. Engagements that reached a
+// final status are dropped.
+func (v *Arbiter) reconcile(engagements map[common.Hash]*engagement, from, to uint64) error {
+	ctx, cancel := context.WithTimeout(v.ctx, reconcileInterval)
+	defer cancel()
+
+	arbitrator := common.HexToAddress(v.config.ESCArbiterAddress)
+	registered, err := v.escNode.FindRegisteredTransactions(ctx, arbitrator, from, to)
+	if err != nil {
+		return err
+	}
+	for _, evt := range registered {
+		if len(evt.Topics) < 2 {
+			continue
+		}
+		engagements[evt.Topics[1]] = &engagement{id: evt.Topics[1], block: evt.Block}
+	}
+	g.Log().Info(v.ctx, "reconcile blocks", from, "to", to, "engagements:", len(engagements))
+
+	// failed requests are retried by the signer, parked and waiting ones need
+	// an operator and cancelled ones were dropped by one. Simulated ones are
+	// left to the arbiter that sends in a dry run, and requeued once live.
+	states := []queue.State{queue.StatePending, queue.StateFailed, queue.StateParked, queue.StateCancelled,
+		queue.StateAwaitingApproval}
+	if v.config.DryRun {
+		states = append(states, queue.StateSimulated)
+	}
+	queued := v.requestsById(states...)
+	signed := v.requestsById(queue.StateSigned)
+	for id, e := range engagements {
+		info, err := v.escNode.GetTransactionById(ctx, id)
+		if err != nil {
+			g.Log().Error(v.ctx, "reconcile GetTransactionById error", err, "id:", id.String())
+			continue
+		}
+		switch info.TxStatus() {
+		case contract.TransactionActive:
+			continue
+		case contract.TransactionArbitrated:
+			if len(info.Signature) > 0 {
+				continue
+			}
+		default:
+			delete(engagements, id)
+			continue
+		}
+
+		if _, ok := queued[id]; ok {
+			continue
+		}
+		if s, ok := signed[id]; ok && time.Since(s.UpdatedAt) < signedGracePeriod {
+			continue
+		}
+		if err := v.requeueArbitration(ctx, e, to); err != nil {
+			g.Log().Error(v.ctx, "reconcile requeue error", err, "id:", id.String())
+		}
+	}
+	return nil
+}
+
+func (v *Arbiter) requeueArbitration(ctx context.Context, e *engagement, to uint64) error {
+	evt, err := v.escNode.FindArbitrationRequest(ctx, e.id, e.block, to)
+	if err != nil {
+		return err
+	}
+	if evt == nil {
+		g.Log().Warning(v.ctx, "reconcile no ArbitrationRequested event found, id:", e.id.String())
+		return nil
+	}
+	created, err := v.queue.Enqueue(evt)
+	if err != nil {
+		return err
+	}
+	if !created {
+		item, err := v.queue.Find(evt)
+		if err != nil {
+			return err
+		}
+		if err := v.queue.Requeue(item.Key); err != nil {
+			return err
+		}
+	}
+	logCtx := logging.WithEvent(v.ctx, evt)
+	g.Log().Notice(logCtx, "reconcile requeued arbitration request")
+	v.logger.Warning(logCtx, "RECONCILE: requeued arbitration request")
+	return nil
+}
+
+// requestsById indexes the queued requests in any of states by arbitration
+// id. The most recently updated request wins if an id is queued twice.
+func (v *Arbiter) requestsById(states ...queue.State) map[common.Hash]*queue.Item {
+	result := make(map[common.Hash]*queue.Item)
+	items, err := v.queue.List(states...)
+	if err != nil {
+		g.Log().Error(v.ctx, "list requests error", err)
+		return result
+	}
+	for _, item := range items {
+		if len(item.Event.Topics) < 2 {
+			continue
+		}
+		id := item.Event.Topics[1]
+		if prev, ok := result[id]; !ok || item.UpdatedAt.After(prev.UpdatedAt) {
+			result[id] = item
+		}
+	}
+	return result
+}
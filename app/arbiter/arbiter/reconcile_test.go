@@ -0,0 +1,55 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestReconcileState(t *testing.T) {
+	dir := t.TempDir()
+
+	engagements, from, err := loadReconcileState(dir, 100)
+	if err != nil || from != 100 || len(engagements) != 0 {
+		t.Fatalf("no state: %d engagements, from %d, %v", len(engagements), from, err)
+	}
+
+	a, b := common.HexToHash("0xa"), common.HexToHash("0xb")
+	engagements[a] = &engagement{id: a, block: 120}
+	engagements[b] = &engagement{id: b, block: 180}
+	if err := saveReconcileState(dir, 200, engagements); err != nil {
+		t.Fatal(err)
+	}
+
+	engagements, from, err = loadReconcileState(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != 201 {
+		t.Errorf("from %d, want 201", from)
+	}
+	if len(engagements) != 2 || engagements[a].block != 120 || engagements[b].block != 180 {
+		t.Errorf("engagements %v", engagements)
+	}
+
+	// a start height beyond the saved progress wins and drops older engagements
+	engagements, from, err = loadReconcileState(dir, 150)
+	if err != nil || from != 201 || len(engagements) != 1 || engagements[b] == nil {
+		t.Errorf("start 150: %v, from %d, %v", engagements, from, err)
+	}
+	engagements, from, err = loadReconcileState(dir, 300)
+	if err != nil || from != 300 || len(engagements) != 0 {
+		t.Errorf("start 300: %v, from %d, %v", engagements, from, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, reconcileStateFile), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, from, err = loadReconcileState(dir, 100); err == nil || from != 100 {
+		t.Errorf("corrupt state: from %d, %v", from, err)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+)
+
+const redialTimeout = 30 * time.Second
+
+// liveSettings are the config fields, or field prefixes ending in ".", that
+// Reload applies without a restart.
+var liveSettings = []string{
+	"Http",
+	"DeadlineThresholds",
+	"SignTimeout",
+	"ReadyMaxLag",
+	"FeeClaimGasBudget",
+	"AlertLowBalance",
+	"Alert.",
+	"Approval.",
+	"Log.Level",
+	"Log.Format",
+}
+
+// derivedSettings are built from DataDir and the key file directory, a
+// change of them is reported there.
+var derivedSettings = map[string]bool{
+	"ArbiterKeyFilePath":     true,
+	"LoanSignedEventPath":    true,
+	"LoanNeedSignReqPath":    true,
+	"LoanNeedSignFailedPath": true,
+	"LoanNeedSignSignedPath": true,
+	"LoanLogPath":            true,
+	"QueueDBPath":            true,
+	"LoanImportedPath":       true,
+	"LoanCompletedEventPath": true,
+	"LoanFeeClaimedPath":     true,
+	"RevenueLedgerPath":      true,
+	"DryRunReportPath":       true,
+}
+
+// settingChange is a config field that differs between two configs.
+type settingChange struct {
+	name     string
+	old, new string
+}
+
+func (c settingChange) String() string {
+	if isSecret(c.name) {
+		return c.name + ": changed"
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.name, c.old, c.new)
+}
+
+// isSecret reports whether the values of a setting must not be logged.
+// RPC and webhook URLs often carry an API key, proxies a password.
+func isSecret(name string) bool {
+	for _, suffix := range []string{"Token", "Password", "URL", "Headers", "Http", "Proxy"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLive(name string) bool {
+	for _, s := range liveSettings {
+		if name == s || (strings.HasSuffix(s, ".") && strings.HasPrefix(name, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffConfig returns the fields that differ between old and new, named by
+// their path in config.Config.
+func diffConfig(old, new *config.Config) []settingChange {
+	var changes []settingChange
+	var walk func(prefix string, a, b reflect.Value)
+	walk = func(prefix string, a, b reflect.Value) {
+		if a.Kind() == reflect.Struct {
+			for i := 0; i < a.NumField(); i++ {
+				walk(prefix+a.Type().Field(i).Name+".", a.Field(i), b.Field(i))
+			}
+			return
+		}
+		name := strings.TrimSuffix(prefix, ".")
+		if derivedSettings[name] || reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return
+		}
+		changes = append(changes, settingChange{name: name, old: fmt.Sprint(a.Interface()), new: fmt.Sprint(b.Interface())})
+	}
+	walk("", reflect.ValueOf(*old), reflect.ValueOf(*new))
+	return changes
+}
+
+// cfg returns the config in effect, which Reload replaces. Settings that
+// need a restart are read from v.config.
+func (v *Arbiter) cfg() *config.Config {
+	if cfg := v.live.Load(); cfg != nil {
+		return cfg
+	}
+	return v.config
+}
+
+// Reload applies the settings of cfg that can change while the arbiter
+// runs and refuses the others, which keep their value until the next
+// restart. It returns the applied and refused changes, and an error if an
+// applicable change failed.
+func (v *Arbiter) Reload(cfg *config.Config) (applied, refused []string, err error) {
+	v.reloadMu.Lock()
+	defer v.reloadMu.Unlock()
+
+	cur := v.cfg()
+	next := *cur
+	var errs []error
+	for _, c := range diffConfig(cur, cfg) {
+		if !isLive(c.name) {
+			refused = append(refused, c.String())
+			continue
+		}
+		applied = append(applied, c.String())
+	}
+	if len(applied) == 0 {
+		v.logReload(applied, refused)
+		return applied, refused, nil
+	}
+
+	if cfg.Http != cur.Http {
+		ctx, cancel := context.WithTimeout(v.ctx, redialTimeout)
+		if err := v.escNode.Redial(ctx, cfg.Http); err != nil {
+			errs = append(errs, fmt.Errorf("switch ESC RPC endpoint: %w", err))
+		} else {
+			next.Http = cfg.Http
+		}
+		cancel()
+	}
+	if cfg.Log.Level != cur.Log.Level || cfg.Log.Format != cur.Log.Format {
+		logCfg := cur.Log
+		logCfg.Level, logCfg.Format = cfg.Log.Level, cfg.Log.Format
+		if err := logging.Reconfigure(logCfg, g.Log(), v.logger); err != nil {
+			errs = append(errs, fmt.Errorf("reconfigure logging: %w", err))
+		} else {
+			next.Log = logCfg
+		}
+	}
+	if !reflect.DeepEqual(cfg.Alert, cur.Alert) {
+		next.Alert = cfg.Alert
+		alertSettings := alertConfig(&next)
+		v.alerts.Update(alertSettings, alertSettings.Notifiers()...)
+	}
+	next.DeadlineThresholds = cfg.DeadlineThresholds
+	next.SignTimeout = cfg.SignTimeout
+	next.ReadyMaxLag = cfg.ReadyMaxLag
+	next.FeeClaimGasBudget = cfg.FeeClaimGasBudget
+	next.AlertLowBalance = cfg.AlertLowBalance
+	next.Approval = cfg.Approval
+	v.live.Store(&next)
+
+	if len(errs) > 0 {
+		// report only what took effect
+		applied = nil
+		for _, c := range diffConfig(cur, &next) {
+			applied = append(applied, c.String())
+		}
+	}
+	v.logReload(applied, refused)
+	return applied, refused, errors.Join(errs...)
+}
+
+func (v *Arbiter) logReload(applied, refused []string) {
+	if len(applied) == 0 && len(refused) == 0 {
+		g.Log().Info(v.ctx, "config reloaded, nothing changed")
+		return
+	}
+	for _, c := range applied {
+		g.Log().Notice(v.ctx, "config reloaded:", c)
+		v.logger.Notice(v.ctx, "CONFIG: reloaded", c)
+	}
+	for _, c := range refused {
+		g.Log().Warning(v.ctx, "config change needs a restart, not applied:", c)
+		v.logger.Warning(v.ctx, "CONFIG: needs restart, not applied", c)
+	}
+}
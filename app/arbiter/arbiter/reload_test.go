@@ -0,0 +1,88 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+)
+
+func TestReload(t *testing.T) {
+	logger, err := logging.NewEventLog(logging.Config{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur := &config.Config{
+		Network:       "mainnet",
+		DataDir:       "/data",
+		QueueDBPath:   "/data/loan/queue.db",
+		SignTimeout:   time.Minute,
+		AdminAPIToken: "secret",
+	}
+	v := &Arbiter{ctx: context.Background(), config: cur, logger: logger, alerts: alert.NewDispatcher(alert.Config{})}
+
+	next := *cur
+	next.Network = "testnet"
+	next.DataDir = "/other"
+	next.QueueDBPath = "/other/loan/queue.db"
+	next.SignTimeout = 2 * time.Minute
+	next.AdminAPIToken = "other secret"
+	next.Alert.Slack.URL = "https://hooks.slack.com/services/secret"
+	applied, refused, err := v.Reload(&next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"SignTimeout: 1m0s -> 2m0s", "Alert.Slack.URL: changed"}
+	if strings.Join(applied, "|") != strings.Join(want, "|") {
+		t.Errorf("applied: %q", applied)
+	}
+	want = []string{"Network: mainnet -> testnet", "DataDir: /data -> /other", "AdminAPIToken: changed"}
+	if strings.Join(refused, "|") != strings.Join(want, "|") {
+		t.Errorf("refused: %q", refused)
+	}
+	for _, c := range append(applied, refused...) {
+		if strings.Contains(c, "secret") {
+			t.Errorf("secret logged: %q", c)
+		}
+	}
+
+	if v.cfg().SignTimeout != 2*time.Minute || v.cfg().Network != "mainnet" || v.cfg().DataDir != "/data" {
+		t.Errorf("config in effect: %+v", v.cfg())
+	}
+	if !v.alerts.Enabled() {
+		t.Error("alerting not enabled by the reload")
+	}
+	if cur.SignTimeout != time.Minute {
+		t.Error("startup config modified")
+	}
+
+	applied, refused, err = v.Reload(v.cfg())
+	if err != nil || len(applied) != 0 || len(refused) != 0 {
+		t.Errorf("reload without changes: %q %q %v", applied, refused, err)
+	}
+}
+
+func TestAlertConfigSource(t *testing.T) {
+	cfg := &config.Config{ESCArbiterAddress: "0xabc"}
+	if source := alertConfig(cfg).Source; source != "0xabc" {
+		t.Errorf("default source: %q", source)
+	}
+	cfg.DryRun = true
+	if source := alertConfig(cfg).Source; source != "0xabc (dry run)" {
+		t.Errorf("dry run source: %q", source)
+	}
+	cfg.Alert.Source = "arbiter-1"
+	if source := alertConfig(cfg).Source; source != "arbiter-1 (dry run)" {
+		t.Errorf("configured dry run source: %q", source)
+	}
+	if cfg.Alert.Source != "arbiter-1" {
+		t.Error("config changed")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 30 * time.Minute
+)
+
+// permanentError marks a failure that retrying cannot fix, such as an
+// undecodable event or a request rejected by policy.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// transientMessages are RPC errors that go away by themselves: the node is
+// unreachable, or the transaction raced another one from the same account.
+var transientMessages = []string{
+	"connection refused",
+	"connection reset",
+	"no such host",
+	"timeout",
+	"deadline exceeded",
+	"eof",
+	"too many requests",
+	"502 bad gateway",
+	"503 service unavailable",
+	"nonce too low",
+	"replacement transaction underpriced",
+	"transaction underpriced",
+	"gas price",
+	"already known",
+	"insufficient funds",
+}
+
+// isTransient reports whether a failed request should be retried: timeouts,
+// errors that report themselves temporary, like BTC API rate limits, and the
+// transientMessages. Other errors, a revert among them, park the request
+// for an operator, who can retry it.
+func isTransient(err error) bool {
+	var p *permanentError
+	if errors.As(err, &p) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay is the exponential backoff before retrying after attempts
+// failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// markFailed schedules a retry of item for transient failures, or parks it
+// for permanent ones and once the next retry would miss the deadline.
+func (v *Arbiter) markFailed(item *queue.Item, reason error) {
+	if !isTransient(reason) {
+		v.park(item, reason)
+		return
+	}
+	next := time.Now().Add(retryDelay(item.Attempts + 1))
+	if !item.Deadline.IsZero() && next.After(item.Deadline) {
+		v.park(item, errors.New("deadline passed, last error: "+reason.Error()))
+		return
+	}
+	ctx := logging.WithEvent(v.ctx, item.Event)
+	if err := v.queue.MarkFailed(item.Key, reason, next); err != nil {
+		g.Log().Error(ctx, "MarkFailed error", err, "key:", item.Key)
+	}
+	g.Log().Warning(ctx, "request failed, retry at", next.Format(time.RFC3339), "key:", item.Key, "err:", reason)
+	if v.config.DryRun {
+		v.reportRequest(item, dryRunRetry, reason, next.UTC().Format(time.RFC3339))
+	}
+	v.logger.Error(ctx, "SIGN: request failed, err:", reason.Error(), "retry:", next.Format(time.RFC3339))
+	a := requestAlert(item, alert.KindSignFailed, alert.Warning, "signing failed, retrying")
+	a.Text += "\nerror: " + reason.Error() + "\nretry: " + next.UTC().Format(time.RFC3339)
+	v.alerts.Send(a)
+}
+
+func (v *Arbiter) park(item *queue.Item, reason error) {
+	ctx := logging.WithEvent(v.ctx, item.Event)
+	if err := v.queue.Park(item.Key, reason); err != nil {
+		g.Log().Error(ctx, "Park error", err, "key:", item.Key)
+	}
+	g.Log().Error(ctx, "request parked, key:", item.Key, "reason:", reason)
+	if v.config.DryRun {
+		v.reportRequest(item, dryRunPark, reason, "")
+	}
+	v.logger.Error(ctx, "SIGN: request parked, reason:", reason.Error())
+	a := requestAlert(item, alert.KindParked, alert.Critical, "signing failed, request parked")
+	a.Text += "\nreason: " + reason.Error()
+	v.alerts.Send(a)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/mempool"
+)
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		err       error
+		transient bool
+	}{
+		{fmt.Errorf("SubmitSignatureFailed: %w", errors.New("dial tcp: connection refused")), true},
+		{fmt.Errorf("SubmitSignatureFailed: %w", errors.New("nonce too low")), true},
+		{errors.New("replacement transaction underpriced"), true},
+		{errors.New("execution reverted: already submitted"), false},
+		{permanent(errors.New("unpack event into map failed")), false},
+		{fmt.Errorf("wrapped: %w", permanent(errors.New("PolicyRejected"))), false},
+		{errors.New("something unexpected"), false},
+		{fmt.Errorf("GetRawTransaction: %w", context.DeadlineExceeded), true},
+		{fmt.Errorf("GetRawTransaction: %w", &mempool.StatusError{StatusCode: 503}), true},
+		{fmt.Errorf("GetRawTransaction: %w", &mempool.StatusError{StatusCode: 400}), false},
+		{&net.DNSError{Err: "i/o timeout", IsTimeout: true}, true},
+	}
+	for _, c := range cases {
+		if got := isTransient(c.err); got != c.transient {
+			t.Errorf("isTransient(%q) = %v, want %v", c.err, got, c.transient)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	expected := []time.Duration{
+		30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute,
+		8 * time.Minute, 16 * time.Minute, 30 * time.Minute, 30 * time.Minute,
+	}
+	for i, want := range expected {
+		if got := retryDelay(i + 1); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+	if got := retryDelay(1000); got != retryMaxDelay {
+		t.Errorf("retryDelay(1000) = %v", got)
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/metrics"
+)
+
+const (
+	feeClaimInterval = 5 * time.Minute
+	// a fee claim not mined after this long is sent again if still possible
+	pendingClaimTimeout = time.Hour
+)
+
+// revenueRecord is one line of the arbitration fee ledger. Amounts are in
+// wei of ELA on ESC.
+type revenueRecord struct {
+	Time          time.Time `json:"time"`
+	TxId          string    `json:"txId"`
+	Dapp          string    `json:"dapp"`
+	ClaimTxHash   string    `json:"claimTxHash"`
+	Block         uint64    `json:"block"`
+	ArbitratorFee string    `json:"arbitratorFee"`
+	SystemFee     string    `json:"systemFee"`
+	GasUsed       uint64    `json:"gasUsed"`
+	GasPrice      string    `json:"gasPrice"`
+	GasCost       string    `json:"gasCost"`
+}
+
+func (v *Arbiter) claimArbitrationFees() error {
+	g.Log().Info(v.ctx, "claimArbitrationFees start")
+
+	for {
+		files, err := os.ReadDir(v.config.LoanCompletedEventPath)
+		if err != nil {
+			g.Log().Error(v.ctx, "read completed event dir error", err)
+		}
+		for _, file := range files {
+			if v.ctx.Err() != nil {
+				return nil
+			}
+			v.claimArbitrationFee(file.Name())
+		}
+
+		if !v.sleep(feeClaimInterval) {
+			return nil
+		}
+	}
+}
+
+func (v *Arbiter) claimArbitrationFee(fileName string) {
+	filePath := v.config.LoanCompletedEventPath + "/" + fileName
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		g.Log().Error(v.ctx, "read file error", err)
+		return
+	}
+	logEvt, err := v.decodeLogEvtByFileContent(fileContent)
+	if err != nil || len(logEvt.Topics) < 2 {
+		g.Log().Error(v.ctx, "decode completed event error", err, "file:", filePath)
+		v.moveToDirectory(filePath, v.config.LoanFeeClaimedPath+"/"+fileName+".failed")
+		return
+	}
+	txId := logEvt.Topics[1]
+	logCtx := logging.WithEvent(v.ctx, logEvt)
+
+	ctx, cancel := context.WithTimeout(v.ctx, feeClaimInterval)
+	defer cancel()
+
+	// a claim sent on an earlier pass whose receipt did not arrive in time
+	pendingPath := filepath.Join(v.config.LoanFeeClaimedPath, fileName+".Pending")
+	if content, err := os.ReadFile(pendingPath); err == nil {
+		var pending revenueRecord
+		if err := json.Unmarshal(content, &pending); err != nil {
+			g.Log().Error(logCtx, "decode pending fee claim error", err, "file:", pendingPath)
+		} else if v.checkPendingClaim(ctx, logCtx, fileName, &pending) {
+			return
+		}
+	}
+
+	info, err := v.escNode.GetTransactionById(ctx, txId)
+	if err != nil {
+		g.Log().Error(logCtx, "GetTransactionById error", err, "id:", txId.String())
+		return
+	}
+	if !strings.EqualFold(info.Arbitrator.String(), v.config.ESCArbiterAddress) {
+		g.Log().Warning(logCtx, "completed transaction is not ours, id:", txId.String())
+		v.moveToDirectory(filePath, v.config.LoanFeeClaimedPath+"/"+fileName+".NotMine")
+		return
+	}
+	if info.TxStatus() != contract.TransactionCompleted {
+		able, err := v.escNode.IsAbleCompletedTransaction(ctx, txId)
+		if err != nil || !able {
+			g.Log().Debug(logCtx, "fee not claimable yet, id:", txId.String(), "status:", info.TxStatus())
+			return
+		}
+	}
+
+	arbitratorFee, systemFee, err := v.escNode.SimulateTransferArbitrationFee(ctx, txId)
+	if err != nil {
+		g.Log().Debug(logCtx, "fee not claimable yet, id:", txId.String(), "err:", err)
+		return
+	}
+	tx, hash, err := v.escNode.TransferArbitrationFee(ctx, txId, v.cfg().FeeClaimGasBudget)
+	switch {
+	case errors.Is(err, contract.ErrGasBudget):
+		g.Log().Warning(logCtx, "fee claim not sent, id:", txId.String(), "err:", err)
+		return
+	case errors.Is(err, contract.ErrDryRun):
+		// the event file stays, the arbiter claims the fee once live
+		maxGasCost := contract.MaxGasCost(tx)
+		if v.reportFeeClaim(txId, arbitratorFee, systemFee, maxGasCost) {
+			v.logger.Info(logCtx, "DRYRUN: transferArbitrationFee simulated, arbitratorFee:", arbitratorFee.String(),
+				"maxGasCost:", maxGasCost.String())
+		}
+		return
+	case err != nil:
+		g.Log().Error(logCtx, "transferArbitrationFee error", err, "id:", txId.String())
+		v.logger.Error(logCtx, "FEE: transferArbitrationFee failed, err:", err.Error())
+		return
+	}
+
+	record := &revenueRecord{
+		Time:          time.Now().UTC(),
+		TxId:          txId.String(),
+		Dapp:          info.Dapp.String(),
+		ClaimTxHash:   hash.String(),
+		ArbitratorFee: arbitratorFee.String(),
+		SystemFee:     systemFee.String(),
+		GasPrice:      tx.GasPrice().String(),
+	}
+	if err := writeJSONFile(pendingPath, record); err != nil {
+		g.Log().Error(logCtx, "save pending fee claim error", err, "txhash:", hash.String())
+	}
+	receipt, err := v.escNode.WaitForReceipt(ctx, hash)
+	if err != nil {
+		g.Log().Error(logCtx, "wait transferArbitrationFee receipt error", err, "txhash:", hash.String(),
+			"checked again on the next pass")
+		return
+	}
+	v.finishFeeClaim(logCtx, fileName, record, receipt)
+}
+
+// checkPendingClaim looks up the receipt of a fee claim sent on an earlier
+// pass. It reports whether the claim is settled or still waiting; a claim
+// not mined for pendingClaimTimeout is left to be sent again.
+func (v *Arbiter) checkPendingClaim(ctx, logCtx context.Context, fileName string, record *revenueRecord) bool {
+	hash := common.HexToHash(record.ClaimTxHash)
+	receipt, err := v.escNode.TransactionReceipt(ctx, hash)
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		if time.Since(record.Time) < pendingClaimTimeout {
+			g.Log().Info(logCtx, "fee claim not mined yet, txhash:", hash.String())
+			return true
+		}
+		g.Log().Warning(logCtx, "fee claim not mined since", record.Time, "txhash:", hash.String())
+		return false
+	case err != nil:
+		g.Log().Error(logCtx, "transferArbitrationFee receipt error", err, "txhash:", hash.String())
+		return true
+	}
+	return v.finishFeeClaim(logCtx, fileName, record, receipt)
+}
+
+// finishFeeClaim books a mined fee claim in the revenue ledger and moves the
+// completed event out of the way. It reports whether the claim succeeded,
+// a reverted one is sent again on the next pass.
+func (v *Arbiter) finishFeeClaim(logCtx context.Context, fileName string, record *revenueRecord, receipt *types.Receipt) bool {
+	pendingPath := filepath.Join(v.config.LoanFeeClaimedPath, fileName+".Pending")
+	if err := os.Remove(pendingPath); err != nil && !os.IsNotExist(err) {
+		g.Log().Error(logCtx, "remove pending fee claim error", err)
+	}
+	gasPrice, _ := new(big.Int).SetString(record.GasPrice, 10)
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	metrics.AddGas(metrics.GasFeeClaim, receipt.GasUsed, gasPrice)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		g.Log().Error(logCtx, "transferArbitrationFee reverted, txhash:", record.ClaimTxHash)
+		v.logger.Error(logCtx, "FEE: transferArbitrationFee reverted, tx:", record.ClaimTxHash)
+		return false
+	}
+
+	if err := v.history.RecordReceipt(v.ctx, receipt); err != nil {
+		g.Log().Error(logCtx, "RecordReceipt error", err)
+	}
+
+	gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	record.Block = receipt.BlockNumber.Uint64()
+	record.GasUsed = receipt.GasUsed
+	record.GasCost = gasCost.String()
+	if err := appendJSONLine(v.config.RevenueLedgerPath, record); err != nil {
+		g.Log().Error(logCtx, "append revenue ledger error", err, "record:", record)
+	}
+	v.moveToDirectory(filepath.Join(v.config.LoanCompletedEventPath, fileName),
+		v.config.LoanFeeClaimedPath+"/"+fileName+".Succeed")
+	v.logger.Info(logCtx, "FEE: transferArbitrationFee succeed, tx:", record.ClaimTxHash,
+		"arbitratorFee:", record.ArbitratorFee, "gasCost:", gasCost.String())
+	return true
+}
+
+// writeJSONFile replaces the file at path with record in JSON.
+func writeJSONFile(path string, record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendJSONLine appends record as a line of JSON to the file at path.
+func appendJSONLine(path string, record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/history"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+)
+
+func TestFinishFeeClaim(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := logging.NewEventLog(logging.Config{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		LoanCompletedEventPath: filepath.Join(dir, "completed"),
+		LoanFeeClaimedPath:     filepath.Join(dir, "claimed"),
+		RevenueLedgerPath:      filepath.Join(dir, "revenue_ledger.jsonl"),
+	}
+	for _, d := range []string{cfg.LoanCompletedEventPath, cfg.LoanFeeClaimedPath} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v := &Arbiter{ctx: context.Background(), config: cfg, history: history.Nop{}, logger: logger}
+
+	claim := func(fileName string, status uint64) bool {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(cfg.LoanCompletedEventPath, fileName), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		record := &revenueRecord{TxId: "0xaa", ClaimTxHash: common.Hash{1}.String(), ArbitratorFee: "100", GasPrice: "2"}
+		pendingPath := filepath.Join(cfg.LoanFeeClaimedPath, fileName+".Pending")
+		if err := writeJSONFile(pendingPath, record); err != nil {
+			t.Fatal(err)
+		}
+		receipt := &types.Receipt{Status: status, GasUsed: 50, BlockNumber: big.NewInt(7)}
+		ok := v.finishFeeClaim(v.ctx, fileName, record, receipt)
+		if _, err := os.Stat(pendingPath); !os.IsNotExist(err) {
+			t.Errorf("%s: pending claim kept", fileName)
+		}
+		return ok
+	}
+
+	// a reverted claim is sent again, its event stays
+	if claim("reverted", types.ReceiptStatusFailed) {
+		t.Error("reverted claim finished")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.LoanCompletedEventPath, "reverted")); err != nil {
+		t.Error("event of a reverted claim moved")
+	}
+
+	if !claim("mined", types.ReceiptStatusSuccessful) {
+		t.Error("mined claim not finished")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.LoanFeeClaimedPath, "mined.Succeed")); err != nil {
+		t.Error("event of a mined claim not moved")
+	}
+	f, err := os.Open(cfg.RevenueLedgerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []revenueRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record revenueRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 1 || records[0].GasCost != "100" || records[0].Block != 7 || records[0].ArbitratorFee != "100" {
+		t.Errorf("ledger: %+v", records)
+	}
+}
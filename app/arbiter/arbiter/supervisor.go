@@ -0,0 +1,85 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	restartMinDelay = time.Second
+	restartMaxDelay = time.Minute
+	// a subsystem that ran this long before crashing starts over at the
+	// minimum restart delay
+	restartResetAfter = 5 * time.Minute
+)
+
+// panicError is a recovered panic of a subsystem.
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.value, e.stack)
+}
+
+// supervise runs fn until shutdown. fn returns nil once the context is
+// cancelled; if it panics or returns early it is restarted after a growing
+// delay and the reason is reported.
+func (v *Arbiter) supervise(name string, fn func() error) {
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		delay := restartMinDelay
+		for {
+			started := time.Now()
+			err := runRecovered(fn)
+			if v.ctx.Err() != nil {
+				g.Log().Info(v.ctx, name, "stopped")
+				return
+			}
+			if err == nil {
+				err = errors.New("exited unexpectedly")
+			}
+			if time.Since(started) >= restartResetAfter {
+				delay = restartMinDelay
+			}
+			g.Log().Critical(v.ctx, name, "crashed, restart in", delay, "reason:", err)
+			v.logger.Critical(v.ctx, "SUPERVISOR:", name, "crashed, restart in", delay, "reason:", err)
+			v.health.setDown(name, err.Error())
+			if !v.sleep(delay) {
+				return
+			}
+			v.health.setUp(name)
+			delay *= 2
+			if delay > restartMaxDelay {
+				delay = restartMaxDelay
+			}
+		}
+	}()
+}
+
+func runRecovered(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r, stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// sleep waits for d, it returns false if the arbiter shuts down meanwhile.
+func (v *Arbiter) sleep(d time.Duration) bool {
+	select {
+	case <-v.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/glog"
+)
+
+func TestRunRecovered(t *testing.T) {
+	err := runRecovered(func() error { panic("boom") })
+	var p *panicError
+	if !errors.As(err, &p) || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected recovered panic, got %v", err)
+	}
+	if isTransient(permanent(err)) {
+		t.Fatal("a crashed request must not be retried")
+	}
+
+	want := errors.New("rpc down")
+	if err := runRecovered(func() error { return want }); err != want {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+func TestSuperviseRestartsUntilShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := glog.New()
+	logger.SetStdoutPrint(false)
+	v := &Arbiter{ctx: ctx, logger: logger, health: newHealthState()}
+
+	var runs int32
+	v.supervise("test", func() error {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			panic("first run crashes")
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&runs) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("subsystem not restarted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		v.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not stop after shutdown")
+	}
+}
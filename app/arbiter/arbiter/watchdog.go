@@ -0,0 +1,232 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	watchdogInterval = time.Minute
+	// critical alerts are repeated at this interval until the request is resolved
+	criticalRepeatInterval = 5 * time.Minute
+)
+
+type deadlineLevel int
+
+const (
+	deadlineOk deadlineLevel = iota
+	deadlineNotice
+	deadlineWarning
+	deadlineCritical
+	deadlineMissed
+)
+
+func (l deadlineLevel) String() string {
+	switch l {
+	case deadlineOk:
+		return "OK"
+	case deadlineNotice:
+		return "NOTICE"
+	case deadlineWarning:
+		return "WARNING"
+	case deadlineCritical:
+		return "CRITICAL"
+	case deadlineMissed:
+		return "MISSED"
+	}
+	return "UNKNOWN"
+}
+
+// watchedRequest is an open arbitration request tracked by the watchdog.
+type watchedRequest struct {
+	txId      common.Hash
+	deadline  time.Time
+	level     deadlineLevel
+	lastAlert time.Time
+	// outcome already reported while a local file is still open
+	resolved bool
+}
+
+// watchDeadlines tracks the time left to answer every open arbitration
+// request and escalates as the on-chain deadline approaches. Once a request
+// leaves the queue its final outcome is read from chain and reported.
+func (v *Arbiter) watchDeadlines() error {
+	g.Log().Info(v.ctx, "watchDeadlines start")
+
+	watched := make(map[common.Hash]*watchedRequest)
+	for {
+		open := v.openRequestIds()
+		for txId := range open {
+			w, ok := watched[txId]
+			if !ok {
+				w = &watchedRequest{txId: txId}
+				watched[txId] = w
+			}
+			if !w.resolved {
+				v.checkDeadline(w)
+			}
+		}
+		for txId, w := range watched {
+			if _, ok := open[txId]; !ok {
+				if !w.resolved {
+					v.reportOutcome(w)
+				}
+				delete(watched, txId)
+			}
+		}
+
+		if !v.sleep(watchdogInterval) {
+			return nil
+		}
+	}
+}
+
+// openRequestIds returns the arbitration ids of all requests that are still
+// waiting for our signature on chain, either queued, retried, parked,
+// awaiting approval or signed with the submission not yet mined.
+func (v *Arbiter) openRequestIds() map[common.Hash]struct{} {
+	open := make(map[common.Hash]struct{})
+	for id := range v.requestsById(queue.StatePending, queue.StateFailed, queue.StateParked, queue.StateAwaitingApproval,
+		queue.StateSigned) {
+		open[id] = struct{}{}
+	}
+	return open
+}
+
+// checkDeadline updates the escalation level of w, or reports its outcome if
+// it was already answered or closed on chain.
+func (v *Arbiter) checkDeadline(w *watchedRequest) {
+	ctx, cancel := context.WithTimeout(v.ctx, watchdogInterval)
+	defer cancel()
+	info, err := v.escNode.GetTransactionById(ctx, w.txId)
+	if err != nil {
+		g.Log().Error(v.ctx, "watchdog GetTransactionById error", err, "id:", w.txId.String())
+		return
+	}
+	w.deadline = time.Unix(info.Deadline.Int64(), 0)
+	if info.TxStatus() != contract.TransactionArbitrated || len(info.Signature) > 0 {
+		// answered or closed on chain while the request is still queued, a
+		// signed request stays watched until its submission is mined
+		v.reportOutcomeWithInfo(w, info)
+		w.resolved = true
+		return
+	}
+
+	left := time.Until(w.deadline)
+	if v.escalate(w, left, time.Now()) {
+		v.alertDeadline(w, left)
+	}
+}
+
+// escalate raises the level of w for the time left and reports whether to
+// alert: on every higher level, and again every criticalRepeatInterval from
+// critical on.
+func (v *Arbiter) escalate(w *watchedRequest, left time.Duration, now time.Time) bool {
+	level := v.deadlineLevel(left)
+	if level > w.level || (level >= deadlineCritical && now.Sub(w.lastAlert) >= criticalRepeatInterval) {
+		w.level = level
+		w.lastAlert = now
+		return true
+	}
+	return false
+}
+
+func (v *Arbiter) deadlineLevel(left time.Duration) deadlineLevel {
+	if left <= 0 {
+		return deadlineMissed
+	}
+	level := deadlineOk
+	for i, threshold := range v.cfg().DeadlineThresholds {
+		if left <= threshold {
+			level = deadlineNotice + deadlineLevel(i)
+		}
+	}
+	if level > deadlineCritical {
+		level = deadlineCritical
+	}
+	return level
+}
+
+func (v *Arbiter) alertDeadline(w *watchedRequest, left time.Duration) {
+	ctx := logging.WithArbitration(v.ctx, logging.Arbitration{TxId: w.txId.String()})
+	deadline := w.deadline.UTC().Format(time.RFC3339)
+	left = left.Round(time.Second)
+	switch w.level {
+	case deadlineOk:
+		g.Log().Info(ctx, "arbitration request open, deadline:", deadline, "left:", left)
+	case deadlineNotice:
+		g.Log().Notice(ctx, "arbitration deadline approaching, deadline:", deadline, "left:", left)
+		v.logger.Notice(ctx, "DEADLINE: approaching, deadline:", deadline, "left:", left)
+	case deadlineWarning:
+		g.Log().Warning(ctx, "arbitration deadline near, deadline:", deadline, "left:", left)
+		v.logger.Warning(ctx, "DEADLINE: near, deadline:", deadline, "left:", left)
+	case deadlineCritical:
+		g.Log().Critical(ctx, "arbitration deadline imminent, stake at risk, deadline:", deadline, "left:", left)
+		v.logger.Critical(ctx, "DEADLINE: imminent, stake at risk, deadline:", deadline, "left:", left)
+	case deadlineMissed:
+		g.Log().Critical(ctx, "arbitration deadline missed, stake may be slashed, deadline:", deadline)
+		v.logger.Critical(ctx, "DEADLINE: missed, deadline:", deadline)
+	}
+
+	a := &alert.Alert{
+		Kind:  alert.KindDeadline,
+		Key:   w.txId.String(),
+		Title: "arbitration deadline approaching",
+		Text:  "txId: " + w.txId.String() + "\ndeadline: " + deadline + "\nleft: " + left.String(),
+	}
+	switch w.level {
+	case deadlineOk:
+		return
+	case deadlineNotice:
+		a.Severity = alert.Info
+	case deadlineWarning:
+		a.Severity = alert.Warning
+	case deadlineCritical:
+		a.Severity = alert.Critical
+		a.Title = "arbitration deadline imminent, stake at risk"
+	case deadlineMissed:
+		a.Kind = alert.KindDeadlineMissed
+		a.Severity = alert.Critical
+		a.Title = "arbitration deadline missed, stake may be slashed"
+		a.Text = "txId: " + w.txId.String() + "\ndeadline: " + deadline
+	}
+	v.alerts.Send(a)
+}
+
+func (v *Arbiter) reportOutcome(w *watchedRequest) {
+	ctx, cancel := context.WithTimeout(v.ctx, watchdogInterval)
+	defer cancel()
+	info, err := v.escNode.GetTransactionById(ctx, w.txId)
+	if err != nil {
+		g.Log().Error(v.ctx, "watchdog GetTransactionById error", err, "id:", w.txId.String())
+		return
+	}
+	v.reportOutcomeWithInfo(w, info)
+}
+
+func (v *Arbiter) reportOutcomeWithInfo(w *watchedRequest, info *contract.TransactionInfo) {
+	ctx := logging.WithArbitration(v.ctx, logging.Arbitration{TxId: w.txId.String()})
+	status := info.TxStatus()
+	switch {
+	case len(info.Signature) > 0 || status == contract.TransactionSubmitted || status == contract.TransactionCompleted:
+		g.Log().Notice(ctx, "arbitration resolved, status:", status)
+		v.logger.Info(ctx, "DEADLINE: resolved, status:", status)
+	case status == contract.TransactionArbitrated:
+		// the local request was removed but nothing was submitted
+		g.Log().Warning(ctx, "arbitration request left the queue unanswered, deadline:", w.deadline)
+		v.logger.Warning(ctx, "DEADLINE: request left the queue unanswered")
+	default:
+		g.Log().Error(ctx, "arbitration closed without our signature, status:", status)
+		v.logger.Error(ctx, "DEADLINE: closed without signature, status:", status)
+	}
+}
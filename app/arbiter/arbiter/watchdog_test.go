@@ -0,0 +1,78 @@
+// Copyright (c) 2025 The bel2 developers
+
+package arbiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+)
+
+func TestDeadlineLevel(t *testing.T) {
+	v := &Arbiter{config: &config.Config{DeadlineThresholds: []time.Duration{6 * time.Hour, time.Hour, 15 * time.Minute}}}
+	tests := []struct {
+		left time.Duration
+		want deadlineLevel
+	}{
+		{24 * time.Hour, deadlineOk},
+		{6*time.Hour + time.Second, deadlineOk},
+		{6 * time.Hour, deadlineNotice},
+		{2 * time.Hour, deadlineNotice},
+		{time.Hour, deadlineWarning},
+		{15 * time.Minute, deadlineCritical},
+		{time.Second, deadlineCritical},
+		{0, deadlineMissed},
+		{-time.Hour, deadlineMissed},
+	}
+	for _, tt := range tests {
+		if got := v.deadlineLevel(tt.left); got != tt.want {
+			t.Errorf("%s left: %s, want %s", tt.left, got, tt.want)
+		}
+	}
+
+	// more thresholds than levels stay critical
+	v.config.DeadlineThresholds = []time.Duration{4 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour}
+	if got := v.deadlineLevel(30 * time.Minute); got != deadlineCritical {
+		t.Errorf("extra threshold: %s", got)
+	}
+	v.config.DeadlineThresholds = nil
+	if got := v.deadlineLevel(time.Minute); got != deadlineOk {
+		t.Errorf("no thresholds: %s", got)
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	v := &Arbiter{config: &config.Config{DeadlineThresholds: []time.Duration{6 * time.Hour, time.Hour, 15 * time.Minute}}}
+	w := &watchedRequest{}
+	now := time.Now()
+
+	steps := []struct {
+		after time.Duration
+		left  time.Duration
+		alert bool
+		level deadlineLevel
+	}{
+		{0, 12 * time.Hour, false, deadlineOk},
+		{0, 5 * time.Hour, true, deadlineNotice},
+		// same level, no repeat below critical
+		{time.Hour, 4 * time.Hour, false, deadlineNotice},
+		{0, 30 * time.Minute, true, deadlineWarning},
+		{0, 10 * time.Minute, true, deadlineCritical},
+		{time.Minute, 9 * time.Minute, false, deadlineCritical},
+		// critical repeats every criticalRepeatInterval
+		{criticalRepeatInterval, 4 * time.Minute, true, deadlineCritical},
+		// missed is a new level, alerted right away
+		{time.Minute, -time.Second, true, deadlineMissed},
+		{time.Minute, -time.Minute, false, deadlineMissed},
+		{criticalRepeatInterval, -time.Hour, true, deadlineMissed},
+		// an extended deadline does not lower the level
+		{time.Minute, 12 * time.Hour, false, deadlineMissed},
+	}
+	for i, step := range steps {
+		now = now.Add(step.after)
+		if alert := v.escalate(w, step.left, now); alert != step.alert || w.level != step.level {
+			t.Errorf("step %d, %s left: alert %v level %s, want %v %s", i, step.left, alert, w.level, step.alert, step.level)
+		}
+	}
+}
@@ -2,13 +2,30 @@
 
 package config
 
+import (
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/btcsource"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+)
+
 type Config struct {
+	// name of the network profile the defaults below come from
 	Network string
 
 	Signer   bool
 	Listener bool
+	// sign and simulate requests and fee claims without sending anything,
+	// writing what would have been sent to DryRunReportPath
+	DryRun bool
 
-	Http                             string
+	Http string
+	// expected chain id of Http, zero skips the check
+	ESCChainID                       int64
 	ESCStartHeight                   uint64
 	ESCArbiterContractAddress        string
 	ESCArbiterManagerContractAddress string
@@ -28,7 +45,102 @@ type Config struct {
 	LoanNeedSignSignedPath string
 	// loan logs path
 	LoanLogPath string
+	// arbitration request queue database
+	QueueDBPath string
+	// requests imported from LoanNeedSignReqPath into the queue
+	LoanImportedPath string
+	// completed transactions waiting for fee claim
+	LoanCompletedEventPath string
+	// completed transactions whose fee was claimed
+	LoanFeeClaimedPath string
+	// arbitration fee ledger file
+	RevenueLedgerPath string
+	// dry run report file
+	DryRunReportPath string
+
+	// claim arbitration fees of completed transactions
+	FeeClaim bool
+	// max gas cost in wei for one fee claim, nil means unlimited
+	FeeClaimGasBudget *big.Int
+
+	// time left before the arbitration deadline at which the watchdog
+	// escalates to notice, warning and critical, in descending order
+	DeadlineThresholds []time.Duration
+
+	// requests signed and submitted in parallel
+	SignWorkers int
+	// time limit for signing and submitting one request
+	SignTimeout time.Duration
+
+	// listen address of the metrics and probe endpoints, empty disables them
+	MonitorAddr string
+	// blocks the listener may lag behind the chain head and still be ready
+	ReadyMaxLag uint64
+
+	// listen address of the admin API, "unix:<path>" for a Unix socket,
+	// empty disables it
+	AdminAPIAddr string
+	// bearer token required by the admin API, empty allows local access only
+	AdminAPIToken string
 
-	// bitcoin node rpc
+	// BTC network, and the Esplora compatible API used to look up BTC
+	// transactions
+	BTCParams *chaincfg.Params
+	BTCAPI    string
+	// HTTP or SOCKS5 proxy for BTCAPI, empty uses the proxy environment
+	// variables
 	Proxy string
+	// confirmed BTC transactions kept in memory, 0 disables the cache
+	BTCAPICacheSize int
+	// BTC data backend, "esplora" for BTCAPI, "bitcoind" or "electrum"
+	BTCSource string
+	// bitcoind JSON-RPC URL with credentials, or a cookie file for them
+	BitcoindURL        string
+	BitcoindCookieFile string
+	// Electrum server, "tcp://host:port" or "ssl://host:port"
+	ElectrumAddr string
+
+	// history storage backend, "file" or "pgsql"
+	Storage string
+
+	// alert delivery, deduplication and rate limit
+	Alert alert.Config
+	// alert when the operator ESC balance falls below this amount in wei,
+	// nil disables the check
+	AlertLowBalance *big.Int
+
+	// requests an operator approves before they are signed
+	Approval ApprovalConfig
+
+	// log level and format, event log rotation and syslog target
+	Log logging.Config
+}
+
+// BTCSourceConfig returns the settings of the BTC data backend.
+func (c *Config) BTCSourceConfig() btcsource.Config {
+	return btcsource.Config{
+		Backend:            c.BTCSource,
+		EsploraURL:         c.BTCAPI,
+		BitcoindURL:        c.BitcoindURL,
+		BitcoindCookieFile: c.BitcoindCookieFile,
+		ElectrumAddr:       c.ElectrumAddr,
+		Proxy:              c.Proxy,
+		EsploraCacheSize:   c.BTCAPICacheSize,
+	}
+}
+
+// ApprovalConfig selects the requests that stop for an operator review
+// after passing the automated checks. Nothing selected signs every request
+// right away.
+type ApprovalConfig struct {
+	// every request needs approval
+	All bool
+	// requests of these dapps need approval
+	Dapps []string
+	// requests moving at least this many satoshis need approval, zero
+	// disables the threshold
+	MinValue int64
+	// approve a waiting request once its deadline is this close, zero waits
+	// for the operator until the deadline passes
+	AutoApproveMargin time.Duration
 }
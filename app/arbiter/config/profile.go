@@ -0,0 +1,97 @@
+// Copyright (c) 2025 The bel2 developers
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Profile bundles the chain endpoints, contracts and BTC network of a
+// deployment. The network setting selects one, every value of it can be
+// overridden in the config file.
+type Profile struct {
+	Name string
+
+	// ESC RPC endpoint and its chain id, zero accepts any chain
+	ESCRPC     string
+	ESCChainID int64
+	// arbiter and arbiter manager contracts, empty when the network has no
+	// well known deployment
+	ESCArbiterContractAddress        string
+	ESCArbiterManagerContractAddress string
+	// first block with arbiter contract events
+	ESCStartHeight uint64
+
+	BTCParams *chaincfg.Params
+	// Esplora compatible REST API of the BTC network
+	BTCAPI string
+}
+
+var profiles = map[string]Profile{
+	"mainnet": {
+		Name:                             "mainnet",
+		ESCRPC:                           "https://api.elastos.io/esc",
+		ESCChainID:                       20,
+		ESCArbiterContractAddress:        "0xA10b92006743Ef3B12077da67e465963743b03D3",
+		ESCArbiterManagerContractAddress: "0x9963b5214434776D043A4e98Bc7f33321F6aaCfc",
+		ESCStartHeight:                   28437808,
+		BTCParams:                        &chaincfg.MainNetParams,
+		BTCAPI:                           "https://mempool.space/api",
+	},
+	"testnet": {
+		Name:       "testnet",
+		ESCRPC:     "https://api-testnet.elastos.io/esc",
+		ESCChainID: 21,
+		BTCParams:  &chaincfg.TestNet3Params,
+		BTCAPI:     "https://mempool.space/testnet/api",
+	},
+	"signet": {
+		Name:       "signet",
+		ESCRPC:     "https://api-testnet.elastos.io/esc",
+		ESCChainID: 21,
+		BTCParams:  &chaincfg.SigNetParams,
+		BTCAPI:     "https://mempool.space/signet/api",
+	},
+	// a local ESC node and a bitcoind regtest with electrs or esplora
+	"devnet": {
+		Name:      "devnet",
+		ESCRPC:    "http://127.0.0.1:20636",
+		BTCParams: &chaincfg.RegressionNetParams,
+		BTCAPI:    "http://127.0.0.1:3002",
+	},
+}
+
+// profileAliases are accepted names of profiles.
+var profileAliases = map[string]string{
+	"regtest": "devnet",
+}
+
+// GetProfile returns the profile called name, case insensitive.
+func GetProfile(name string) (Profile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := profileAliases[name]; ok {
+		name = alias
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown network %q, need one of %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	return p, nil
+}
+
+// ProfileNames returns the names of the profiles, sorted.
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles)+len(profileAliases))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	for name := range profileAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
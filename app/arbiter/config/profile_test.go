@@ -0,0 +1,32 @@
+// Copyright (c) 2025 The bel2 developers
+
+package config
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestGetProfile(t *testing.T) {
+	p, err := GetProfile(" Mainnet ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ESCChainID != 20 || p.BTCParams != &chaincfg.MainNetParams || p.ESCArbiterContractAddress == "" {
+		t.Fatalf("mainnet: %+v", p)
+	}
+	p, err = GetProfile("regtest")
+	if err != nil || p.Name != "devnet" || p.BTCParams.Name != chaincfg.RegressionNetParams.Name {
+		t.Fatalf("regtest alias: %+v %v", p, err)
+	}
+	if _, err := GetProfile("mars"); err == nil {
+		t.Fatal("unknown network accepted")
+	}
+	for _, name := range ProfileNames() {
+		p, err := GetProfile(name)
+		if err != nil || p.ESCRPC == "" || p.BTCParams == nil || p.BTCAPI == "" {
+			t.Errorf("incomplete profile %s: %+v %v", name, p, err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 The bel2 developers
+
+package contract
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+)
+
+// ArbitratorAdmin sends arbitrator management transactions to the arbiter
+// manager contract. Unlike the operator key used by ArbitratorContract, the
+// transactions are signed by the arbitrator key itself.
+type ArbitratorAdmin struct {
+	submitter *ContractSubmitter
+	abi       abi.ABI
+	manager   common.Address
+}
+
+// AdminCall is a single arbiter manager method invocation.
+type AdminCall struct {
+	Method string
+	Args   []interface{}
+	Value  *big.Int
+}
+
+// AdminTransaction is a prepared but not yet signed admin transaction.
+type AdminTransaction struct {
+	Call AdminCall
+	From common.Address
+	To   common.Address
+	Tx   *types.Transaction
+}
+
+// MaxCost returns the value plus the maximum gas fee of the transaction.
+func (t *AdminTransaction) MaxCost() *big.Int {
+	return t.Tx.Cost()
+}
+
+func NewAdmin(ctx context.Context, http string, managerAddress string, privateKey string) (*ArbitratorAdmin, error) {
+	client, err := ConnectRPC(ctx, http)
+	if err != nil {
+		return nil, err
+	}
+	managerABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	submitter, err := NewSubmitter(ctx, client, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ArbitratorAdmin{
+		submitter: submitter,
+		abi:       managerABI,
+		manager:   common.HexToAddress(managerAddress),
+	}, nil
+}
+
+// Address returns the arbitrator address derived from the signing key.
+func (a *ArbitratorAdmin) Address() common.Address {
+	return a.submitter.Address()
+}
+
+func RegisterArbitratorByStakeETH(btcAddress string, btcPubKey []byte, feeRate, deadline, stake *big.Int) AdminCall {
+	return AdminCall{Method: "registerArbitratorByStakeETH", Args: []interface{}{btcAddress, btcPubKey, feeRate, deadline}, Value: stake}
+}
+
+func RegisterArbitratorByStakeNFT(tokenIds []*big.Int, btcAddress string, btcPubKey []byte, feeRate, deadline *big.Int) AdminCall {
+	return AdminCall{Method: "registerArbitratorByStakeNFT", Args: []interface{}{tokenIds, btcAddress, btcPubKey, feeRate, deadline}}
+}
+
+func StakeETH(amount *big.Int) AdminCall {
+	return AdminCall{Method: "stakeETH", Value: amount}
+}
+
+func StakeNFT(tokenIds []*big.Int) AdminCall {
+	return AdminCall{Method: "stakeNFT", Args: []interface{}{tokenIds}}
+}
+
+func Unstake() AdminCall {
+	return AdminCall{Method: "unstake"}
+}
+
+func SetArbitratorFeeRate(feeRate *big.Int) AdminCall {
+	return AdminCall{Method: "setArbitratorFeeRate", Args: []interface{}{feeRate}}
+}
+
+func SetArbitratorDeadline(deadline *big.Int) AdminCall {
+	return AdminCall{Method: "setArbitratorDeadline", Args: []interface{}{deadline}}
+}
+
+func Pause() AdminCall {
+	return AdminCall{Method: "pause"}
+}
+
+func Unpause() AdminCall {
+	return AdminCall{Method: "unpause"}
+}
+
+func SetRevenueAddresses(ethAddress common.Address, btcPubKey []byte, btcAddress string) AdminCall {
+	return AdminCall{Method: "setRevenueAddresses", Args: []interface{}{ethAddress, btcPubKey, btcAddress}}
+}
+
+// Prepare packs the call and builds the transaction with gas price, gas
+// limit and nonce filled in. Gas estimation fails if the call would revert.
+func (a *ArbitratorAdmin) Prepare(ctx context.Context, call AdminCall) (*AdminTransaction, error) {
+	input, err := a.abi.Pack(call.Method, call.Args...)
+	if err != nil {
+		return nil, err
+	}
+	if call.Value == nil {
+		call.Value = big.NewInt(0)
+	}
+	tx, err := a.submitter.MakeContractTransaction(ctx, input, &a.manager, call.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &AdminTransaction{
+		Call: call,
+		From: a.Address(),
+		To:   a.manager,
+		Tx:   tx,
+	}, nil
+}
+
+// Simulate executes the prepared transaction with eth_call against the
+// latest block without broadcasting it.
+func (a *ArbitratorAdmin) Simulate(ctx context.Context, t *AdminTransaction) error {
+	msg := ethereum.CallMsg{
+		From:     t.From,
+		To:       &t.To,
+		Gas:      t.Tx.Gas(),
+		GasPrice: t.Tx.GasPrice(),
+		Value:    t.Tx.Value(),
+		Data:     t.Tx.Data(),
+	}
+	_, err := a.submitter.CallContract(ctx, msg, nil)
+	return err
+}
+
+func (a *ArbitratorAdmin) Send(ctx context.Context, t *AdminTransaction) (common.Hash, error) {
+	return a.submitter.SignAndSendTransaction(ctx, t.Tx)
+}
+
+func (a *ArbitratorAdmin) WaitForReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return a.submitter.WaitForReceipt(ctx, hash)
+}
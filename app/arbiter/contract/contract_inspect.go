@@ -0,0 +1,135 @@
+// Copyright (c) 2025 The bel2 developers
+
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+// Inspector reads the deployment state of the arbiter contracts. Unlike
+// ArbitratorContract it needs no key and starts nothing, for preflight
+// checks.
+type Inspector struct {
+	client     *CrossClient
+	loanABI    abi.ABI
+	managerABI abi.ABI
+	Loan       common.Address
+	Manager    common.Address
+}
+
+func NewInspector(ctx context.Context, http string, loanAddress string, managerAddress string) (*Inspector, error) {
+	client, err := ConnectRPC(ctx, http)
+	if err != nil {
+		return nil, err
+	}
+	loanABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterABI))
+	if err != nil {
+		return nil, err
+	}
+	managerABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Inspector{
+		client:     client,
+		loanABI:    loanABI,
+		managerABI: managerABI,
+		Loan:       common.HexToAddress(loanAddress),
+		Manager:    common.HexToAddress(managerAddress),
+	}, nil
+}
+
+func (i *Inspector) ChainID(ctx context.Context) (*big.Int, error) {
+	return i.client.ChainID(ctx)
+}
+
+func (i *Inspector) LatestHeight(ctx context.Context) (uint64, error) {
+	return i.client.GetLatestHeight(ctx)
+}
+
+func (i *Inspector) Balance(ctx context.Context, account common.Address) (*big.Int, error) {
+	return i.client.BalanceAt(ctx, account)
+}
+
+// HasCode reports whether a contract is deployed at account.
+func (i *Inspector) HasCode(ctx context.Context, account common.Address) (bool, error) {
+	code, err := i.client.CodeAt(ctx, account)
+	if err != nil {
+		return false, err
+	}
+	return len(code) > 0, nil
+}
+
+// LoanManager returns the arbitrator manager the arbiter contract uses.
+func (i *Inspector) LoanManager(ctx context.Context) (common.Address, error) {
+	return i.callAddress(ctx, i.loanABI, i.Loan, "arbitratorManager")
+}
+
+// ManagerTransactionManager returns the transaction manager the arbitrator
+// manager uses, which is the arbiter contract.
+func (i *Inspector) ManagerTransactionManager(ctx context.Context) (common.Address, error) {
+	return i.callAddress(ctx, i.managerABI, i.Manager, "transactionManager")
+}
+
+// TransactionById returns getTransactionById of the arbiter contract.
+func (i *Inspector) TransactionById(ctx context.Context, id common.Hash) (*TransactionInfo, error) {
+	result, err := i.call(ctx, i.loanABI, i.Loan, "getTransactionById", id)
+	if err != nil {
+		return nil, err
+	}
+	return unpackTransactionInfo(i.loanABI, result)
+}
+
+// FindArbitrationRequest returns the latest ArbitrationRequested event of
+// transaction id between from and to, or nil if there is none.
+func (i *Inspector) FindArbitrationRequest(ctx context.Context, id common.Hash, from, to uint64) (*events.ContractLogEvent, error) {
+	return findArbitrationRequest(ctx, i.client, i.Loan, id, from, to)
+}
+
+// ArbitratorInfo returns getArbitratorInfo of arbitrator.
+func (i *Inspector) ArbitratorInfo(ctx context.Context, arbitrator common.Address) (*ArbitratorInfo, error) {
+	result, err := i.call(ctx, i.managerABI, i.Manager, "getArbitratorInfo", arbitrator)
+	if err != nil {
+		return nil, err
+	}
+	return unpackArbitratorInfo(i.managerABI, result)
+}
+
+func (i *Inspector) callAddress(ctx context.Context, contractABI abi.ABI, to common.Address, method string) (common.Address, error) {
+	result, err := i.call(ctx, contractABI, to, method)
+	if err != nil {
+		return common.Address{}, err
+	}
+	out, err := contractABI.Unpack(method, result)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(out) == 0 {
+		return common.Address{}, errors.New("empty result")
+	}
+	address, ok := out[0].(common.Address)
+	if !ok {
+		return common.Address{}, fmt.Errorf("unexpected result type %T", out[0])
+	}
+	return address, nil
+}
+
+func (i *Inspector) call(ctx context.Context, contractABI abi.ABI, to common.Address, method string, args ...interface{}) ([]byte, error) {
+	input, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	msg := ethereum.CallMsg{From: common.Address{}, To: &to, Data: input}
+	return i.client.CallContract(ctx, msg, nil)
+}
@@ -7,8 +7,10 @@ import (
 	"errors"
 	"math"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/metrics"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -21,6 +23,10 @@ type ContractListener struct {
 	listeneTopics []common.Hash
 	ctx           context.Context
 	chan_events   chan *events.ContractLogEvent
+
+	// last scanned block and the chain head at that time
+	height atomic.Uint64
+	head   atomic.Uint64
 }
 
 func NewListener(ctx context.Context, client *CrossClient,
@@ -36,12 +42,13 @@ func NewListener(ctx context.Context, client *CrossClient,
 }
 
 func (c *ContractListener) Start(startHeight uint64) (uint64, error) {
-	endBlock, err := c.queryClient.GetLatestHeight()
+	endBlock, err := c.queryClient.GetLatestHeight(c.ctx)
 	if err != nil {
 		g.Log().Warning(c.ctx, "GetLatestHeight failed", err)
 		return math.MaxUint64, err
 	}
 	if startHeight > endBlock-2 {
+		c.setHeight(startHeight-1, endBlock)
 		return math.MaxUint64, errors.New("start block must be less than end block")
 	}
 
@@ -63,9 +70,56 @@ func (c *ContractListener) Start(startHeight uint64) (uint64, error) {
 			return math.MaxUint64, err
 		}
 	}
+	c.setHeight(toBlock, endBlock)
 	return toBlock, nil
 }
 
+func (c *ContractListener) setHeight(height, head uint64) {
+	c.height.Store(height)
+	c.head.Store(head)
+	metrics.SetListener(height, head)
+}
+
+// FilterEvents returns the loan contract logs matching topics between from
+// and to inclusive, querying at most 10000 blocks at a time.
+func (c *ContractListener) FilterEvents(ctx context.Context, topics [][]common.Hash, from, to uint64) ([]*events.ContractLogEvent, error) {
+	return filterEvents(ctx, c.queryClient, c.loanContract, topics, from, to)
+}
+
+// filterEvents returns the logs of contract matching topics between from
+// and to, queried in ranges the RPC nodes accept.
+func filterEvents(ctx context.Context, client *CrossClient, contract common.Address, topics [][]common.Hash, from, to uint64) ([]*events.ContractLogEvent, error) {
+	distance := uint64(10000)
+	var result []*events.ContractLogEvent
+	for i := from; i <= to; i += distance + 1 {
+		end := i + distance
+		if end > to {
+			end = to
+		}
+		query := ethereum.FilterQuery{
+			FromBlock: big.NewInt(0).SetUint64(i),
+			ToBlock:   big.NewInt(0).SetUint64(end),
+			Addresses: []common.Address{contract},
+			Topics:    topics,
+		}
+		logs, err := client.FilterLogs(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			result = append(result, &events.ContractLogEvent{
+				EventData: l.Data,
+				TxHash:    l.TxHash,
+				Topics:    l.Topics,
+				Block:     l.BlockNumber,
+				TxIndex:   l.TxIndex,
+				LogIndex:  l.Index,
+			})
+		}
+	}
+	return result, nil
+}
+
 func (c *ContractListener) filterLoanEvent(query ethereum.FilterQuery) error {
 	logs, err := c.queryClient.FilterLogs(c.ctx, query)
 	if err != nil {
@@ -78,8 +132,13 @@ func (c *ContractListener) filterLoanEvent(query ethereum.FilterQuery) error {
 			Topics:    l.Topics,
 			Block:     l.BlockNumber,
 			TxIndex:   l.TxIndex,
+			LogIndex:  l.Index,
+		}
+		select {
+		case c.chan_events <- evt:
+		case <-c.ctx.Done():
+			return c.ctx.Err()
 		}
-		c.chan_events <- evt
 	}
 	return nil
 }
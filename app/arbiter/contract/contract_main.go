@@ -5,9 +5,10 @@ package contract
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
+	"runtime/debug"
 	"strings"
 	"time"
 
@@ -15,27 +16,39 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/glog"
 
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/history"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/metrics"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
 )
 
 type ArbitratorContract struct {
+	// RPC connection shared by the listener and the submitter
+	client    *CrossClient
 	listener  *ContractListener
 	submitter *ContractSubmitter
 	ctx       context.Context
 
-	chan_event          chan *events.ContractLogEvent
-	chan_interrupt      chan struct{}
+	chan_event     chan *events.ContractLogEvent
+	chan_interrupt chan struct{}
+	// next start height requested by Rescan
+	rescan              chan uint64
 	Loan_abi            abi.ABI
 	Arbiter_manager_abi abi.ABI
 
 	loanContract           *common.Address
 	arbiterManagerContract *common.Address
 	cfg                    *config.Config
+	queue                  *queue.Queue
+	history                history.Recorder
 
-	logger *log.Logger
+	// event log
+	logger *glog.Logger
 }
 
 // ArbitratorStatus should be defined according to the possible statuses you have.
@@ -60,11 +73,20 @@ type ArbitratorInfo struct {
 	LastSubmittedWorkTime time.Time        // Last submitted work time
 }
 
-func New(ctx context.Context, cfg *config.Config, privateKey string, logger *log.Logger) (*ArbitratorContract, error) {
-	client, err := ConnectRPC(cfg.Http)
+func New(ctx context.Context, cfg *config.Config, privateKey string, requestQueue *queue.Queue, recorder history.Recorder, logger *glog.Logger) (*ArbitratorContract, error) {
+	client, err := ConnectRPC(ctx, cfg.Http)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.ESCChainID != 0 {
+		chainId, err := client.ChainID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("query ESC chain id: %w", err)
+		}
+		if chainId.Int64() != cfg.ESCChainID {
+			return nil, fmt.Errorf("ESC RPC %s serves chain %s, network %s expects %d", cfg.Http, chainId, cfg.Network, cfg.ESCChainID)
+		}
+	}
 	loanAddress := common.HexToAddress(cfg.ESCArbiterContractAddress)
 	arbiterManagerAddress := common.HexToAddress(cfg.ESCArbiterManagerContractAddress)
 	eventChan := make(chan *events.ContractLogEvent, 3)
@@ -87,52 +109,60 @@ func New(ctx context.Context, cfg *config.Config, privateKey string, logger *log
 	if err != nil {
 		return nil, err
 	}
+	submitter.dryRun = cfg.DryRun
 	c := &ArbitratorContract{
+		client:                 client,
 		listener:               listener,
 		submitter:              submitter,
 		ctx:                    ctx,
 		chan_event:             eventChan,
 		chan_interrupt:         chan_interrupt,
+		rescan:                 make(chan uint64, 1),
 		Loan_abi:               loanABI,
 		Arbiter_manager_abi:    arbiterManagerABI,
 		loanContract:           &loanAddress,
 		arbiterManagerContract: &arbiterManagerAddress,
 		cfg:                    cfg,
+		queue:                  requestQueue,
+		history:                recorder,
 		logger:                 logger,
 	}
 	return c, nil
 }
 
-func (c *ArbitratorContract) Start(startHeight uint64) error {
+// Redial switches the listener and the submitter to the RPC endpoint http,
+// which must serve the configured ESC chain.
+func (c *ArbitratorContract) Redial(ctx context.Context, http string) error {
+	return c.client.Redial(ctx, http, c.cfg.ESCChainID)
+}
 
-	// get arbitrator operator address
-	arbiterAddress := common.HexToAddress(c.cfg.ESCArbiterAddress)
-	arbitratorOperatorAddress, err := c.getArbiterOperatorAddress(arbiterAddress)
-	if err != nil {
-		g.Log().Error(c.ctx, "GetArbiterOperatorAddress error", err)
-		panic("invalid arbiter address, err:" + err.Error())
-	}
-	g.Log().Info(c.ctx, "arbitratorOperatorAddress", arbitratorOperatorAddress)
-	// check operator address
-	if c.submitter.keypair.Address() != arbitratorOperatorAddress.String() {
-		g.Log().Error(c.ctx, "Invalid operator address from arbiter address")
-		panic("invalid operator address, " +
-			"operator from key file:" + c.submitter.keypair.Address() +
-			"operator from config:" + arbitratorOperatorAddress.String())
+// Start listens for contract events from startHeight until the context is
+// cancelled. It fails if the key file is not the operator of the configured
+// arbitrator.
+func (c *ArbitratorContract) Start(startHeight uint64) error {
+	if err := c.VerifyOperator(c.ctx); err != nil {
+		return err
 	}
 
+	// the handler lives as long as this call, a supervisor restart after a
+	// panic starts a new one
+	handlerCtx, stopHandler := context.WithCancel(c.ctx)
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for {
 			select {
 			case evt := <-c.chan_event:
-				err := c.parseContractEvent(evt)
-				if err != nil {
-					g.Log().Error(c.ctx, "parseContractEvent failed ", err)
-				}
-				// g.Log().Info(c.ctx, "parseContractEvent success:", evt)
+				c.handleEvent(evt)
+			case <-handlerCtx.Done():
+				return
 			}
 		}
 	}()
+	defer func() {
+		stopHandler()
+		<-done
+	}()
 
 	for {
 		endBlock, err := c.listener.Start(startHeight)
@@ -143,19 +173,104 @@ func (c *ArbitratorContract) Start(startHeight uint64) error {
 			}
 			startHeight = endBlock + 1
 		}
-		time.Sleep(5 * time.Second)
+		select {
+		case <-c.ctx.Done():
+			<-done
+			// handle the events received before the listener stopped
+			for {
+				select {
+				case evt := <-c.chan_event:
+					c.handleEvent(evt)
+				default:
+					return nil
+				}
+			}
+		case height := <-c.rescan:
+			g.Log().Notice(c.ctx, "rescan requested from block", height, "was at", startHeight)
+			c.logger.Warning(c.ctx, "LISTENER: rescan from block", height)
+			startHeight = height
+		case <-time.After(5 * time.Second):
+		}
 	}
 
 }
 
+// Rescan makes the listener scan again from height after its current pass.
+// Known requests are not enqueued twice. A newer call replaces a rescan that
+// did not start yet.
+func (c *ArbitratorContract) Rescan(height uint64) {
+	for {
+		select {
+		case c.rescan <- height:
+			return
+		default:
+		}
+		select {
+		case <-c.rescan:
+		default:
+		}
+	}
+}
+
+// VerifyOperator checks that the key file is the operator registered for
+// the configured arbitrator in getArbitratorInfo.
+func (c *ArbitratorContract) VerifyOperator(ctx context.Context) error {
+	// get arbitrator operator address
+	arbiterAddress := common.HexToAddress(c.cfg.ESCArbiterAddress)
+	arbitratorOperatorAddress, err := c.getArbiterOperatorAddress(ctx, arbiterAddress)
+	if err != nil {
+		g.Log().Error(c.ctx, "GetArbiterOperatorAddress error", err)
+		return fmt.Errorf("invalid arbiter address, err: %w", err)
+	}
+	g.Log().Debug(c.ctx, "arbitratorOperatorAddress", arbitratorOperatorAddress)
+	// check operator address
+	if c.submitter.keypair.Address() != arbitratorOperatorAddress.String() {
+		g.Log().Error(c.ctx, "Invalid operator address from arbiter address")
+		return errors.New("invalid operator address, " +
+			"operator from key file:" + c.submitter.keypair.Address() +
+			"operator from config:" + arbitratorOperatorAddress.String())
+	}
+	return nil
+}
+
+// ListenerHeight returns the last block scanned by the listener and the
+// chain head seen at that time, zero before the first scan.
+func (c *ArbitratorContract) ListenerHeight() (height, head uint64) {
+	return c.listener.height.Load(), c.listener.head.Load()
+}
+
+// handleEvent parses one event. A panic is logged instead of taking down
+// the listener, the reconciler picks up a lost arbitration request.
+func (c *ArbitratorContract) handleEvent(evt *events.ContractLogEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.Log().Critical(c.ctx, "parseContractEvent panic", r, "tx:", evt.TxHash.String(), "\n", string(debug.Stack()))
+		}
+	}()
+	err := c.parseContractEvent(evt)
+	if err != nil {
+		g.Log().Error(c.ctx, "parseContractEvent failed ", err)
+	}
+}
+
 func (c *ArbitratorContract) parseContractEvent(event *events.ContractLogEvent) error {
+	if err := c.history.RecordEvent(c.ctx, event); err != nil {
+		g.Log().Error(c.ctx, "RecordEvent error", err)
+	}
+	if len(event.Topics) == 0 {
+		return nil
+	}
+	metrics.EventsSeen.WithLabelValues(events.Name(event.Topics[0])).Inc()
+	ctx := logging.WithEvent(c.ctx, event)
 	var err error
 	if event.Topics[0].Cmp(events.ArbitrationRequested) == 0 {
-		err = c.parseTransferNeedSignEvent(event)
-		fmt.Println("ArbitrationRequested  >>>>>>>>>>>>>>>> received")
+		g.Log().Debug(ctx, "ArbitrationRequested received")
+		err = c.parseTransferNeedSignEvent(ctx, event)
 	} else if event.Topics[0].Cmp(events.ArbitrationResultSubmitted) == 0 {
-		err = c.parseTransferSignedEvent(event)
-		fmt.Println("ArbitrationResultSubmitted  >>>>>>>>>>>>>>>> received")
+		g.Log().Debug(ctx, "ArbitrationResultSubmitted received")
+		err = c.parseTransferSignedEvent(ctx, event)
+	} else if event.Topics[0].Cmp(events.TransactionCompleted) == 0 {
+		err = c.parseTransactionCompletedEvent(ctx, event)
 	}
 	return err
 }
@@ -164,75 +279,130 @@ func (c *ArbitratorContract) GetSubmiterAddress() string {
 	return c.submitter.keypair.Address()
 }
 
-func (c *ArbitratorContract) parseTransferNeedSignEvent(event *events.ContractLogEvent) error {
+func (c *ArbitratorContract) parseTransferNeedSignEvent(ctx context.Context, event *events.ContractLogEvent) error {
 	var ev = make(map[string]interface{})
 	err := c.Loan_abi.UnpackIntoMap(ev, "ArbitrationRequested", event.EventData)
 	if err != nil {
-		g.Log().Error(c.ctx, "parseTransferNeedSignEvent UnpackIntoMap error", err)
+		g.Log().Error(ctx, "parseTransferNeedSignEvent UnpackIntoMap error", err)
 		return err
 	}
 	if ev["arbitrator"].(common.Address).String() != c.cfg.ESCArbiterAddress {
-		g.Log().Debug(c.ctx, "find ArbitrationRequested event, but not mine")
+		g.Log().Debug(ctx, "find ArbitrationRequested event, but not mine")
 		return nil
 	}
-	c.logger.Println("[INF] EVENT: ArbitrationRequested, block:", event.Block, "tx:", event.TxHash)
+	c.logger.Info(ctx, "EVENT: ArbitrationRequested")
 
-	path := c.cfg.LoanNeedSignReqPath + "/" + event.TxHash.String()
-	err = events.SaveContractEvent(path, event)
+	created, err := c.queue.Enqueue(event)
 	if err != nil {
-		g.Log().Error(c.ctx, "SaveContractEvent error", err)
+		g.Log().Error(ctx, "Enqueue error", err)
+		return err
 	}
-	g.Log().Noticef(c.ctx, "find btc tx need sign:%s ", event.TxHash.String())
-	return err
+	if !created {
+		g.Log().Info(ctx, "ArbitrationRequested already queued:", queue.KeyOf(event))
+		return nil
+	}
+	g.Log().Noticef(ctx, "find btc tx need sign:%s ", event.TxHash.String())
+	return nil
 }
 
-func (c *ArbitratorContract) parseTransferSignedEvent(event *events.ContractLogEvent) error {
+func (c *ArbitratorContract) parseTransferSignedEvent(ctx context.Context, event *events.ContractLogEvent) error {
 	var ev = make(map[string]interface{})
 	err := c.Loan_abi.UnpackIntoMap(ev, "ArbitrationResultSubmitted", event.EventData)
 	if err != nil {
-		g.Log().Error(c.ctx, "parseTransferSignedEvent UnpackIntoMap error", err)
+		g.Log().Error(ctx, "parseTransferSignedEvent UnpackIntoMap error", err)
 		return err
 	}
 	path := c.cfg.LoanSignedEventPath + "/" + event.TxHash.String()
 	err = events.SaveContractEvent(path, event)
 	if err != nil {
-		g.Log().Error(c.ctx, "SaveContractEvent error", err)
+		g.Log().Error(ctx, "SaveContractEvent error", err)
+	}
+	g.Log().Noticef(ctx, "find btc tx signed:%s ", event.TxHash.String())
+	return err
+}
+
+func (c *ArbitratorContract) parseTransactionCompletedEvent(ctx context.Context, event *events.ContractLogEvent) error {
+	if len(event.Topics) < 2 {
+		return errors.New("invalid TransactionCompleted topics")
+	}
+	txId := event.Topics[1]
+	info, err := c.GetTransactionById(ctx, txId)
+	if err != nil {
+		g.Log().Error(ctx, "parseTransactionCompletedEvent GetTransactionById error", err)
+		return err
+	}
+	if !strings.EqualFold(info.Arbitrator.String(), c.cfg.ESCArbiterAddress) {
+		g.Log().Debug(ctx, "find TransactionCompleted event, but not mine")
+		return nil
+	}
+	c.logger.Info(ctx, "EVENT: TransactionCompleted")
+
+	path := c.cfg.LoanCompletedEventPath + "/" + txId.String()
+	err = events.SaveContractEvent(path, event)
+	if err != nil {
+		g.Log().Error(ctx, "SaveContractEvent error", err)
 	}
-	g.Log().Noticef(c.ctx, "find btc tx signed:%s ", event.TxHash.String())
+	g.Log().Noticef(ctx, "find completed transaction:%s ", txId.String())
 	return err
 }
 
-func (c *ArbitratorContract) SubmitArbitrationSignature(rawData []byte, queryId [32]byte) (common.Hash, error) {
+func (c *ArbitratorContract) SubmitArbitrationSignature(ctx context.Context, rawData []byte, queryId [32]byte) (common.Hash, error) {
 	input, err := c.Loan_abi.Pack("submitArbitration", queryId, rawData)
 	if err != nil {
 		return common.Hash{}, err
 	}
-	hash, err := c.submitter.MakeAndSendContractTransaction(input, c.loanContract, big.NewInt(0))
+	hash, err := c.submitter.MakeAndSendContractTransaction(ctx, input, c.loanContract, big.NewInt(0))
 	return hash, err
 }
 
-func (c *ArbitratorContract) getArbiterOperatorAddress(arbiter common.Address) (common.Address, error) {
+// SimulateArbitrationSignature executes submitArbitration with eth_call
+// from the operator account without sending it. It returns the gas the
+// submission would use, or why the contract would revert it.
+func (c *ArbitratorContract) SimulateArbitrationSignature(ctx context.Context, rawData []byte, queryId [32]byte) (uint64, error) {
+	input, err := c.Loan_abi.Pack("submitArbitration", queryId, rawData)
+	if err != nil {
+		return 0, err
+	}
+	msg := ethereum.CallMsg{From: c.submitter.Address(), To: c.loanContract, Data: input}
+	if _, err := c.submitter.CallContract(ctx, msg, nil); err != nil {
+		return 0, err
+	}
+	return c.submitter.EstimateGas(ctx, msg)
+}
+
+func (c *ArbitratorContract) getArbiterOperatorAddress(ctx context.Context, arbiter common.Address) (common.Address, error) {
 	input, err := c.Arbiter_manager_abi.Pack("getArbitratorInfo", arbiter)
 	if err != nil {
 		return common.Address{}, err
 	}
 	// use c.arbiterManagerContract to call get getArbitratorInfo operator address
 	msg := ethereum.CallMsg{From: common.Address{}, To: c.arbiterManagerContract, Data: input}
-	result, err := c.submitter.CallContract(context.TODO(), msg, nil)
+	result, err := c.submitter.CallContract(ctx, msg, nil)
 	if err != nil {
 		return common.Address{}, err
 	}
-	ev, err := c.Arbiter_manager_abi.Unpack("getArbitratorInfo", result)
-	if err != nil || len(ev) == 0 {
+	info, err := unpackArbitratorInfo(c.Arbiter_manager_abi, result)
+	if err != nil {
 		g.Log().Error(c.ctx, "parse ArbitratorInfo UnpackIntoMap error", err)
 		return common.Address{}, err
 	}
+	return common.HexToAddress(info.Operator), nil
+}
+
+func unpackArbitratorInfo(managerABI abi.ABI, result []byte) (*ArbitratorInfo, error) {
+	ev, err := managerABI.Unpack("getArbitratorInfo", result)
+	if err != nil {
+		return nil, err
+	}
+	if len(ev) == 0 {
+		return nil, errors.New("empty getArbitratorInfo result")
+	}
 	info := ArbitratorInfo{}
 	data, err := json.Marshal(ev[0])
 	if err != nil {
-		return common.Address{}, err
+		return nil, err
 	}
+	// the uint256 deadlines do not decode into time.Time, the other fields do
 	json.Unmarshal(data, &info)
-
-	return common.HexToAddress(info.Operator), nil
+	return &info, nil
 }
@@ -5,9 +5,10 @@ package contract
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
+	"time"
 
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/crypto"
@@ -16,12 +17,26 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+var (
+	// ErrDryRun is returned instead of sending a transaction in a dry run.
+	ErrDryRun = errors.New("dry run, transaction not sent")
+	// ErrGasBudget is returned for a transaction that may cost more gas than
+	// allowed.
+	ErrGasBudget = errors.New("gas cost exceeds budget")
 )
 
 type ContractSubmitter struct {
 	client  *CrossClient
 	ctx     context.Context
 	keypair crypto.Keypair
+	// signs and simulates, but never sends a transaction
+	dryRun bool
+	// held while a transaction takes its nonce and is sent, so concurrent
+	// submissions do not reuse the pending nonce
+	sendLock chan struct{}
 }
 
 func NewSubmitter(ctx context.Context, client *CrossClient, privateKey string) (*ContractSubmitter, error) {
@@ -34,42 +49,90 @@ func NewSubmitter(ctx context.Context, client *CrossClient, privateKey string) (
 		return nil, err
 	}
 	submitter := &ContractSubmitter{
-		client:  client,
-		ctx:     ctx,
-		keypair: kp,
+		client:   client,
+		ctx:      ctx,
+		keypair:  kp,
+		sendLock: make(chan struct{}, 1),
 	}
 	return submitter, nil
 }
 
-func (s *ContractSubmitter) MakeAndSendContractTransaction(data []byte, to *common.Address, value *big.Int) (common.Hash, error) {
-	var hash common.Hash
+func (s *ContractSubmitter) MakeAndSendContractTransaction(ctx context.Context, data []byte, to *common.Address, value *big.Int) (common.Hash, error) {
+	select {
+	case s.sendLock <- struct{}{}:
+	case <-ctx.Done():
+		return common.Hash{}, ctx.Err()
+	}
+	defer func() { <-s.sendLock }()
+
+	tx, err := s.MakeContractTransaction(ctx, data, to, value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return s.SignAndSendTransaction(ctx, tx)
+}
+
+// MakeAndSendWithinBudget builds and sends a transaction like
+// MakeAndSendContractTransaction, unless its gas limit at the suggested gas
+// price costs more than budget wei, nil for no limit. The transaction is
+// returned for its gas price, also with ErrGasBudget and ErrDryRun.
+func (s *ContractSubmitter) MakeAndSendWithinBudget(ctx context.Context, data []byte, to *common.Address, value *big.Int, budget *big.Int) (*types.Transaction, common.Hash, error) {
+	select {
+	case s.sendLock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, common.Hash{}, ctx.Err()
+	}
+	defer func() { <-s.sendLock }()
+
+	tx, err := s.MakeContractTransaction(ctx, data, to, value)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	if budget != nil && MaxGasCost(tx).Cmp(budget) > 0 {
+		return tx, common.Hash{}, fmt.Errorf("%w: %s > %s wei", ErrGasBudget, MaxGasCost(tx), budget)
+	}
+	hash, err := s.SignAndSendTransaction(ctx, tx)
+	return tx, hash, err
+}
+
+// MaxGasCost is the most tx can cost in wei, its gas limit at its gas price.
+func MaxGasCost(tx *types.Transaction) *big.Int {
+	return new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+}
+
+// MakeContractTransaction builds an unsigned legacy transaction with the
+// suggested gas price, estimated gas limit and pending nonce of the submitter.
+func (s *ContractSubmitter) MakeContractTransaction(ctx context.Context, data []byte, to *common.Address, value *big.Int) (*types.Transaction, error) {
 	var from = s.keypair.CommonAddress()
-	ctx := context.Background()
 	gasPrice, err := s.client.SuggestGasPrice(ctx)
 	if err != nil {
-		log.Printf("SuggestGasPrice err: %v", err)
-		return hash, err
+		g.Log().Error(ctx, "SuggestGasPrice error", err)
+		return nil, err
 	}
 	msg := ethereum.CallMsg{From: from, To: to, Data: data, GasPrice: gasPrice, Value: value}
 	gasLimit, err := s.client.EstimateGas(ctx, msg)
 	if err != nil || gasLimit == 0 {
-		log.Printf("EstimateGas err: %v", err)
-		return hash, err
+		g.Log().Error(ctx, "EstimateGas error", err)
+		if err == nil {
+			err = errors.New("estimated gas limit is zero")
+		}
+		return nil, err
 	}
 	gasLimit = gasLimit + gasLimit*10
 	nonce, err := s.client.PendingNonceAt(ctx, from)
 	if err != nil {
-		log.Printf("PendingNonceAt err: %v", err)
-		return hash, err
+		g.Log().Error(ctx, "PendingNonceAt error", err)
+		return nil, err
 	}
 
-	tx := contract_abi.NewTransaction(nonce, to, value, gasLimit, gasPrice, data)
-
-	return s.SignAndSendTransaction(ctx, tx)
+	return contract_abi.NewTransaction(nonce, to, value, gasLimit, gasPrice, data), nil
 }
 
 func (s *ContractSubmitter) SignAndSendTransaction(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
-
+	if s.dryRun {
+		return common.Hash{}, ErrDryRun
+	}
 	id, err := s.client.ChainID(ctx)
 	if err != nil {
 		return common.Hash{}, err
@@ -79,11 +142,47 @@ func (s *ContractSubmitter) SignAndSendTransaction(ctx context.Context, tx *type
 		return common.Hash{}, err
 	}
 
-	fmt.Println("SignAndSendTransaction rawTX:", hex.EncodeToString(rawTX))
+	g.Log().Debug(ctx, "SignAndSendTransaction rawTX:", hex.EncodeToString(rawTX))
 	hash, err := s.client.SendRawTransaction(ctx, rawTX)
 	return hash, err
 }
 
+// WaitForReceipt polls the chain until the receipt of hash is available or
+// ctx is done.
+func (s *ContractSubmitter) WaitForReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		receipt, err := s.client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ContractSubmitter) Address() common.Address {
+	return s.keypair.CommonAddress()
+}
+
+// TransactionReceipt returns the receipt of hash, ethereum.NotFound while
+// it is not mined.
+func (s *ContractSubmitter) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return s.client.TransactionReceipt(ctx, hash)
+}
+
+// EstimateGas returns the gas msg would use if sent.
+func (s *ContractSubmitter) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return s.client.EstimateGas(ctx, msg)
+}
+
 func (s *ContractSubmitter) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
 	return s.client.CallContract(ctx, msg, blockNumber)
 }
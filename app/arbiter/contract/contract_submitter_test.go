@@ -0,0 +1,153 @@
+// Copyright (c) 2025 The bel2 developers
+
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+// fakeChain serves the JSON-RPC calls of the submitter. Its pending nonce
+// is the number of transactions sent, and it rejects a reused nonce like a
+// node does.
+type fakeChain struct {
+	mu    sync.Mutex
+	sent  []*types.Transaction
+	nonce map[uint64]bool
+	// 20 if not set
+	chainId uint64
+}
+
+func (c *fakeChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "eth_chainId":
+		response["result"] = hexutil.Uint64(20)
+		if c.chainId != 0 {
+			response["result"] = hexutil.Uint64(c.chainId)
+		}
+	case "eth_gasPrice":
+		response["result"] = "0x1"
+	case "eth_estimateGas":
+		response["result"] = "0x64"
+	case "eth_getTransactionCount":
+		response["result"] = hexutil.Uint64(len(c.sent))
+	case "eth_sendRawTransaction":
+		var raw hexutil.Bytes
+		json.Unmarshal(req.Params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			response["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+			break
+		}
+		if c.nonce[tx.Nonce()] {
+			response["error"] = map[string]interface{}{"code": -32000, "message": "nonce too low"}
+			break
+		}
+		c.nonce[tx.Nonce()] = true
+		c.sent = append(c.sent, tx)
+		response["result"] = tx.Hash()
+	default:
+		response["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func newTestSubmitter(t *testing.T) (*ContractSubmitter, *fakeChain) {
+	chain := &fakeChain{nonce: make(map[uint64]bool)}
+	srv := httptest.NewServer(chain)
+	t.Cleanup(srv.Close)
+	client, err := ConnectRPC(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSubmitter(context.Background(), client, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, chain
+}
+
+func TestMakeAndSendWithinBudget(t *testing.T) {
+	s, chain := newTestSubmitter(t)
+	ctx := context.Background()
+	to := common.HexToAddress("0x01")
+
+	// gas limit 100 plus ten times the estimate at gas price 1
+	tx, _, err := s.MakeAndSendWithinBudget(ctx, nil, &to, big.NewInt(0), big.NewInt(1000))
+	if !errors.Is(err, ErrGasBudget) || tx == nil || MaxGasCost(tx).Int64() != 1100 {
+		t.Fatalf("over budget: %v %v", tx, err)
+	}
+	if len(chain.sent) != 0 {
+		t.Fatal("sent over budget")
+	}
+
+	tx, hash, err := s.MakeAndSendWithinBudget(ctx, nil, &to, big.NewInt(0), big.NewInt(1100))
+	if err != nil || hash != chain.sent[0].Hash() || tx.Nonce() != 0 {
+		t.Fatalf("within budget: %v %v", hash, err)
+	}
+
+	s.dryRun = true
+	if tx, _, err := s.MakeAndSendWithinBudget(ctx, nil, &to, big.NewInt(0), nil); !errors.Is(err, ErrDryRun) || tx == nil {
+		t.Errorf("dry run: %v %v", tx, err)
+	}
+	if len(chain.sent) != 1 {
+		t.Error("sent in a dry run")
+	}
+}
+
+func TestConcurrentSendsTakeDistinctNonces(t *testing.T) {
+	s, chain := newTestSubmitter(t)
+	ctx := context.Background()
+	to := common.HexToAddress("0x01")
+
+	const n = 10
+	errs := make(chan error, 2*n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := s.MakeAndSendContractTransaction(ctx, nil, &to, big.NewInt(0))
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, err := s.MakeAndSendWithinBudget(ctx, nil, &to, big.NewInt(0), nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if len(chain.sent) != 2*n {
+		t.Errorf("%d of %d transactions sent", len(chain.sent), 2*n)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 The bel2 developers
+
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+// TransactionStatus mirrors DataTypes.TransactionStatus of the arbiter contract.
+type TransactionStatus uint8
+
+const (
+	TransactionActive TransactionStatus = iota
+	TransactionCompleted
+	TransactionArbitrated
+	TransactionExpired
+	TransactionDisputed
+	TransactionSubmitted
+)
+
+func (s TransactionStatus) String() string {
+	switch s {
+	case TransactionActive:
+		return "Active"
+	case TransactionCompleted:
+		return "Completed"
+	case TransactionArbitrated:
+		return "Arbitrated"
+	case TransactionExpired:
+		return "Expired"
+	case TransactionDisputed:
+		return "Disputed"
+	case TransactionSubmitted:
+		return "Submitted"
+	}
+	return "Unknown"
+}
+
+type UTXO struct {
+	TxHash [32]byte
+	Index  uint32
+	Script []byte
+	Amount *big.Int
+}
+
+// TransactionInfo is the DataTypes.Transaction returned by getTransactionById.
+type TransactionInfo struct {
+	Dapp                        common.Address
+	Arbitrator                  common.Address
+	StartTime                   *big.Int
+	Deadline                    *big.Int
+	BtcTx                       []byte
+	BtcTxHash                   [32]byte
+	Status                      uint8
+	DepositedFee                *big.Int
+	Signature                   []byte
+	CompensationReceiver        common.Address
+	TimeoutCompensationReceiver common.Address
+	Utxos                       []UTXO
+	Script                      []byte
+}
+
+func (t *TransactionInfo) TxStatus() TransactionStatus {
+	return TransactionStatus(t.Status)
+}
+
+func (c *ArbitratorContract) GetTransactionById(ctx context.Context, id [32]byte) (*TransactionInfo, error) {
+	result, err := c.callLoanContract(ctx, "getTransactionById", id)
+	if err != nil {
+		return nil, err
+	}
+	return unpackTransactionInfo(c.Loan_abi, result)
+}
+
+func unpackTransactionInfo(loanABI abi.ABI, result []byte) (*TransactionInfo, error) {
+	out, err := loanABI.Unpack("getTransactionById", result)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("empty getTransactionById result")
+	}
+	return abi.ConvertType(out[0], new(TransactionInfo)).(*TransactionInfo), nil
+}
+
+func (c *ArbitratorContract) IsAbleCompletedTransaction(ctx context.Context, id [32]byte) (bool, error) {
+	result, err := c.callLoanContract(ctx, "isAbleCompletedTransaction", id)
+	if err != nil {
+		return false, err
+	}
+	out, err := c.Loan_abi.Unpack("isAbleCompletedTransaction", result)
+	if err != nil {
+		return false, err
+	}
+	if len(out) == 0 {
+		return false, errors.New("empty isAbleCompletedTransaction result")
+	}
+	return out[0].(bool), nil
+}
+
+// SimulateTransferArbitrationFee executes transferArbitrationFee with
+// eth_call from the operator account and returns the fees it would pay out.
+// An error means the fee is not claimable (yet).
+func (c *ArbitratorContract) SimulateTransferArbitrationFee(ctx context.Context, id [32]byte) (arbitratorFee, systemFee *big.Int, err error) {
+	input, err := c.Loan_abi.Pack("transferArbitrationFee", id)
+	if err != nil {
+		return nil, nil, err
+	}
+	msg := ethereum.CallMsg{From: c.submitter.Address(), To: c.loanContract, Data: input}
+	result, err := c.submitter.CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := c.Loan_abi.Unpack("transferArbitrationFee", result)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(out) != 2 {
+		return nil, nil, errors.New("unexpected transferArbitrationFee result")
+	}
+	return out[0].(*big.Int), out[1].(*big.Int), nil
+}
+
+// TransferArbitrationFee claims the arbitration fee of transaction id,
+// unless the claim may cost more gas than budget wei, nil for no limit. The
+// nonce is taken under the submitter send lock, shared with the signature
+// submissions. The claim transaction is returned for its gas price, also
+// with ErrGasBudget and ErrDryRun.
+func (c *ArbitratorContract) TransferArbitrationFee(ctx context.Context, id [32]byte, budget *big.Int) (*types.Transaction, common.Hash, error) {
+	input, err := c.Loan_abi.Pack("transferArbitrationFee", id)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return c.submitter.MakeAndSendWithinBudget(ctx, input, c.loanContract, big.NewInt(0), budget)
+}
+
+// TransactionReceipt returns the receipt of an ESC transaction,
+// ethereum.NotFound while it is not mined.
+func (c *ArbitratorContract) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return c.submitter.TransactionReceipt(ctx, hash)
+}
+
+func (c *ArbitratorContract) WaitForReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return c.submitter.WaitForReceipt(ctx, hash)
+}
+
+func (c *ArbitratorContract) callLoanContract(ctx context.Context, method string, args ...interface{}) ([]byte, error) {
+	input, err := c.Loan_abi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	msg := ethereum.CallMsg{From: common.Address{}, To: c.loanContract, Data: input}
+	return c.submitter.CallContract(ctx, msg, nil)
+}
+
+// OperatorBalance returns the ESC balance of the operator account that pays
+// for submissions.
+func (c *ArbitratorContract) OperatorBalance(ctx context.Context) (*big.Int, error) {
+	return c.listener.queryClient.BalanceAt(ctx, c.submitter.Address())
+}
+
+// ArbitratorFlags are the flags of the configured arbitrator in the arbiter
+// manager.
+type ArbitratorFlags struct {
+	Active bool
+	Frozen bool
+	Paused bool
+}
+
+// GetArbitratorFlags queries isActiveArbitrator, isFrozenStatus and isPaused
+// of the configured arbitrator.
+func (c *ArbitratorContract) GetArbitratorFlags(ctx context.Context) (*ArbitratorFlags, error) {
+	arbiter := common.HexToAddress(c.cfg.ESCArbiterAddress)
+	var status ArbitratorFlags
+	for _, q := range []struct {
+		method string
+		value  *bool
+	}{
+		{"isActiveArbitrator", &status.Active},
+		{"isFrozenStatus", &status.Frozen},
+		{"isPaused", &status.Paused},
+	} {
+		value, err := c.callManagerBool(ctx, q.method, arbiter)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", q.method, err)
+		}
+		*q.value = value
+	}
+	return &status, nil
+}
+
+func (c *ArbitratorContract) callManagerBool(ctx context.Context, method string, args ...interface{}) (bool, error) {
+	input, err := c.Arbiter_manager_abi.Pack(method, args...)
+	if err != nil {
+		return false, err
+	}
+	msg := ethereum.CallMsg{From: common.Address{}, To: c.arbiterManagerContract, Data: input}
+	result, err := c.submitter.CallContract(ctx, msg, nil)
+	if err != nil {
+		return false, err
+	}
+	out, err := c.Arbiter_manager_abi.Unpack(method, result)
+	if err != nil {
+		return false, err
+	}
+	if len(out) == 0 {
+		return false, errors.New("empty result")
+	}
+	value, ok := out[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected result type %T", out[0])
+	}
+	return value, nil
+}
+
+func (c *ArbitratorContract) GetLatestHeight(ctx context.Context) (uint64, error) {
+	return c.listener.queryClient.GetLatestHeight(ctx)
+}
+
+// FindRegisteredTransactions returns the TransactionRegistered events of
+// arbitrator between from and to.
+func (c *ArbitratorContract) FindRegisteredTransactions(ctx context.Context, arbitrator common.Address, from, to uint64) ([]*events.ContractLogEvent, error) {
+	topics := [][]common.Hash{
+		{events.TransactionRegistered},
+		nil,
+		nil,
+		{common.BytesToHash(arbitrator.Bytes())},
+	}
+	return c.listener.FilterEvents(ctx, topics, from, to)
+}
+
+// FindArbitrationRequest returns the latest ArbitrationRequested event of
+// transaction id between from and to, or nil if there is none.
+func (c *ArbitratorContract) FindArbitrationRequest(ctx context.Context, id common.Hash, from, to uint64) (*events.ContractLogEvent, error) {
+	return findArbitrationRequest(ctx, c.listener.queryClient, *c.loanContract, id, from, to)
+}
+
+func findArbitrationRequest(ctx context.Context, client *CrossClient, loan common.Address, id common.Hash, from, to uint64) (*events.ContractLogEvent, error) {
+	topics := [][]common.Hash{
+		{events.ArbitrationRequested},
+		{id},
+	}
+	logs, err := filterEvents(ctx, client, loan, topics, from, to)
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+	return logs[len(logs)-1], nil
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -17,17 +18,47 @@ import (
 )
 
 type CrossClient struct {
-	client *rpc.Client
+	// replaced by Redial while calls are in flight
+	client atomic.Pointer[rpc.Client]
 }
 
-func ConnectRPC(http string) (*CrossClient, error) {
-	_cli, err := rpc.DialContext(context.TODO(), http)
+func ConnectRPC(ctx context.Context, http string) (*CrossClient, error) {
+	_cli, err := rpc.DialContext(ctx, http)
 	if err != nil {
 		return nil, err
 	}
-	return &CrossClient{
-		client: _cli,
-	}, nil
+	c := &CrossClient{}
+	c.client.Store(_cli)
+	return c, nil
+}
+
+// Redial switches to the RPC endpoint http, which must serve chain
+// chainId, or the chain of the current endpoint if chainId is 0. Calls in
+// flight on the old connection may fail.
+func (c *CrossClient) Redial(ctx context.Context, http string, chainId int64) error {
+	want := big.NewInt(chainId)
+	if chainId == 0 {
+		id, err := c.ChainID(ctx)
+		if err != nil {
+			return fmt.Errorf("query current chain id: %w", err)
+		}
+		want = id
+	}
+	next, err := rpc.DialContext(ctx, http)
+	if err != nil {
+		return err
+	}
+	var nextId hexutil.Big
+	if err := next.CallContext(ctx, &nextId, "eth_chainId"); err != nil {
+		next.Close()
+		return fmt.Errorf("query chain id: %w", err)
+	}
+	if want.Cmp((*big.Int)(&nextId)) != 0 {
+		next.Close()
+		return fmt.Errorf("endpoint serves chain %s, not %s", (*big.Int)(&nextId), want)
+	}
+	c.client.Swap(next).Close()
+	return nil
 }
 
 type headerNumber struct {
@@ -49,10 +80,10 @@ func (h *headerNumber) UnmarshalJSON(input []byte) error {
 	return nil
 }
 
-func (c *CrossClient) GetLatestHeight() (uint64, error) {
+func (c *CrossClient) GetLatestHeight(ctx context.Context) (uint64, error) {
 	var head *headerNumber
 
-	err := c.client.CallContext(context.Background(), &head, "eth_getBlockByNumber", "latest", false)
+	err := c.client.Load().CallContext(ctx, &head, "eth_getBlockByNumber", "latest", false)
 	if err == nil && head == nil {
 		return 0, errors.New("not found")
 	}
@@ -78,7 +109,7 @@ func (c *CrossClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([
 	if err != nil {
 		return nil, err
 	}
-	err = c.client.CallContext(ctx, &result, "eth_getLogs", arg)
+	err = c.client.Load().CallContext(ctx, &result, "eth_getLogs", arg)
 	return result, err
 }
 
@@ -112,7 +143,7 @@ func toBlockNumArg(number *big.Int) string {
 
 func (c *CrossClient) ChainID(ctx context.Context) (*big.Int, error) {
 	var result hexutil.Big
-	err := c.client.CallContext(ctx, &result, "eth_chainId")
+	err := c.client.Load().CallContext(ctx, &result, "eth_chainId")
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +152,7 @@ func (c *CrossClient) ChainID(ctx context.Context) (*big.Int, error) {
 
 func (c *CrossClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	var hex hexutil.Big
-	if err := c.client.CallContext(ctx, &hex, "eth_gasPrice"); err != nil {
+	if err := c.client.Load().CallContext(ctx, &hex, "eth_gasPrice"); err != nil {
 		return nil, err
 	}
 	return (*big.Int)(&hex), nil
@@ -129,7 +160,7 @@ func (c *CrossClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 
 func (c *CrossClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
 	var hex hexutil.Uint64
-	err := c.client.CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg))
+	err := c.client.Load().CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg))
 	if err != nil {
 		return 0, err
 	}
@@ -138,22 +169,34 @@ func (c *CrossClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (ui
 
 func (c *CrossClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
 	var result hexutil.Uint64
-	err := c.client.CallContext(ctx, &result, "eth_getTransactionCount", account, "pending")
+	err := c.client.Load().CallContext(ctx, &result, "eth_getTransactionCount", account, "pending")
 	return uint64(result), err
 }
 
+func (c *CrossClient) BalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	var result hexutil.Big
+	err := c.client.Load().CallContext(ctx, &result, "eth_getBalance", account, "latest")
+	return (*big.Int)(&result), err
+}
+
+func (c *CrossClient) CodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	var result hexutil.Bytes
+	err := c.client.Load().CallContext(ctx, &result, "eth_getCode", account, "latest")
+	return result, err
+}
+
 func (c *CrossClient) SendRawTransaction(ctx context.Context, tx []byte) (common.Hash, error) {
 	var hex common.Hash
-	err := c.client.CallContext(ctx, &hex, "eth_sendRawTransaction", hexutil.Encode(tx))
+	err := c.client.Load().CallContext(ctx, &hex, "eth_sendRawTransaction", hexutil.Encode(tx))
 	if err != nil {
 		return hex, err
 	}
 	return hex, nil
 }
 
-func (c *CrossClient) TransactionReceipt(txHash common.Hash) (*types.Receipt, error) {
+func (c *CrossClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
 	var r *types.Receipt
-	err := c.client.CallContext(context.Background(), &r, "eth_getTransactionReceipt", txHash)
+	err := c.client.Load().CallContext(ctx, &r, "eth_getTransactionReceipt", txHash)
 	if err == nil {
 		if r == nil {
 			return nil, ethereum.NotFound
@@ -164,7 +207,7 @@ func (c *CrossClient) TransactionReceipt(txHash common.Hash) (*types.Receipt, er
 
 func (c *CrossClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
 	var hex hexutil.Bytes
-	err := c.client.CallContext(ctx, &hex, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber))
+	err := c.client.Load().CallContext(ctx, &hex, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber))
 	if err != nil {
 		return nil, err
 	}
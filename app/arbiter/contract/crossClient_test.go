@@ -0,0 +1,49 @@
+// Copyright (c) 2025 The bel2 developers
+
+package contract
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedial(t *testing.T) {
+	ctx := context.Background()
+	esc := httptest.NewServer(&fakeChain{})
+	defer esc.Close()
+	other := httptest.NewServer(&fakeChain{chainId: 21})
+	defer other.Close()
+	backup := httptest.NewServer(&fakeChain{})
+	defer backup.Close()
+
+	client, err := ConnectRPC(ctx, esc.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Redial(ctx, other.URL, 20); err == nil {
+		t.Error("switched to another chain")
+	}
+	if err := client.Redial(ctx, other.URL, 0); err == nil {
+		t.Error("switched to another chain than the current one")
+	}
+	if err := client.Redial(ctx, backup.URL, 20); err != nil {
+		t.Fatal(err)
+	}
+
+	// the chain of a dead endpoint cannot be checked
+	dead, err := ConnectRPC(ctx, esc.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esc.Close()
+	if err := dead.Redial(ctx, other.URL, 0); err == nil {
+		t.Error("switched without knowing the chain")
+	}
+	if err := dead.Redial(ctx, other.URL, 20); err == nil {
+		t.Error("switched to another chain from a dead endpoint")
+	}
+	if err := dead.Redial(ctx, backup.URL, 20); err != nil {
+		t.Errorf("switch from a dead endpoint: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 The bel2 developers
+
+package events
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+)
+
+// ArchiveVersion is the version of the JSON event archive format.
+const ArchiveVersion = 1
+
+// archivedEvent is the JSON form of a stored ContractLogEvent. Raw holds
+// the log as received and is what the event is decoded from; Decoded is
+// only there for people reading the file.
+type archivedEvent struct {
+	Version  int                    `json:"version"`
+	Event    string                 `json:"event,omitempty"`
+	TxHash   common.Hash            `json:"txHash"`
+	Block    uint64                 `json:"block"`
+	TxIndex  uint                   `json:"txIndex"`
+	LogIndex uint                   `json:"logIndex"`
+	Decoded  map[string]interface{} `json:"decoded,omitempty"`
+	Raw      rawLog                 `json:"raw"`
+}
+
+type rawLog struct {
+	Topics []common.Hash `json:"topics"`
+	Data   hexutil.Bytes `json:"data"`
+}
+
+var (
+	loanABIOnce sync.Once
+	loanABI     abi.ABI
+	loanABIErr  error
+)
+
+func arbiterABI() (abi.ABI, error) {
+	loanABIOnce.Do(func() {
+		loanABI, loanABIErr = abi.JSON(strings.NewReader(contract_abi.ArbiterABI))
+	})
+	return loanABI, loanABIErr
+}
+
+// MarshalArchive encodes event in the JSON archive format.
+func MarshalArchive(event *ContractLogEvent) ([]byte, error) {
+	archived := archivedEvent{
+		Version:  ArchiveVersion,
+		TxHash:   event.TxHash,
+		Block:    event.Block,
+		TxIndex:  event.TxIndex,
+		LogIndex: event.LogIndex,
+		Raw: rawLog{
+			Topics: event.Topics,
+			Data:   event.EventData,
+		},
+	}
+	archived.Event, archived.Decoded = decodeEvent(event)
+	return json.MarshalIndent(&archived, "", "  ")
+}
+
+// decodeEvent decodes the indexed and data fields of a known arbiter
+// contract event. Unknown or undecodable events only keep the raw log.
+func decodeEvent(event *ContractLogEvent) (string, map[string]interface{}) {
+	if len(event.Topics) == 0 {
+		return "", nil
+	}
+	contractABI, err := arbiterABI()
+	if err != nil {
+		return "", nil
+	}
+	abiEvent, err := contractABI.EventByID(event.Topics[0])
+	if err != nil {
+		return "", nil
+	}
+	fields := make(map[string]interface{})
+	var indexed abi.Arguments
+	for _, arg := range abiEvent.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(fields, indexed, event.Topics[1:]); err != nil {
+		return abiEvent.Name, nil
+	}
+	if err := abiEvent.Inputs.UnpackIntoMap(fields, event.EventData); err != nil {
+		return abiEvent.Name, nil
+	}
+	for name, value := range fields {
+		fields[name] = formatField(value)
+	}
+	return abiEvent.Name, fields
+}
+
+func formatField(value interface{}) interface{} {
+	switch v := value.(type) {
+	case common.Address:
+		return v.String()
+	case []byte:
+		return hex.EncodeToString(v)
+	case [32]byte:
+		return common.Hash(v).String()
+	case *big.Int:
+		return v.String()
+	}
+	return value
+}
+
+// UnmarshalArchive decodes a stored event in either the JSON archive format
+// or the former gob format.
+func UnmarshalArchive(content []byte) (*ContractLogEvent, error) {
+	if IsJSONArchive(content) {
+		event, err := unmarshalJSONArchive(content)
+		if err == nil {
+			return event, nil
+		}
+		// a gob stream may start with '{' too, fall back before giving up
+		if gobEvent, gobErr := unmarshalGob(content); gobErr == nil {
+			return gobEvent, nil
+		}
+		return nil, err
+	}
+	return unmarshalGob(content)
+}
+
+func unmarshalGob(content []byte) (*ContractLogEvent, error) {
+	event := &ContractLogEvent{}
+	if err := gob.NewDecoder(bytes.NewReader(content)).Decode(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func unmarshalJSONArchive(content []byte) (*ContractLogEvent, error) {
+	var archived archivedEvent
+	if err := json.Unmarshal(content, &archived); err != nil {
+		return nil, err
+	}
+	if archived.Version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported event archive version %d", archived.Version)
+	}
+	return &ContractLogEvent{
+		EventData: archived.Raw.Data,
+		TxHash:    archived.TxHash,
+		Topics:    archived.Raw.Topics,
+		Block:     archived.Block,
+		TxIndex:   archived.TxIndex,
+		LogIndex:  archived.LogIndex,
+	}, nil
+}
+
+// IsJSONArchive reports whether content looks like the JSON archive format.
+func IsJSONArchive(content []byte) bool {
+	content = bytes.TrimLeft(content, " \t\r\n")
+	return len(content) > 0 && content[0] == '{'
+}
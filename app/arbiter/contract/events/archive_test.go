@@ -0,0 +1,118 @@
+// Copyright (c) 2025 The bel2 developers
+
+package events
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func testRequestedEvent(t *testing.T) *ContractLogEvent {
+	contractABI, err := arbiterABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := contractABI.Events["ArbitrationRequested"]
+	var nonIndexed = event.Inputs.NonIndexed()
+	values := make([]interface{}, 0, len(nonIndexed))
+	for _, arg := range nonIndexed {
+		switch arg.Type.String() {
+		case "bytes":
+			values = append(values, []byte{0xde, 0xad})
+		case "address":
+			values = append(values, common.HexToAddress("0x1234"))
+		case "uint256":
+			values = append(values, big.NewInt(7))
+		default:
+			t.Skipf("unexpected argument type %s", arg.Type)
+		}
+	}
+	data, err := nonIndexed.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics := []common.Hash{ArbitrationRequested}
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			topics = append(topics, common.HexToHash("0x1234"))
+		}
+	}
+	return &ContractLogEvent{
+		EventData: data,
+		TxHash:    common.HexToHash("0xaa"),
+		Topics:    topics,
+		Block:     42,
+		TxIndex:   3,
+		LogIndex:  5,
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	event := testRequestedEvent(t)
+	content, err := MarshalArchive(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archived archivedEvent
+	if err := json.Unmarshal(content, &archived); err != nil {
+		t.Fatal(err)
+	}
+	if archived.Event != "ArbitrationRequested" || len(archived.Decoded) == 0 {
+		t.Fatalf("event not decoded: %s", content)
+	}
+	if archived.Decoded["btcTx"] != "dead" {
+		t.Fatalf("unexpected btcTx %v", archived.Decoded["btcTx"])
+	}
+
+	loaded, err := LoadContractEvent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(loaded.EventData, event.EventData) || loaded.TxHash != event.TxHash ||
+		loaded.LogIndex != event.LogIndex || len(loaded.Topics) != len(event.Topics) {
+		t.Fatalf("round trip mismatch: %+v", loaded)
+	}
+}
+
+func TestConvertGobEvent(t *testing.T) {
+	event := testRequestedEvent(t)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(event); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), event.TxHash.String())
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	converted, err := ConvertContractEvent(path)
+	if err != nil || !converted {
+		t.Fatalf("gob file converted %v, err %v", converted, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsJSONArchive(content) {
+		t.Fatalf("file not rewritten as JSON: %x", content)
+	}
+	loaded, err := LoadContractEvent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Block != event.Block || !bytes.Equal(loaded.EventData, event.EventData) {
+		t.Fatalf("converted event mismatch: %+v", loaded)
+	}
+
+	if converted, err := ConvertContractEvent(path); err != nil || converted {
+		t.Fatalf("JSON file converted %v, err %v", converted, err)
+	}
+}
@@ -20,4 +20,5 @@ type ContractLogEvent struct {
 	Topics    []common.Hash
 	Block     uint64
 	TxIndex   uint
+	LogIndex  uint
 }
@@ -3,21 +3,21 @@
 package events
 
 import (
-	"bytes"
-	"encoding/gob"
-	"fmt"
+	"context"
 	"math/big"
 	"os"
 	"path/filepath"
+
+	"github.com/gogf/gf/v2/frame/g"
 )
 
-func CreateConfirmDir(filePath string) error {
+func CreateConfirmDir(ctx context.Context, filePath string) error {
 	_, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
-		fmt.Println("create dir ", filePath)
+		g.Log().Info(ctx, "create dir", filePath)
 		err := os.MkdirAll(filePath, 0755)
 		if err != nil {
-			fmt.Println(" createConfirmDir filePaht ", err)
+			g.Log().Error(ctx, "createConfirmDir error", err, "path:", filePath)
 			return err
 		}
 	}
@@ -25,22 +25,65 @@ func CreateConfirmDir(filePath string) error {
 }
 
 func SaveContractEvent(path string, event *ContractLogEvent) error {
-	var buffer bytes.Buffer
-	encoder := gob.NewEncoder(&buffer)
-	encoder.Encode(event)
+	data, err := MarshalArchive(event)
+	if err != nil {
+		return err
+	}
 
 	dir := filepath.Dir(path)
-	_, err := os.Stat(path)
+	_, err = os.Stat(path)
 	if os.IsNotExist(err) {
 		err := os.MkdirAll(dir, 0755)
 		if err != nil {
 			return err
 		}
 	}
-	err = os.WriteFile(path, buffer.Bytes(), 0644)
+	err = os.WriteFile(path, data, 0644)
 	return err
 }
 
+// LoadContractEvent decodes an event written by SaveContractEvent, in the
+// JSON archive format or the former gob format.
+func LoadContractEvent(content []byte) (*ContractLogEvent, error) {
+	return UnmarshalArchive(content)
+}
+
+// ConvertContractEvent rewrites a stored gob event at path in the JSON
+// archive format. It returns false if the file already is JSON.
+func ConvertContractEvent(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if IsJSONArchive(content) {
+		if _, err := unmarshalJSONArchive(content); err == nil {
+			return false, nil
+		}
+	}
+	event, err := unmarshalGob(content)
+	if err != nil {
+		return false, err
+	}
+	data, err := MarshalArchive(event)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	// keep the original timestamp, the queue migration uses it
+	return true, os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
 func UpdateCurrentBlock(datadir string, block uint64) error {
 	fielPath := datadir + "/" + "listened_block.txt"
 	dir := filepath.Dir(fielPath)
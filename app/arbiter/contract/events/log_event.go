@@ -3,6 +3,7 @@
 package events
 
 import (
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -10,4 +11,23 @@ var (
 	ArbitrationRequested = crypto.Keccak256Hash([]byte("ArbitrationRequested(bytes32,address,address,bytes,bytes,address)"))
 
 	ArbitrationResultSubmitted = crypto.Keccak256Hash([]byte("ArbitrationResultSubmitted(bytes,bytes32)"))
+
+	TransactionCompleted = crypto.Keccak256Hash([]byte("TransactionCompleted(bytes32,address)"))
+
+	TransactionRegistered = crypto.Keccak256Hash([]byte("TransactionRegistered(bytes32,address,address,uint256,uint256,address)"))
 )
+
+var eventNames = map[common.Hash]string{
+	ArbitrationRequested:       "ArbitrationRequested",
+	ArbitrationResultSubmitted: "ArbitrationResultSubmitted",
+	TransactionCompleted:       "TransactionCompleted",
+	TransactionRegistered:      "TransactionRegistered",
+}
+
+// Name returns the event name of topic, or "unknown".
+func Name(topic common.Hash) string {
+	if name, ok := eventNames[topic]; ok {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gogf/gf/os/gfile"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gctx"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+const convertUsage = `Usage: arbiter convert [-data <dataPath>] [dir ...]
+
+Rewrites stored contract events from the former gob format to the JSON
+archive format. Files already in JSON are left alone. Without dirs, the
+event directories under the data path are converted.
+`
+
+// eventDirs are the directories below the data path holding stored events.
+var eventDirs = []string{
+	"loan/request",
+	"loan/failed",
+	"loan/signed",
+	"loan/claimed",
+	"loan/imported",
+	"loan_signed_event",
+	"loan_completed_event",
+}
+
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(convertUsage) }
+	dataPath := fs.String("data", "", "data path, read from config.yaml if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dirs := fs.Args()
+	if len(dirs) == 0 {
+		if *dataPath == "" {
+			ctx := gctx.New()
+			g.Cfg().GetAdapter().(*gcfg.AdapterFile).SetPath(".")
+			value, err := g.Cfg().Get(ctx, "arbiter.dataPath")
+			if err != nil || value.String() == "" {
+				return errors.New("no dataPath configured, use -data")
+			}
+			*dataPath = getExpandedPath(value.String())
+		}
+		for _, dir := range eventDirs {
+			dirs = append(dirs, gfile.Join(*dataPath, dir))
+		}
+	}
+
+	var converted, skipped, failed int
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			// temporary files and pending fee claims hold no event
+			if ext := filepath.Ext(entry.Name()); entry.IsDir() || ext == ".tmp" || ext == ".Pending" {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			ok, err := events.ConvertContractEvent(path)
+			switch {
+			case err != nil:
+				failed++
+				fmt.Println("convert", path, "failed:", err)
+			case ok:
+				converted++
+			default:
+				skipped++
+			}
+		}
+	}
+	fmt.Printf("converted %d, already JSON %d, failed %d\n", converted, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be converted", failed)
+	}
+	return nil
+}
@@ -0,0 +1,465 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/glog"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/btcsource"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+)
+
+const doctorUsage = `Usage: arbiter doctor [-timeout 30s] [run flags]
+
+Checks the configuration, the ESC RPC, the BTC source, the contracts, the
+keys and the data directories without starting the arbiter, and prints a
+report with a
+hint for every problem. Exits with status 1 if any check fails. Accepts the
+flags and ARBITER_* environment variables of "arbiter run".
+`
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+type doctorCheck struct {
+	name   string
+	status checkStatus
+	detail string
+	hint   string
+}
+
+type doctor struct {
+	ctx     context.Context
+	timeout time.Duration
+	cfg     *config.Config
+	checks  []doctorCheck
+
+	inspector *contract.Inspector
+	escKey    string
+	btcKey    string
+}
+
+func runDoctor(args []string) error {
+	f := newRunFlags("doctor")
+	f.fs.Usage = func() {
+		fmt.Print(doctorUsage)
+		printRunUsage()
+	}
+	timeout := f.fs.Duration("timeout", 30*time.Second, "time limit for each network check")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	// the checks report problems themselves, keep the log quiet
+	g.Log().SetLevel(glog.LEVEL_CRIT)
+
+	d := &doctor{ctx: gctx.New(), timeout: *timeout}
+	d.checkConfig(f)
+	d.checkKeyFiles()
+	d.checkDataDirs()
+	d.checkRPC()
+	d.checkBTCSource()
+	d.checkContracts()
+	d.checkOperator()
+	d.checkBalance()
+	return d.print()
+}
+
+func (d *doctor) report(name string, status checkStatus, detail, hint string) {
+	d.checks = append(d.checks, doctorCheck{name: name, status: status, detail: detail, hint: hint})
+}
+
+func (d *doctor) print() error {
+	failed := 0
+	for _, c := range d.checks {
+		fmt.Printf("[%s] %-12s %s\n", c.status, c.name, c.detail)
+		if c.hint != "" && c.status != checkPass {
+			fmt.Printf("       %-12s hint: %s\n", "", c.hint)
+		}
+		if c.status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	fmt.Println("\nAll checks passed.")
+	return nil
+}
+
+func (d *doctor) checkConfig(f *runFlags) {
+	path, err := f.load(d.ctx)
+	if err != nil {
+		d.report("config", checkFail, err.Error(), `create the config with "arbiter init" or pass -config`)
+		return
+	}
+	cfg, err := loadConfig(d.ctx)
+	if err != nil {
+		d.report("config", checkFail, err.Error(), "fix the value in "+path)
+		return
+	}
+	// the other checks run with what is valid
+	d.cfg = cfg
+	var problems []string
+	if cfg.Http == "" {
+		problems = append(problems, "chain.esc is empty")
+	}
+	for key, value := range map[string]string{
+		"escArbiterContractAddress":        cfg.ESCArbiterContractAddress,
+		"escArbiterManagerContractAddress": cfg.ESCArbiterManagerContractAddress,
+		"escArbiterAddress":                cfg.ESCArbiterAddress,
+	} {
+		if !common.IsHexAddress(value) {
+			problems = append(problems, fmt.Sprintf("%s %q is not an ESC address", key, value))
+		}
+	}
+	if len(problems) > 0 {
+		d.report("config", checkFail, strings.Join(problems, "; "), "fix the values in "+path)
+		return
+	}
+	d.report("config", checkPass, path, "")
+}
+
+// readKey returns the private key of a key file, or of env if the file is
+// missing, as "arbiter run" would.
+func readKey(path, env string) (key, source string, err error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if key = strings.TrimSpace(os.Getenv(env)); key != "" {
+			return key, env, validatePrivateKey(key)
+		}
+	}
+	if err != nil {
+		return "", path, err
+	}
+	var a struct {
+		PrivateKey string `json:"privKey"`
+	}
+	if err := json.Unmarshal(data, &a); err != nil {
+		return "", path, err
+	}
+	return a.PrivateKey, path, validatePrivateKey(a.PrivateKey)
+}
+
+func (d *doctor) checkKeyFiles() {
+	if d.cfg == nil {
+		d.report("keys", checkSkip, "no valid config", "")
+		return
+	}
+	for _, k := range []struct {
+		name, path, env string
+		key             *string
+	}{
+		{"esc key", d.cfg.EscKeyFilePath, "ARBITER_ESC_PRIVATE_KEY", &d.escKey},
+		{"btc key", d.cfg.ArbiterKeyFilePath, "ARBITER_BTC_PRIVATE_KEY", &d.btcKey},
+	} {
+		key, source, err := readKey(k.path, k.env)
+		if err != nil {
+			d.report(k.name, checkFail, fmt.Sprintf("%s: %v", source, err),
+				`create it with "arbiter init" or set `+k.env)
+			continue
+		}
+		*k.key = key
+		if source == k.env {
+			d.report(k.name, checkPass, "from "+k.env+", written to "+k.path+" on start", "")
+			continue
+		}
+		if problem := keyFilePermissions(k.path); problem != "" {
+			d.report(k.name, checkFail, problem, "chmod 600 "+k.path+" and chmod 700 "+filepath.Dir(k.path))
+			continue
+		}
+		d.report(k.name, checkPass, k.path, "")
+	}
+}
+
+// keyFilePermissions describes why the permissions of a key file are too
+// open, or returns "" if they are strict.
+func keyFilePermissions(path string) string {
+	if runtime.GOOS == "windows" {
+		// file modes do not reflect the ACLs on Windows
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err.Error()
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		return fmt.Sprintf("%s has mode %04o, readable by other users", path, mode)
+	}
+	dir, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return err.Error()
+	}
+	if mode := dir.Mode().Perm(); mode&0022 != 0 {
+		return fmt.Sprintf("%s has mode %04o, writable by other users", filepath.Dir(path), mode)
+	}
+	return ""
+}
+
+func (d *doctor) checkDataDirs() {
+	if d.cfg == nil {
+		d.report("data dirs", checkSkip, "no valid config", "")
+		return
+	}
+	for _, dir := range []string{d.cfg.DataDir, d.cfg.LoanLogPath, filepath.Dir(d.cfg.QueueDBPath)} {
+		if err := checkWritable(dir); err != nil {
+			d.report("data dirs", checkFail, err.Error(), "make the data path writable by the arbiter user")
+			return
+		}
+	}
+	d.report("data dirs", checkPass, d.cfg.DataDir, "")
+}
+
+// checkWritable creates and removes a file in dir, or in the closest
+// existing parent if dir is still to be created.
+func checkWritable(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+func (d *doctor) checkRPC() {
+	if d.cfg == nil || d.cfg.Http == "" {
+		d.report("rpc", checkSkip, "no RPC configured", "")
+		return
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+	inspector, err := contract.NewInspector(ctx, d.cfg.Http, d.cfg.ESCArbiterContractAddress, d.cfg.ESCArbiterManagerContractAddress)
+	if err != nil {
+		d.report("rpc", checkFail, err.Error(), "check chain.esc")
+		return
+	}
+	chainId, err := inspector.ChainID(ctx)
+	if err != nil {
+		d.report("rpc", checkFail, fmt.Sprintf("%s unreachable: %v", d.cfg.Http, err), "check chain.esc and the network connection")
+		return
+	}
+	height, err := inspector.LatestHeight(ctx)
+	if err != nil {
+		d.report("rpc", checkFail, fmt.Sprintf("%s: %v", d.cfg.Http, err), "check chain.esc, the node may still be syncing")
+		return
+	}
+	d.inspector = inspector
+	if want := d.cfg.ESCChainID; want != 0 && chainId.Int64() != want {
+		d.report("rpc", checkFail, fmt.Sprintf("chain id %s, network %s expects %d", chainId, d.cfg.Network, want),
+			"point chain.esc at an ESC "+d.cfg.Network+" node, change network or set chain.escChainId")
+		return
+	}
+	d.report("rpc", checkPass, fmt.Sprintf("%s chain id %s, block %d", d.cfg.Http, chainId, height), "")
+}
+
+// publicExplorers are Esplora APIs run by third parties, which learn the
+// transactions the arbiter looks up.
+var publicExplorers = []string{"mempool.space", "blockstream.info"}
+
+func (d *doctor) checkBTCSource() {
+	if d.cfg == nil {
+		d.report("btc source", checkSkip, "no valid config", "")
+		return
+	}
+	source, err := btcsource.New(d.cfg.BTCSourceConfig())
+	if err != nil {
+		d.report("btc source", checkFail, err.Error(), "check btcSource and its settings")
+		return
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+	height, err := source.GetTipHeight(ctx)
+	if err != nil {
+		d.report("btc source", checkFail, fmt.Sprintf("%s unreachable: %v", source, err),
+			"check btcApi, bitcoindUrl or electrumAddr and the network connection")
+		return
+	}
+	detail := fmt.Sprintf("%s, tip height %d", source, height)
+	if strings.EqualFold(d.cfg.BTCSource, btcsource.Esplora) || d.cfg.BTCSource == "" {
+		for _, host := range publicExplorers {
+			if strings.Contains(strings.ToLower(d.cfg.BTCAPI), host) {
+				d.report("btc source", checkWarn, detail+", a public explorer sees the escrows looked up",
+					"set btcSource to bitcoind or electrum, or point btcApi at your own esplora")
+				return
+			}
+		}
+	}
+	d.report("btc source", checkPass, detail, "")
+}
+
+func (d *doctor) checkContracts() {
+	if d.inspector == nil {
+		d.report("contracts", checkSkip, "no RPC", "")
+		return
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+	for _, c := range []struct {
+		name, key string
+		address   common.Address
+	}{
+		{"arbiter contract", "escArbiterContractAddress", d.inspector.Loan},
+		{"manager contract", "escArbiterManagerContractAddress", d.inspector.Manager},
+	} {
+		ok, err := d.inspector.HasCode(ctx, c.address)
+		if err != nil {
+			d.report("contracts", checkFail, err.Error(), "check chain.esc")
+			return
+		}
+		if !ok {
+			d.report("contracts", checkFail, fmt.Sprintf("no %s deployed at %s", c.name, c.address),
+				"check "+c.key+" for the configured network")
+			return
+		}
+	}
+	manager, err := d.inspector.LoanManager(ctx)
+	if err != nil {
+		d.report("contracts", checkFail, "arbiter contract does not answer arbitratorManager(): "+err.Error(),
+			"escArbiterContractAddress must be the arbiter contract")
+		return
+	}
+	transactionManager, err := d.inspector.ManagerTransactionManager(ctx)
+	if err != nil {
+		d.report("contracts", checkFail, "manager contract does not answer transactionManager(): "+err.Error(),
+			"escArbiterManagerContractAddress must be the arbitrator manager")
+		return
+	}
+	if manager != d.inspector.Manager {
+		d.report("contracts", checkWarn, fmt.Sprintf("arbiter contract uses manager %s, configured %s", manager, d.inspector.Manager),
+			"check escArbiterManagerContractAddress")
+		return
+	}
+	if transactionManager != d.inspector.Loan {
+		d.report("contracts", checkWarn, fmt.Sprintf("manager uses transaction manager %s, configured arbiter contract %s", transactionManager, d.inspector.Loan),
+			"check escArbiterContractAddress")
+		return
+	}
+	d.report("contracts", checkPass, fmt.Sprintf("arbiter %s, manager %s", d.inspector.Loan, d.inspector.Manager), "")
+}
+
+func (d *doctor) checkOperator() {
+	if d.inspector == nil {
+		d.report("operator", checkSkip, "no RPC", "")
+		return
+	}
+	if !common.IsHexAddress(d.cfg.ESCArbiterAddress) {
+		d.report("operator", checkSkip, "no valid escArbiterAddress", "")
+		return
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+	info, err := d.inspector.ArbitratorInfo(ctx, common.HexToAddress(d.cfg.ESCArbiterAddress))
+	if err != nil {
+		d.report("operator", checkFail, "getArbitratorInfo: "+err.Error(), "check escArbiterAddress and escArbiterManagerContractAddress")
+		return
+	}
+	operator := common.HexToAddress(info.Operator)
+	if operator == (common.Address{}) {
+		d.report("operator", checkFail, d.cfg.ESCArbiterAddress+" is not a registered arbitrator",
+			`register it with "arbiter admin register-eth" or check escArbiterAddress`)
+		return
+	}
+
+	if d.escKey == "" {
+		d.report("esc operator", checkSkip, "no ESC key", "")
+	} else if address, err := escAddress(d.escKey); err != nil {
+		d.report("esc operator", checkFail, err.Error(), "check the ESC key")
+	} else if address != operator {
+		d.report("esc operator", checkFail, fmt.Sprintf("ESC key is %s, the registered operator is %s", address, operator),
+			"use the operator key in escKey.json or set the operator on chain")
+	} else {
+		d.report("esc operator", checkPass, operator.String(), "")
+	}
+
+	if d.btcKey == "" {
+		d.report("btc operator", checkSkip, "no BTC key", "")
+		return
+	}
+	pubKey, err := arbiter.GetPubKey(d.btcKey)
+	if err != nil {
+		d.report("btc operator", checkFail, err.Error(), "check the BTC key")
+		return
+	}
+	onChain := hex.EncodeToString(info.OperatorBtcPubKey)
+	if !bytes.EqualFold([]byte(pubKey), []byte(onChain)) {
+		d.report("btc operator", checkFail, fmt.Sprintf("BTC key has public key %s, the registered OperatorBtcPubKey is %s", pubKey, onChain),
+			"use the operator key in btcKey.json or update the BTC public key on chain")
+		return
+	}
+	d.report("btc operator", checkPass, pubKey, "")
+}
+
+func escAddress(key string) (common.Address, error) {
+	privateKey, err := crypto.HexToECDSA(key)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+func (d *doctor) checkBalance() {
+	if d.inspector == nil || d.escKey == "" {
+		d.report("gas balance", checkSkip, "no RPC or ESC key", "")
+		return
+	}
+	address, err := escAddress(d.escKey)
+	if err != nil {
+		d.report("gas balance", checkSkip, err.Error(), "")
+		return
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+	balance, err := d.inspector.Balance(ctx, address)
+	if err != nil {
+		d.report("gas balance", checkFail, err.Error(), "check chain.esc")
+		return
+	}
+	detail := fmt.Sprintf("%s holds %s ELA", address, formatEther(balance))
+	switch {
+	case balance.Sign() == 0:
+		d.report("gas balance", checkFail, detail, "send ELA to the operator, it pays the gas of every submission")
+	case d.cfg.AlertLowBalance != nil && balance.Cmp(d.cfg.AlertLowBalance) < 0:
+		d.report("gas balance", checkWarn, detail+", below alert.lowBalance", "top up the operator")
+	default:
+		d.report("gas balance", checkPass, detail, "")
+	}
+}
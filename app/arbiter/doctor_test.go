@@ -0,0 +1,240 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/contract_abi"
+)
+
+const (
+	testEscKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testBtcKey = "0000000000000000000000000000000000000000000000000000000000000001"
+)
+
+var (
+	testLoan       = common.HexToAddress("0xA10b92006743Ef3B12077da67e465963743b03D3")
+	testManager    = common.HexToAddress("0x9963b5214434776D043A4e98Bc7f33321F6aaCfc")
+	testArbitrator = common.HexToAddress("0x0262aB0ED65373cC855C34529fDdeAa0e686D913")
+)
+
+// arbitratorInfo mirrors the getArbitratorInfo tuple for packing.
+type arbitratorInfo struct {
+	Arbitrator            common.Address
+	Paused                bool
+	CurrentFeeRate        *big.Int
+	ActiveTransactionId   [32]byte
+	EthAmount             *big.Int
+	Erc20Token            common.Address
+	NftContract           common.Address
+	NftTokenIds           []*big.Int
+	Operator              common.Address
+	OperatorBtcPubKey     []byte
+	OperatorBtcAddress    string
+	DeadLine              *big.Int
+	RevenueBtcPubKey      []byte
+	RevenueBtcAddress     string
+	RevenueETHAddress     common.Address
+	LastSubmittedWorkTime *big.Int
+}
+
+// fakeESC answers the JSON-RPC calls of the doctor like an ESC node with
+// the arbiter contracts deployed, and the tip height of an Esplora API
+// under /esplora.
+func fakeESC(t *testing.T, chainId int64, operator common.Address, btcPubKey []byte) *httptest.Server {
+	loanABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	managerABI, err := abi.JSON(strings.NewReader(contract_abi.ArbiterManagerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack := func(contractABI abi.ABI, method string, values ...interface{}) hexutil.Bytes {
+		out, err := contractABI.Methods[method].Outputs.Pack(values...)
+		if err != nil {
+			t.Fatal(method, err)
+		}
+		return out
+	}
+	info := arbitratorInfo{
+		Arbitrator:            testArbitrator,
+		CurrentFeeRate:        big.NewInt(100),
+		EthAmount:             big.NewInt(0),
+		NftTokenIds:           []*big.Int{},
+		Operator:              operator,
+		OperatorBtcPubKey:     btcPubKey,
+		DeadLine:              big.NewInt(0),
+		LastSubmittedWorkTime: big.NewInt(0),
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/esplora/blocks/tip/height" {
+			w.Write([]byte("850000"))
+			return
+		}
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		var result interface{}
+		switch req.Method {
+		case "eth_chainId":
+			result = hexutil.EncodeBig(big.NewInt(chainId))
+		case "eth_getBlockByNumber":
+			result = map[string]string{"number": "0x100"}
+		case "eth_getCode":
+			result = "0x6080"
+		case "eth_getBalance":
+			result = hexutil.EncodeBig(big.NewInt(1e18))
+		case "eth_call":
+			var call struct {
+				To   common.Address `json:"to"`
+				Data hexutil.Bytes  `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &call)
+			contractABI := managerABI
+			if call.To == testLoan {
+				contractABI = loanABI
+			}
+			method, err := contractABI.MethodById(call.Data[:4])
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch method.Name {
+			case "arbitratorManager":
+				result = pack(loanABI, method.Name, testManager)
+			case "transactionManager":
+				result = pack(managerABI, method.Name, testLoan)
+			case "getArbitratorInfo":
+				result = pack(managerABI, method.Name, info)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+}
+
+func newTestDoctor(t *testing.T, url string) *doctor {
+	dir := t.TempDir()
+	keys := filepath.Join(dir, "keys")
+	if err := os.Mkdir(keys, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for file, key := range map[string]string{"escKey.json": testEscKey, "btcKey.json": testBtcKey} {
+		data := `{"privKey":"` + key + `"}`
+		if err := os.WriteFile(filepath.Join(keys, file), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &doctor{
+		ctx:     context.Background(),
+		timeout: 5 * time.Second,
+		cfg: &config.Config{
+			Network:                          "mainnet",
+			Http:                             url,
+			ESCChainID:                       20,
+			BTCAPI:                           url + "/esplora",
+			ESCArbiterContractAddress:        testLoan.String(),
+			ESCArbiterManagerContractAddress: testManager.String(),
+			ESCArbiterAddress:                testArbitrator.String(),
+			DataDir:                          filepath.Join(dir, "data"),
+			LoanLogPath:                      filepath.Join(dir, "data", "logs"),
+			QueueDBPath:                      filepath.Join(dir, "data", "loan", "queue.db"),
+			EscKeyFilePath:                   filepath.Join(keys, "escKey.json"),
+			ArbiterKeyFilePath:               filepath.Join(keys, "btcKey.json"),
+		},
+	}
+}
+
+func (d *doctor) run() {
+	d.checkKeyFiles()
+	d.checkDataDirs()
+	d.checkRPC()
+	d.checkBTCSource()
+	d.checkContracts()
+	d.checkOperator()
+	d.checkBalance()
+}
+
+func (d *doctor) status(name string) checkStatus {
+	for _, c := range d.checks {
+		if c.name == name {
+			return c.status
+		}
+	}
+	return ""
+}
+
+func TestDoctorPasses(t *testing.T) {
+	operator, err := escAddress(testEscKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := arbiter.GetPubKey(testBtcKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	btcPubKey, _ := hex.DecodeString(pubKey)
+	srv := fakeESC(t, 20, operator, btcPubKey)
+	defer srv.Close()
+
+	d := newTestDoctor(t, srv.URL)
+	d.run()
+	for _, c := range d.checks {
+		if c.status != checkPass {
+			t.Errorf("%s: %s %s", c.name, c.status, c.detail)
+		}
+	}
+}
+
+func TestDoctorFindsProblems(t *testing.T) {
+	srv := fakeESC(t, 21, common.HexToAddress("0x01"), []byte{2, 3})
+	defer srv.Close()
+
+	d := newTestDoctor(t, srv.URL)
+	if err := os.Chmod(d.cfg.EscKeyFilePath, 0644); err != nil {
+		t.Fatal(err)
+	}
+	d.run()
+	if d.status("esc key") != checkFail {
+		t.Error("readable key file not reported")
+	}
+	if d.status("rpc") != checkFail {
+		t.Error("testnet chain id on mainnet not reported")
+	}
+
+	d = newTestDoctor(t, srv.URL)
+	d.cfg.Network = "testnet"
+	d.cfg.ESCChainID = 21
+	d.cfg.BTCSource = "electrum"
+	d.run()
+	if d.status("esc operator") != checkFail || d.status("btc operator") != checkFail {
+		t.Errorf("wrong operator keys not reported: %+v", d.checks)
+	}
+	if d.status("btc source") != checkFail {
+		t.Error("electrum without electrumAddr not reported")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 The bel2 developers
+
+// Package history records what the arbiter saw and did, for reporting
+// across nodes. The request queue stays the source of truth; a Recorder
+// only keeps a copy.
+package history
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+const (
+	StorageFile     = "file"
+	StoragePostgres = "pgsql"
+)
+
+// Recorder stores the arbitration history.
+type Recorder interface {
+	// RecordEvent stores a contract event seen by the listener.
+	RecordEvent(ctx context.Context, event *events.ContractLogEvent) error
+	// RecordQueueItem stores the current state of a queued request.
+	RecordQueueItem(ctx context.Context, item *queue.Item) error
+	// RecordSignature stores the signature produced for a request.
+	RecordSignature(ctx context.Context, key queue.Key, txId common.Hash, signature []byte) error
+	// RecordSubmission stores an ESC submission attempt and its error, if any.
+	RecordSubmission(ctx context.Context, key queue.Key, txId common.Hash, submitTxHash common.Hash, submitErr error) error
+	// RecordReceipt stores the receipt of an ESC transaction sent by us.
+	RecordReceipt(ctx context.Context, receipt *types.Receipt) error
+}
+
+// Nop is the Recorder of the default file based setup, where the queue
+// database and the event log are the only history.
+type Nop struct{}
+
+func (Nop) RecordEvent(context.Context, *events.ContractLogEvent) error { return nil }
+
+func (Nop) RecordQueueItem(context.Context, *queue.Item) error { return nil }
+
+func (Nop) RecordSignature(context.Context, queue.Key, common.Hash, []byte) error { return nil }
+
+func (Nop) RecordSubmission(context.Context, queue.Key, common.Hash, common.Hash, error) error {
+	return nil
+}
+
+func (Nop) RecordReceipt(context.Context, *types.Receipt) error { return nil }
@@ -0,0 +1,206 @@
+// Copyright (c) 2025 The bel2 developers
+
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/database/gdb"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+// migrations are applied in order and recorded in schema_migrations. Never
+// edit an applied migration, append a new one.
+var migrations = []string{
+	// 1: initial schema
+	`CREATE TABLE IF NOT EXISTS contract_events (
+		id         BIGSERIAL PRIMARY KEY,
+		arbiter    TEXT        NOT NULL,
+		tx_hash    TEXT        NOT NULL,
+		log_index  INTEGER     NOT NULL,
+		block      BIGINT      NOT NULL,
+		tx_index   INTEGER     NOT NULL,
+		topic0     TEXT        NOT NULL,
+		topics     TEXT        NOT NULL,
+		data       BYTEA,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (arbiter, tx_hash, log_index)
+	);
+	CREATE INDEX IF NOT EXISTS contract_events_topic0_idx ON contract_events (topic0, block);
+
+	CREATE TABLE IF NOT EXISTS queue_items (
+		arbiter        TEXT        NOT NULL,
+		key            TEXT        NOT NULL,
+		tx_id          TEXT        NOT NULL,
+		state          TEXT        NOT NULL,
+		attempts       INTEGER     NOT NULL,
+		last_error     TEXT        NOT NULL,
+		submit_tx_hash TEXT        NOT NULL,
+		created_at     TIMESTAMPTZ NOT NULL,
+		updated_at     TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (arbiter, key)
+	);
+	CREATE INDEX IF NOT EXISTS queue_items_tx_id_idx ON queue_items (tx_id);
+
+	CREATE TABLE IF NOT EXISTS signatures (
+		id         BIGSERIAL PRIMARY KEY,
+		arbiter    TEXT        NOT NULL,
+		key        TEXT        NOT NULL,
+		tx_id      TEXT        NOT NULL,
+		signature  BYTEA       NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+
+	CREATE TABLE IF NOT EXISTS submissions (
+		id             BIGSERIAL PRIMARY KEY,
+		arbiter        TEXT        NOT NULL,
+		key            TEXT        NOT NULL,
+		tx_id          TEXT        NOT NULL,
+		submit_tx_hash TEXT        NOT NULL,
+		error          TEXT        NOT NULL,
+		created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX IF NOT EXISTS submissions_tx_id_idx ON submissions (tx_id);
+
+	CREATE TABLE IF NOT EXISTS receipts (
+		arbiter    TEXT        NOT NULL,
+		tx_hash    TEXT        NOT NULL,
+		block      BIGINT      NOT NULL,
+		status     INTEGER     NOT NULL,
+		gas_used   BIGINT      NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (arbiter, tx_hash)
+	);`,
+	// 2: retry schedule and arbitration deadline of queue items
+	`ALTER TABLE queue_items
+		ADD COLUMN IF NOT EXISTS next_attempt TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS deadline     TIMESTAMPTZ;`,
+}
+
+// Postgres records the history in a PostgreSQL database configured in the
+// gf "database" section. Rows carry the arbiter address so several arbiters
+// can share one database.
+type Postgres struct {
+	db      gdb.DB
+	arbiter string
+}
+
+func NewPostgres(ctx context.Context, db gdb.DB, arbiter string) (*Postgres, error) {
+	p := &Postgres{db: db, arbiter: strings.ToLower(arbiter)}
+	if err := p.migrate(ctx); err != nil {
+		return nil, fmt.Errorf("migrate history database: %v", err)
+	}
+	return p, nil
+}
+
+func (p *Postgres) migrate(ctx context.Context) error {
+	_, err := p.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+	value, err := p.db.GetValue(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`)
+	if err != nil {
+		return err
+	}
+	for version := value.Int() + 1; version <= len(migrations); version++ {
+		err := p.db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			if _, err := tx.Exec(migrations[version-1]); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d: %v", version, err)
+		}
+	}
+	return nil
+}
+
+func (p *Postgres) RecordEvent(ctx context.Context, event *events.ContractLogEvent) error {
+	topics := make([]string, len(event.Topics))
+	for i, topic := range event.Topics {
+		topics[i] = topic.String()
+	}
+	var topic0 string
+	if len(topics) > 0 {
+		topic0 = topics[0]
+	}
+	_, err := p.db.Exec(ctx, `INSERT INTO contract_events
+		(arbiter, tx_hash, log_index, block, tx_index, topic0, topics, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (arbiter, tx_hash, log_index) DO NOTHING`,
+		p.arbiter, event.TxHash.String(), event.LogIndex, event.Block, event.TxIndex,
+		topic0, strings.Join(topics, ","), event.EventData)
+	return err
+}
+
+func (p *Postgres) RecordQueueItem(ctx context.Context, item *queue.Item) error {
+	var txId string
+	if len(item.Event.Topics) > 1 {
+		txId = item.Event.Topics[1].String()
+	}
+	_, err := p.db.Exec(ctx, `INSERT INTO queue_items
+		(arbiter, key, tx_id, state, attempts, last_error, submit_tx_hash, next_attempt, deadline, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (arbiter, key) DO UPDATE SET
+			state = EXCLUDED.state,
+			attempts = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			submit_tx_hash = EXCLUDED.submit_tx_hash,
+			next_attempt = EXCLUDED.next_attempt,
+			deadline = EXCLUDED.deadline,
+			updated_at = EXCLUDED.updated_at`,
+		p.arbiter, item.Key.String(), txId, string(item.State), item.Attempts, item.LastError,
+		submitTxHash(item.SubmitTxHash), nullTime(item.NextAttempt), nullTime(item.Deadline),
+		item.CreatedAt, item.UpdatedAt)
+	return err
+}
+
+func (p *Postgres) RecordSignature(ctx context.Context, key queue.Key, txId common.Hash, signature []byte) error {
+	_, err := p.db.Exec(ctx, `INSERT INTO signatures (arbiter, key, tx_id, signature) VALUES (?, ?, ?, ?)`,
+		p.arbiter, key.String(), txId.String(), signature)
+	return err
+}
+
+func (p *Postgres) RecordSubmission(ctx context.Context, key queue.Key, txId common.Hash, hash common.Hash, submitErr error) error {
+	var errText string
+	if submitErr != nil {
+		errText = submitErr.Error()
+	}
+	_, err := p.db.Exec(ctx, `INSERT INTO submissions (arbiter, key, tx_id, submit_tx_hash, error) VALUES (?, ?, ?, ?, ?)`,
+		p.arbiter, key.String(), txId.String(), submitTxHash(hash), errText)
+	return err
+}
+
+func (p *Postgres) RecordReceipt(ctx context.Context, receipt *types.Receipt) error {
+	_, err := p.db.Exec(ctx, `INSERT INTO receipts (arbiter, tx_hash, block, status, gas_used)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (arbiter, tx_hash) DO NOTHING`,
+		p.arbiter, receipt.TxHash.String(), receipt.BlockNumber.Uint64(), receipt.Status, receipt.GasUsed)
+	return err
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func submitTxHash(hash common.Hash) string {
+	if hash == (common.Hash{}) {
+		return ""
+	}
+	return hash.String()
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 The bel2 developers
+
+//go:build integration
+
+package history
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "github.com/gogf/gf/contrib/drivers/pgsql/v2"
+	"github.com/gogf/gf/v2/database/gdb"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+// TestPostgresIntegration migrates the empty database linked by
+// ARBITER_TEST_PGSQL, e.g. "pgsql:user:password@tcp(127.0.0.1:5432)/arbiter_test",
+// and records a queue item:
+//
+//	ARBITER_TEST_PGSQL=... go test -tags integration ./history
+func TestPostgresIntegration(t *testing.T) {
+	link := os.Getenv("ARBITER_TEST_PGSQL")
+	if link == "" {
+		t.Skip("ARBITER_TEST_PGSQL not set")
+	}
+	db, err := gdb.New(gdb.ConfigNode{Link: link})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	p, err := NewPostgres(ctx, db, "0xAB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// migrating again is a no-op
+	if _, err := NewPostgres(ctx, db, "0xAB"); err != nil {
+		t.Fatal(err)
+	}
+	version, err := db.GetValue(ctx, `SELECT MAX(version) FROM schema_migrations`)
+	if err != nil || version.Int() != len(migrations) {
+		t.Fatalf("schema version %v, err %v", version, err)
+	}
+
+	event := &events.ContractLogEvent{
+		TxHash: common.Hash{1},
+		Topics: []common.Hash{events.ArbitrationRequested, {2}},
+	}
+	item := &queue.Item{
+		Key:         queue.KeyOf(event),
+		Event:       event,
+		State:       queue.StatePending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		NextAttempt: time.Now(),
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.RecordQueueItem(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+		item.State = queue.StateSigned
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2025 The bel2 developers
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/database/gdb"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/queue"
+)
+
+// statement is a query run by the recorder and its arguments.
+type statement struct {
+	query string
+	args  []interface{}
+}
+
+// fakeDB keeps the applied migration versions in memory and records the
+// statements run outside of migrations. Only the methods the recorder uses
+// are implemented.
+type fakeDB struct {
+	gdb.DB
+	versions   []int
+	migrations []string
+	statements []statement
+	// migration statement that fails
+	fail string
+}
+
+func (db *fakeDB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.statements = append(db.statements, statement{query, args})
+	return nil, nil
+}
+
+func (db *fakeDB) GetValue(ctx context.Context, query string, args ...interface{}) (gdb.Value, error) {
+	version := 0
+	for _, v := range db.versions {
+		if v > version {
+			version = v
+		}
+	}
+	return gvar.New(version), nil
+}
+
+// Transaction commits the statements of f unless it fails.
+func (db *fakeDB) Transaction(ctx context.Context, f func(ctx context.Context, tx gdb.TX) error) error {
+	tx := &fakeTX{db: db}
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	db.versions = append(db.versions, tx.versions...)
+	db.migrations = append(db.migrations, tx.migrations...)
+	return nil
+}
+
+type fakeTX struct {
+	gdb.TX
+	db         *fakeDB
+	versions   []int
+	migrations []string
+}
+
+func (tx *fakeTX) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if strings.HasPrefix(query, "INSERT INTO schema_migrations") {
+		tx.versions = append(tx.versions, args[0].(int))
+		return nil, nil
+	}
+	if query == tx.db.fail {
+		return nil, errors.New("syntax error")
+	}
+	tx.migrations = append(tx.migrations, query)
+	return nil, nil
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{}
+	if _, err := NewPostgres(ctx, db, "0xAB"); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.versions) != len(migrations) || len(db.migrations) != len(migrations) {
+		t.Fatalf("fresh database: versions %v, %d migrations run", db.versions, len(db.migrations))
+	}
+	for i, version := range db.versions {
+		if version != i+1 || db.migrations[i] != migrations[i] {
+			t.Errorf("migration %d applied as version %d", i+1, version)
+		}
+	}
+
+	if _, err := NewPostgres(ctx, db, "0xAB"); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.migrations) != len(migrations) {
+		t.Errorf("migrated database ran %d migrations again", len(db.migrations)-len(migrations))
+	}
+
+	// a database one release behind only gets the last migration
+	last := len(migrations)
+	applied := make([]int, last-1)
+	for i := range applied {
+		applied[i] = i + 1
+	}
+	db = &fakeDB{versions: applied}
+	if _, err := NewPostgres(ctx, db, "0xAB"); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.migrations) != 1 || db.migrations[0] != migrations[last-1] || db.versions[last-1] != last {
+		t.Errorf("upgrade ran %d migrations, versions %v", len(db.migrations), db.versions)
+	}
+
+	// a failed migration is not recorded
+	db = &fakeDB{versions: applied, fail: migrations[last-1]}
+	if _, err := NewPostgres(ctx, db, "0xAB"); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("migration %d", last)) {
+		t.Errorf("failed migration: %v", err)
+	}
+	if len(db.versions) != last-1 || len(db.migrations) != 0 {
+		t.Errorf("failed migration recorded: versions %v", db.versions)
+	}
+}
+
+var (
+	createTable = regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS (\w+) \((.*?)\n\t\);`)
+	alterTable  = regexp.MustCompile(`(?s)ALTER TABLE (\w+)(.*?);`)
+	addColumn   = regexp.MustCompile(`ADD COLUMN IF NOT EXISTS (\w+)`)
+	insertInto  = regexp.MustCompile(`(?s)INSERT INTO (\w+)\s*\(([^)]*)\)\s*VALUES\s*\(([^)]*)\)(.*)`)
+	setColumn   = regexp.MustCompile(`(\w+) = EXCLUDED\.(\w+)`)
+)
+
+// schemaColumns returns the columns of every table the migrations create.
+func schemaColumns(t *testing.T) map[string]map[string]bool {
+	tables := make(map[string]map[string]bool)
+	for _, migration := range migrations {
+		for _, m := range createTable.FindAllStringSubmatch(migration, -1) {
+			columns := make(map[string]bool)
+			for _, line := range strings.Split(m[2], "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 0 || fields[0] == "PRIMARY" || fields[0] == "UNIQUE" {
+					continue
+				}
+				columns[fields[0]] = true
+			}
+			tables[m[1]] = columns
+		}
+		for _, m := range alterTable.FindAllStringSubmatch(migration, -1) {
+			if tables[m[1]] == nil {
+				t.Fatalf("migration alters unknown table %s", m[1])
+			}
+			for _, c := range addColumn.FindAllStringSubmatch(m[2], -1) {
+				tables[m[1]][c[1]] = true
+			}
+		}
+	}
+	return tables
+}
+
+func TestInsertColumns(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{}
+	p, err := NewPostgres(ctx, db, "0xAB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.statements = nil
+
+	event := &events.ContractLogEvent{
+		TxHash:    common.Hash{1},
+		Topics:    []common.Hash{events.ArbitrationRequested, {2}},
+		EventData: []byte{3},
+	}
+	item := &queue.Item{
+		Key:         queue.KeyOf(event),
+		Event:       event,
+		State:       queue.StatePending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		NextAttempt: time.Now(),
+		Deadline:    time.Now(),
+	}
+	receipt := &types.Receipt{TxHash: common.Hash{4}, BlockNumber: big.NewInt(5), Status: 1}
+	for _, err := range []error{
+		p.RecordEvent(ctx, event),
+		p.RecordQueueItem(ctx, item),
+		p.RecordSignature(ctx, item.Key, common.Hash{2}, []byte{6}),
+		p.RecordSubmission(ctx, item.Key, common.Hash{2}, common.Hash{7}, errors.New("reverted")),
+		p.RecordReceipt(ctx, receipt),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables := schemaColumns(t)
+	if len(db.statements) != 5 {
+		t.Fatalf("%d statements", len(db.statements))
+	}
+	for _, s := range db.statements {
+		m := insertInto.FindStringSubmatch(s.query)
+		if m == nil {
+			t.Errorf("not an insert: %s", s.query)
+			continue
+		}
+		table, columns := tables[m[1]], strings.Split(m[2], ",")
+		if table == nil {
+			t.Errorf("insert into unknown table %s", m[1])
+			continue
+		}
+		for _, column := range columns {
+			if column = strings.TrimSpace(column); !table[column] {
+				t.Errorf("insert into %s: no column %s", m[1], column)
+			}
+		}
+		if placeholders := strings.Count(m[3], "?"); placeholders != len(columns) || len(s.args) != len(columns) {
+			t.Errorf("insert into %s: %d columns, %d placeholders, %d arguments", m[1], len(columns), placeholders, len(s.args))
+		}
+		for _, c := range setColumn.FindAllStringSubmatch(m[4], -1) {
+			if !table[c[1]] || !table[c[2]] {
+				t.Errorf("update of %s: no column %s", m[1], c[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2025 The bel2 developers
+
+// Package logging configures the gf loggers of the arbiter: the default
+// logger behind g.Log() and the event log. Both share the output format,
+// the level and the optional syslog sink, and add the fields of the
+// arbitration carried by the context to every line.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/glog"
+	"github.com/gogf/gf/v2/util/gconv"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	// EventLogFile is the operator log of requests, signatures and alerts.
+	EventLogFile = "event.log"
+)
+
+// Config selects how the arbiter logs.
+type Config struct {
+	// glog level: "all", "debug", "info", "notice", "warning", "error" or
+	// "critical", empty means "all"
+	Level string
+	// FormatText or FormatJSON, empty means FormatText
+	Format string
+	// size at which the event log is rotated, like "100M", empty disables
+	// rotation
+	RotateSize string
+	// rotated event logs kept
+	RotateBackups int
+	// syslog destination: "local" for the local syslog or journald socket,
+	// or "udp://host:514" / "tcp://host:514", empty disables it
+	Syslog string
+}
+
+func (c Config) validate() error {
+	switch c.Format {
+	case "", FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q", c.Format)
+	}
+	if c.RotateBackups < 0 {
+		return errors.New("log rotate backups must not be negative")
+	}
+	return nil
+}
+
+// Setup configures the default logger returned by g.Log().
+func Setup(cfg Config) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	h, err := newHandler(cfg, "")
+	if err != nil {
+		return err
+	}
+	logger := g.Log()
+	if err := setLevel(logger, cfg.Level); err != nil {
+		return err
+	}
+	logger.SetHandlers(h.handle)
+	register(logger, h)
+	return nil
+}
+
+// NewEventLog returns the logger writing the event log to dir. It is
+// rotated by size and not printed to stdout.
+func NewEventLog(cfg Config, dir string) (*glog.Logger, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	settings := map[string]interface{}{
+		"path":     dir,
+		"file":     EventLogFile,
+		"stdout":   false,
+		"stStatus": 0,
+	}
+	if cfg.RotateSize != "" {
+		settings["rotateSize"] = cfg.RotateSize
+		settings["rotateBackupLimit"] = cfg.RotateBackups
+		settings["rotateBackupCompress"] = 9
+	}
+	logger := glog.New()
+	if err := logger.SetConfigWithMap(settings); err != nil {
+		return nil, err
+	}
+	if err := setLevel(logger, cfg.Level); err != nil {
+		return nil, err
+	}
+	h, err := newHandler(cfg, "event")
+	if err != nil {
+		return nil, err
+	}
+	logger.SetHandlers(h.handle)
+	register(logger, h)
+	return logger, nil
+}
+
+var (
+	handlersMu sync.Mutex
+	// handlers of the loggers configured by Setup and NewEventLog
+	handlers = make(map[*glog.Logger]*handler)
+)
+
+func register(logger *glog.Logger, h *handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[logger] = h
+}
+
+// Reconfigure changes the level and the format of loggers configured by
+// Setup or NewEventLog while they are in use. The rotation and the syslog
+// sink are fixed once the logger was created.
+func Reconfigure(cfg Config, loggers ...*glog.Logger) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	// check the level before any logger is changed
+	if err := setLevel(glog.New(), cfg.Level); err != nil {
+		return err
+	}
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	for _, logger := range loggers {
+		if _, ok := handlers[logger]; !ok {
+			return errors.New("logger not configured by the logging package")
+		}
+	}
+	for _, logger := range loggers {
+		setLevel(logger, cfg.Level)
+		handlers[logger].format.Store(cfg.Format)
+	}
+	return nil
+}
+
+func setLevel(logger *glog.Logger, level string) error {
+	if level == "" {
+		level = "all"
+	}
+	if err := logger.SetLevelStr(level); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	return nil
+}
+
+type arbitrationKey struct{}
+
+// Arbitration identifies the arbitration a log line is about.
+type Arbitration struct {
+	// arbitration id, the queryId of the contract
+	TxId  string `json:"txId,omitempty"`
+	Dapp  string `json:"dapp,omitempty"`
+	EscTx string `json:"escTx,omitempty"`
+	Block uint64 `json:"block,omitempty"`
+}
+
+// WithArbitration returns a context whose log lines carry the fields of a.
+func WithArbitration(ctx context.Context, a Arbitration) context.Context {
+	return context.WithValue(ctx, arbitrationKey{}, a)
+}
+
+// WithEvent returns a context whose log lines carry the ESC transaction and
+// block of a contract event, and its txId and dapp for the events that
+// index them.
+func WithEvent(ctx context.Context, event *events.ContractLogEvent) context.Context {
+	a := Arbitration{EscTx: event.TxHash.String(), Block: event.Block}
+	if len(event.Topics) > 2 {
+		switch event.Topics[0] {
+		case events.ArbitrationRequested, events.TransactionCompleted, events.TransactionRegistered:
+			a.TxId = event.Topics[1].String()
+			a.Dapp = common.BytesToAddress(event.Topics[2].Bytes()).String()
+		}
+	}
+	return WithArbitration(ctx, a)
+}
+
+// ArbitrationFrom returns the arbitration fields carried by ctx.
+func ArbitrationFrom(ctx context.Context) (Arbitration, bool) {
+	if ctx == nil {
+		return Arbitration{}, false
+	}
+	a, ok := ctx.Value(arbitrationKey{}).(Arbitration)
+	return a, ok
+}
+
+// String formats the fields as key=value pairs for the text format.
+func (a Arbitration) String() string {
+	var fields []string
+	if a.TxId != "" {
+		fields = append(fields, "txId="+a.TxId)
+	}
+	if a.Dapp != "" {
+		fields = append(fields, "dapp="+a.Dapp)
+	}
+	if a.EscTx != "" {
+		fields = append(fields, "escTx="+a.EscTx)
+	}
+	if a.Block != 0 {
+		fields = append(fields, "block="+strconv.FormatUint(a.Block, 10))
+	}
+	return strings.Join(fields, " ")
+}
+
+// entry is one line of the JSON format.
+type entry struct {
+	Time   string `json:"time"`
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"`
+	Msg    string `json:"msg"`
+	Arbitration
+	TraceId string `json:"traceId,omitempty"`
+	Caller  string `json:"caller,omitempty"`
+	Stack   string `json:"stack,omitempty"`
+}
+
+var levelNames = map[int]string{
+	glog.LEVEL_DEBU: "debug",
+	glog.LEVEL_INFO: "info",
+	glog.LEVEL_NOTI: "notice",
+	glog.LEVEL_WARN: "warning",
+	glog.LEVEL_ERRO: "error",
+	glog.LEVEL_CRIT: "critical",
+	glog.LEVEL_PANI: "panic",
+	glog.LEVEL_FATA: "fatal",
+}
+
+type handler struct {
+	format atomic.Value // string
+	name   string
+	syslog syslogWriter
+}
+
+func newHandler(cfg Config, name string) (*handler, error) {
+	h := &handler{name: name}
+	h.format.Store(cfg.Format)
+	if cfg.Syslog != "" {
+		w, err := dialSyslog(cfg.Syslog)
+		if err != nil {
+			return nil, fmt.Errorf("connect syslog %q: %w", cfg.Syslog, err)
+		}
+		h.syslog = w
+	}
+	return h, nil
+}
+
+func (h *handler) handle(ctx context.Context, in *glog.HandlerInput) {
+	a, _ := ArbitrationFrom(ctx)
+	if h.format.Load() == FormatJSON {
+		e := entry{
+			Time:        in.Time.UTC().Format(time.RFC3339Nano),
+			Level:       levelNames[in.Level],
+			Logger:      h.name,
+			Msg:         message(in),
+			Arbitration: a,
+			TraceId:     in.TraceId,
+			Caller:      in.CallerPath,
+			Stack:       in.Stack,
+		}
+		if e.Level == "" {
+			e.Level = strings.ToLower(in.LevelFormat)
+		}
+		line, err := json.Marshal(&e)
+		if err != nil {
+			line, _ = json.Marshal(map[string]string{"level": "error", "msg": "marshal log entry: " + err.Error()})
+		}
+		in.Buffer.Write(line)
+		in.Buffer.WriteByte('\n')
+	} else if fields := a.String(); fields != "" {
+		if in.CtxStr != "" {
+			in.CtxStr += " "
+		}
+		in.CtxStr += fields
+	}
+
+	if h.syslog != nil {
+		line := in.Buffer.String()
+		if line == "" {
+			line = in.String(false)
+		}
+		// syslog adds its own timestamp and drops the trailing newline
+		if err := h.syslog.write(in.Level, strings.TrimRight(line, "\n")); err != nil {
+			fmt.Fprintln(os.Stderr, "write syslog error:", err)
+		}
+	}
+	in.Next(ctx)
+}
+
+// message joins the content and the values of a log call the way the gf
+// text format does.
+func message(in *glog.HandlerInput) string {
+	msg := in.Content
+	for _, v := range in.Values {
+		s := gconv.String(v)
+		if s == "" {
+			continue
+		}
+		if msg != "" && !strings.HasSuffix(msg, "\n") {
+			msg += " "
+		}
+		msg += s
+	}
+	return strings.TrimRight(msg, "\n")
+}
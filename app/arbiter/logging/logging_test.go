@@ -0,0 +1,137 @@
+// Copyright (c) 2025 The bel2 developers
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/os/glog"
+)
+
+func newTestLogger(t *testing.T, format string) (*glog.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	h, err := newHandler(Config{Format: format}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := glog.New()
+	logger.SetStdoutPrint(false)
+	logger.SetWriter(&buf)
+	logger.SetHandlers(h.handle)
+	return logger, &buf
+}
+
+var testArbitration = Arbitration{TxId: "0xaa", Dapp: "0xbb", EscTx: "0xcc", Block: 42}
+
+func TestJSONFormat(t *testing.T) {
+	logger, buf := newTestLogger(t, FormatJSON)
+	ctx := WithArbitration(context.Background(), testArbitration)
+	logger.Warning(ctx, "request failed, retry at", 3, errors.New("nonce too low"))
+
+	var e entry
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, buf.String())
+	}
+	if e.Level != "warning" || e.Logger != "test" || e.Time == "" {
+		t.Fatalf("header: %+v", e)
+	}
+	if e.Msg != "request failed, retry at 3 nonce too low" {
+		t.Fatalf("msg: %q", e.Msg)
+	}
+	if e.Arbitration != testArbitration {
+		t.Fatalf("fields: %+v", e.Arbitration)
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	logger, buf := newTestLogger(t, FormatText)
+	logger.Info(WithArbitration(context.Background(), testArbitration), "SIGN: submitted")
+	line := buf.String()
+	for _, want := range []string{"[INFO]", "txId=0xaa dapp=0xbb escTx=0xcc block=42", "SIGN: submitted"} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("missing %q in %q", want, line)
+		}
+	}
+
+	buf.Reset()
+	logger.Info(context.Background(), "no arbitration")
+	if strings.Contains(buf.String(), "txId=") {
+		t.Fatalf("unexpected fields in %q", buf.String())
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewEventLog(Config{Format: FormatJSON, Level: "notice", RotateSize: "1M", RotateBackups: 2}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithArbitration(context.Background(), Arbitration{TxId: "0xaa"})
+	logger.Info(ctx, "below level")
+	logger.Critical(ctx, "DEADLINE: missed")
+
+	content, err := os.ReadFile(filepath.Join(dir, EventLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %q", content)
+	}
+	var e entry
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Level != "critical" || e.Msg != "DEADLINE: missed" || e.TxId != "0xaa" || e.Stack != "" {
+		t.Fatalf("entry: %+v", e)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	if _, err := NewEventLog(Config{Format: "xml"}, t.TempDir()); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+	if _, err := NewEventLog(Config{Level: "loud"}, t.TempDir()); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewEventLog(Config{Level: "notice"}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(context.Background(), "below level")
+	if err := Reconfigure(Config{Level: "info", Format: FormatJSON}, logger); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(context.Background(), "after reload")
+
+	content, err := os.ReadFile(filepath.Join(dir, EventLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e entry
+	if err := json.Unmarshal(bytes.TrimSpace(content), &e); err != nil {
+		t.Fatalf("not one JSON line: %v\n%s", err, content)
+	}
+	if e.Level != "info" || e.Msg != "after reload" {
+		t.Fatalf("entry: %+v", e)
+	}
+
+	if err := Reconfigure(Config{Level: "loud"}, logger); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+	if err := Reconfigure(Config{}, glog.New()); err == nil {
+		t.Fatal("unregistered logger accepted")
+	}
+}
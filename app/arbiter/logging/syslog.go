@@ -0,0 +1,65 @@
+// Copyright (c) 2025 The bel2 developers
+
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+
+	"github.com/gogf/gf/v2/os/glog"
+)
+
+const syslogTag = "arbiter"
+
+type syslogWriter interface {
+	write(level int, msg string) error
+}
+
+type unixSyslog struct {
+	w *syslog.Writer
+}
+
+// dialSyslog connects to the local syslog, which journald also serves, or
+// to a remote "udp://host:port" or "tcp://host:port" collector.
+func dialSyslog(target string) (syslogWriter, error) {
+	var (
+		w   *syslog.Writer
+		err error
+	)
+	if target == "local" {
+		w, err = syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, syslogTag)
+	} else {
+		u, perr := url.Parse(target)
+		if perr != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog target %q, want local, udp://host:port or tcp://host:port", target)
+		}
+		w, err = syslog.Dial(u.Scheme, u.Host, syslog.LOG_INFO|syslog.LOG_DAEMON, syslogTag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &unixSyslog{w: w}, nil
+}
+
+func (s *unixSyslog) write(level int, msg string) error {
+	switch level {
+	case glog.LEVEL_DEBU:
+		return s.w.Debug(msg)
+	case glog.LEVEL_INFO:
+		return s.w.Info(msg)
+	case glog.LEVEL_NOTI:
+		return s.w.Notice(msg)
+	case glog.LEVEL_WARN:
+		return s.w.Warning(msg)
+	case glog.LEVEL_ERRO:
+		return s.w.Err(msg)
+	case glog.LEVEL_CRIT:
+		return s.w.Crit(msg)
+	case glog.LEVEL_PANI, glog.LEVEL_FATA:
+		return s.w.Emerg(msg)
+	}
+	return s.w.Info(msg)
+}
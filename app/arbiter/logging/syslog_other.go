@@ -0,0 +1,15 @@
+// Copyright (c) 2025 The bel2 developers
+
+//go:build windows || plan9
+
+package logging
+
+import "errors"
+
+type syslogWriter interface {
+	write(level int, msg string) error
+}
+
+func dialSyslog(target string) (syslogWriter, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
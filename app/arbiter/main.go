@@ -5,38 +5,46 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/ethereum/go-ethereum/common"
 	_ "github.com/gogf/gf/contrib/drivers/pgsql/v2"
 	"github.com/gogf/gf/os/gfile"
+	"github.com/gogf/gf/v2/container/gvar"
 	"github.com/gogf/gf/v2/frame/g"
-	"github.com/gogf/gf/v2/os/gcfg"
-	"github.com/gogf/gf/v2/os/gctx"
 
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/alert"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/api/btcsource"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
 	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
 	"golang.org/x/term"
 )
 
+// ConfigFile is the config written by the setup. The settings of the
+// network profile are only written when they differ from it.
 type ConfigFile struct {
 	Chain struct {
-		Esc string `yaml:"esc"`
+		Esc string `yaml:"esc,omitempty"`
 	} `yaml:"chain"`
 	Arbiter struct {
 		Listener                         bool   `yaml:"listener"`
 		Signer                           bool   `yaml:"signer"`
 		Network                          string `yaml:"network"`
-		EscStartHeight                   uint64 `yaml:"escStartHeight"`
-		EscArbiterContractAddress        string `yaml:"escArbiterContractAddress"`
-		EscArbiterManagerContractAddress string `yaml:"escArbiterManagerContractAddress"`
+		EscStartHeight                   uint64 `yaml:"escStartHeight,omitempty"`
+		EscArbiterContractAddress        string `yaml:"escArbiterContractAddress,omitempty"`
+		EscArbiterManagerContractAddress string `yaml:"escArbiterManagerContractAddress,omitempty"`
 		DataPath                         string `yaml:"dataPath"`
 		KeyFilePath                      string `yaml:"keyFilePath"`
 		EscArbiterAddress                string `yaml:"escArbiterAddress"`
@@ -47,13 +55,9 @@ type ConfigFile struct {
 
 func getDefaultConfig() ConfigFile {
 	var cfg ConfigFile
-	cfg.Chain.Esc = "https://api.elastos.io/esc"
 	cfg.Arbiter.Listener = true
 	cfg.Arbiter.Signer = true
 	cfg.Arbiter.Network = "mainnet"
-	cfg.Arbiter.EscStartHeight = 28437808
-	cfg.Arbiter.EscArbiterContractAddress = "0xA10b92006743Ef3B12077da67e465963743b03D3"
-	cfg.Arbiter.EscArbiterManagerContractAddress = "0x9963b5214434776D043A4e98Bc7f33321F6aaCfc"
 	
 	// Get executable directory for portable paths
 	execPath, err := os.Executable()
@@ -71,17 +75,19 @@ func getDefaultConfig() ConfigFile {
 	return cfg
 }
 
-func setupConfig() error {
-	// Get executable directory
-	execPath, err := os.Executable()
-	if err != nil {
-		execPath = "."
+// setupConfig prompts for the settings and private keys and writes the
+// config file to configPath, next to the executable if empty.
+func setupConfig(configPath string) error {
+	if configPath == "" {
+		// Get executable directory
+		execPath, err := os.Executable()
+		if err != nil {
+			execPath = "."
+		}
+		configPath = filepath.Join(filepath.Dir(execPath), configFileName)
 	}
-	execDir := filepath.Dir(execPath)
-	
-	// Use absolute path for config
-	configPath := filepath.Join(execDir, "config.yaml")
-	
+	configPath = getExpandedPath(configPath)
+
 	fmt.Printf("Looking for config file at: %s\n", configPath)
 	
 	// Check if config already exists and warn user
@@ -96,6 +102,24 @@ func setupConfig() error {
 
 	fmt.Println("\nPress Enter to keep the default value, or type a new value:")
 
+	// the network profile provides the defaults of the chain settings
+	var profile config.Profile
+	for {
+		fmt.Printf("Network, one of %s [%s]: ", strings.Join(config.ProfileNames(), ", "), cfg.Arbiter.Network)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			cfg.Arbiter.Network = strings.TrimSpace(input)
+		}
+		var err error
+		if profile, err = config.GetProfile(cfg.Arbiter.Network); err == nil {
+			break
+		}
+		fmt.Println("Error:", err)
+	}
+	cfg.Chain.Esc = profile.ESCRPC
+	cfg.Arbiter.EscStartHeight = profile.ESCStartHeight
+	cfg.Arbiter.EscArbiterContractAddress = profile.ESCArbiterContractAddress
+	cfg.Arbiter.EscArbiterManagerContractAddress = profile.ESCArbiterManagerContractAddress
+
 	// Chain configuration
 	fmt.Printf("ESC Chain URL [%s]: ", cfg.Chain.Esc)
 	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
@@ -113,11 +137,6 @@ func setupConfig() error {
 		cfg.Arbiter.Signer = strings.ToLower(strings.TrimSpace(input)) == "true"
 	}
 
-	fmt.Printf("Network [%s]: ", cfg.Arbiter.Network)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		cfg.Arbiter.Network = strings.TrimSpace(input)
-	}
-
 	fmt.Printf("ESC Start Height [%d]: ", cfg.Arbiter.EscStartHeight)
 	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
 		if height, err := strconv.ParseUint(strings.TrimSpace(input), 10, 64); err == nil {
@@ -125,14 +144,27 @@ func setupConfig() error {
 		}
 	}
 
-	fmt.Printf("ESC Arbiter Contract Address [%s]: ", cfg.Arbiter.EscArbiterContractAddress)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		cfg.Arbiter.EscArbiterContractAddress = strings.TrimSpace(input)
+	// networks without a well known deployment have no default
+	for {
+		fmt.Printf("ESC Arbiter Contract Address [%s]: ", cfg.Arbiter.EscArbiterContractAddress)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			cfg.Arbiter.EscArbiterContractAddress = strings.TrimSpace(input)
+		}
+		if cfg.Arbiter.EscArbiterContractAddress != "" {
+			break
+		}
+		fmt.Println("Error: network", profile.Name, "has no default, enter the address")
 	}
 
-	fmt.Printf("ESC Arbiter Manager Contract Address [%s]: ", cfg.Arbiter.EscArbiterManagerContractAddress)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		cfg.Arbiter.EscArbiterManagerContractAddress = strings.TrimSpace(input)
+	for {
+		fmt.Printf("ESC Arbiter Manager Contract Address [%s]: ", cfg.Arbiter.EscArbiterManagerContractAddress)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			cfg.Arbiter.EscArbiterManagerContractAddress = strings.TrimSpace(input)
+		}
+		if cfg.Arbiter.EscArbiterManagerContractAddress != "" {
+			break
+		}
+		fmt.Println("Error: network", profile.Name, "has no default, enter the address")
 	}
 
 	fmt.Printf("Data Path [%s]: ", cfg.Arbiter.DataPath)
@@ -150,6 +182,20 @@ func setupConfig() error {
 		cfg.Arbiter.EscArbiterAddress = strings.TrimSpace(input)
 	}
 
+	// keep the profile defaults out of the file, they follow the release
+	if cfg.Chain.Esc == profile.ESCRPC {
+		cfg.Chain.Esc = ""
+	}
+	if cfg.Arbiter.EscStartHeight == profile.ESCStartHeight {
+		cfg.Arbiter.EscStartHeight = 0
+	}
+	if cfg.Arbiter.EscArbiterContractAddress == profile.ESCArbiterContractAddress {
+		cfg.Arbiter.EscArbiterContractAddress = ""
+	}
+	if cfg.Arbiter.EscArbiterManagerContractAddress == profile.ESCArbiterManagerContractAddress {
+		cfg.Arbiter.EscArbiterManagerContractAddress = ""
+	}
+
 	// Create directories
 	os.MkdirAll(cfg.Arbiter.DataPath, 0755)
 	os.MkdirAll(cfg.Arbiter.KeyFilePath, 0755)
@@ -190,12 +236,8 @@ func createKeyFiles(cfg ConfigFile) error {
 		escKey = strings.TrimSpace(string(keyBytes))
 		
 		// Validate hex format and length
-		if len(escKey) != 64 {
-			fmt.Println("Error: Private key must be exactly 64 hex characters")
-			continue
-		}
-		if _, err := hex.DecodeString(escKey); err != nil {
-			fmt.Println("Error: Private key must be in hex format")
+		if err := validatePrivateKey(escKey); err != nil {
+			fmt.Println("Error:", err)
 			continue
 		}
 		break
@@ -227,12 +269,8 @@ func createKeyFiles(cfg ConfigFile) error {
 		btcKey = strings.TrimSpace(string(keyBytes))
 		
 		// Validate hex format and length
-		if len(btcKey) != 64 {
-			fmt.Println("Error: Private key must be exactly 64 hex characters")
-			continue
-		}
-		if _, err := hex.DecodeString(btcKey); err != nil {
-			fmt.Println("Error: Private key must be in hex format")
+		if err := validatePrivateKey(btcKey); err != nil {
+			fmt.Println("Error:", err)
 			continue
 		}
 		break
@@ -248,8 +286,9 @@ func createKeyFiles(cfg ConfigFile) error {
 }
 
 func main() {
-	if len(os.Args) > 1 {
-		operation := os.Args[1]
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		operation := args[0]
 		switch strings.ToLower(operation) {
 		case "getpk", "gen":
 			myPriKey := os.Args[2]
@@ -260,93 +299,325 @@ func main() {
 			}
 			fmt.Println("publicKey:", pk)
 			return
+		case "admin":
+			if err := runAdmin(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					return
+				}
+				fmt.Println("admin failed:", err)
+				os.Exit(1)
+			}
+			return
+		case "approvals":
+			if err := runApprovals(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					return
+				}
+				fmt.Println("approvals:", err)
+				os.Exit(1)
+			}
+			return
+		case "convert":
+			if err := runConvert(os.Args[2:]); err != nil {
+				fmt.Println("convert failed:", err)
+				os.Exit(1)
+			}
+			return
+		case "init":
+			if err := runInit(os.Args[2:]); err != nil {
+				fmt.Println("init failed:", err)
+				os.Exit(1)
+			}
+			return
+		case "doctor":
+			if err := runDoctor(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					return
+				}
+				fmt.Println("doctor:", err)
+				os.Exit(1)
+			}
+			return
+		case "export-request", "offline-sign", "import-signature":
+			run := map[string]func([]string) error{
+				"export-request":   runExportRequest,
+				"offline-sign":     runOfflineSign,
+				"import-signature": runImportSignature,
+			}[strings.ToLower(operation)]
+			if err := run(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					return
+				}
+				fmt.Println(operation+":", err)
+				os.Exit(1)
+			}
+			return
+		case "run":
+			args = os.Args[2:]
+		default:
+			fmt.Println("unknown command:", operation)
+			fmt.Println("commands: init, run, doctor, admin, approvals, convert, getpk, export-request, offline-sign, import-signature")
+			os.Exit(1)
+		}
+	} else if len(args) == 0 && interactive() {
+		// first start from a terminal without a config: set it up first
+		if _, err := findConfigFile(""); err != nil {
+			if err := setupConfig(""); err != nil {
+				fmt.Printf("Setup failed: %v\n", err)
+				os.Exit(1)
+			}
 		}
 	}
 
-	// Run setup first
-	if err := setupConfig(); err != nil {
-		fmt.Printf("Setup failed: %v\n", err)
+	if err := runArbiter(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Println("run failed:", err)
 		os.Exit(1)
 	}
-
-	ctx := gctx.New()
-	var wg sync.WaitGroup
-	wg.Add(1)
-	
-	// Initialize config file path for gf framework
-	g.Cfg().GetAdapter().(*gcfg.AdapterFile).SetPath(".")
-	
-	// start arbiter
-	g.Log().Info(ctx, "Starting arbiter...")
-	arb := arbiter.NewArbiter(ctx, initConfig(ctx))
-	arb.Start()
-	wg.Wait()
 }
 
+// initConfig loads the config and logs it, or exits if it is invalid.
 func initConfig(ctx context.Context) *config.Config {
-	network, err := g.Cfg().Get(ctx, "arbiter.network")
+	cfg, err := loadConfig(ctx)
 	if err != nil {
-		g.Log().Error(ctx, "get network config err:", err)
+		g.Log().Error(ctx, "load config err:", err)
 		os.Exit(1)
 	}
+	logConfig(ctx, cfg)
+	return cfg
+}
+
+func logConfig(ctx context.Context, cfg *config.Config) {
+	g.Log().Info(ctx, "btcCreator:", cfg.Signer)
+	g.Log().Info(ctx, "listener:", cfg.Listener)
+	g.Log().Info(ctx, "dryRun:", cfg.DryRun)
+	g.Log().Info(ctx, "network:", cfg.Network)
+	g.Log().Info(ctx, "http:", cfg.Http, "chainId:", cfg.ESCChainID)
+	g.Log().Info(ctx, "escStartHeight:", cfg.ESCStartHeight)
+	g.Log().Info(ctx, "escArbiterContractAddress:", cfg.ESCArbiterContractAddress)
+	g.Log().Info(ctx, "escArbiterManagerAddress:", cfg.ESCArbiterManagerContractAddress)
+	g.Log().Info(ctx, "escArbiterAddress:", cfg.ESCArbiterAddress)
+	g.Log().Info(ctx, "dataPath:", cfg.DataDir)
+	g.Log().Info(ctx, "keyFilePath:", filepath.Dir(cfg.EscKeyFilePath))
+	g.Log().Info(ctx, "feeClaim:", cfg.FeeClaim)
+	g.Log().Info(ctx, "feeClaimGasBudget:", cfg.FeeClaimGasBudget)
+	g.Log().Info(ctx, "deadlineThresholds:", cfg.DeadlineThresholds)
+	g.Log().Info(ctx, "storage:", cfg.Storage)
+	g.Log().Info(ctx, "btcApi:", cfg.BTCAPI, "proxy set:", cfg.Proxy != "", "btcApiCacheSize:", cfg.BTCAPICacheSize)
+	g.Log().Info(ctx, "btcSource:", cfg.BTCSource, "electrumAddr:", cfg.ElectrumAddr, "bitcoindUrl set:", cfg.BitcoindURL != "")
+	g.Log().Info(ctx, "signWorkers:", cfg.SignWorkers)
+	g.Log().Info(ctx, "signTimeout:", cfg.SignTimeout)
+	g.Log().Info(ctx, "monitorAddr:", cfg.MonitorAddr)
+	g.Log().Info(ctx, "readyMaxLag:", cfg.ReadyMaxLag)
+	g.Log().Info(ctx, "adminApiAddr:", cfg.AdminAPIAddr)
+	g.Log().Info(ctx, "adminApiToken set:", cfg.AdminAPIToken != "")
+	g.Log().Info(ctx, "logLevel:", cfg.Log.Level, "logFormat:", cfg.Log.Format, "syslog:", cfg.Log.Syslog)
+	g.Log().Info(ctx, "logRotateSize:", cfg.Log.RotateSize, "logRotateBackups:", cfg.Log.RotateBackups)
+	g.Log().Info(ctx, "alert notifiers:", len(cfg.Alert.Notifiers()), "lowBalance:", cfg.AlertLowBalance)
+	g.Log().Info(ctx, "approval all:", cfg.Approval.All, "dapps:", cfg.Approval.Dapps, "minValue:", cfg.Approval.MinValue,
+		"autoApproveMargin:", cfg.Approval.AutoApproveMargin)
+}
+
+// loadConfig reads the config from the gf configuration.
+func loadConfig(ctx context.Context) (*config.Config, error) {
+	network, err := g.Cfg().Get(ctx, "arbiter.network", "mainnet")
+	if err != nil {
+		return nil, fmt.Errorf("get network config: %w", err)
+	}
+	profile, err := config.GetProfile(network.String())
+	if err != nil {
+		return nil, err
+	}
 	signer, err := g.Cfg().Get(ctx, "arbiter.signer")
 	if err != nil {
-		g.Log().Error(ctx, "get signer err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get signer: %w", err)
 	}
 	listener, err := g.Cfg().Get(ctx, "arbiter.listener")
 	if err != nil {
-		g.Log().Error(ctx, "get listener config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get listener config: %w", err)
 	}
-	http, err := g.Cfg().Get(ctx, "chain.esc")
+	dryRun, err := g.Cfg().Get(ctx, "arbiter.dryRun", false)
 	if err != nil {
-		g.Log().Error(ctx, "get http config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get dryRun config: %w", err)
 	}
-	escStartHeight, err := g.Cfg().Get(ctx, "arbiter.escStartHeight")
+	http, err := getOrDefault(ctx, "chain.esc", profile.ESCRPC)
 	if err != nil {
-		g.Log().Error(ctx, "get escStartHeight config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get http config: %w", err)
 	}
-	escArbiterContractAddress, err := g.Cfg().Get(ctx, "arbiter.escArbiterContractAddress")
+	// the chain id of the profile only holds for its endpoint, an endpoint
+	// of its own is checked against chain.escChainId alone
+	defaultChainId := profile.ESCChainID
+	if http.String() != profile.ESCRPC {
+		defaultChainId = 0
+	}
+	escChainId, err := getOrDefault(ctx, "chain.escChainId", defaultChainId)
 	if err != nil {
-		g.Log().Error(ctx, "get escArbiterAddress config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get escChainId config: %w", err)
 	}
-	escArbiterManagerAddress, err := g.Cfg().Get(ctx, "arbiter.escArbiterManagerContractAddress")
+	escStartHeight, err := getOrDefault(ctx, "arbiter.escStartHeight", profile.ESCStartHeight)
 	if err != nil {
-		g.Log().Error(ctx, "get escArbiterManagerAddress config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get escStartHeight config: %w", err)
+	}
+	escArbiterContractAddress, err := getOrDefault(ctx, "arbiter.escArbiterContractAddress", profile.ESCArbiterContractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("get escArbiterAddress config: %w", err)
+	}
+	escArbiterManagerAddress, err := getOrDefault(ctx, "arbiter.escArbiterManagerContractAddress", profile.ESCArbiterManagerContractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("get escArbiterManagerAddress config: %w", err)
+	}
+	for _, address := range []struct {
+		key   string
+		value string
+	}{
+		{"escArbiterContractAddress", escArbiterContractAddress.String()},
+		{"escArbiterManagerContractAddress", escArbiterManagerAddress.String()},
+	} {
+		if address.value == "" {
+			return nil, fmt.Errorf("%s not configured, network %s has no default", address.key, profile.Name)
+		}
+		if !common.IsHexAddress(address.value) {
+			return nil, fmt.Errorf("invalid %s %q", address.key, address.value)
+		}
+	}
+	btcApi, err := getOrDefault(ctx, "arbiter.btcApi", profile.BTCAPI)
+	if err != nil {
+		return nil, fmt.Errorf("get btcApi config: %w", err)
+	}
+	proxy, err := g.Cfg().Get(ctx, "arbiter.proxy", "")
+	if err != nil {
+		return nil, fmt.Errorf("get proxy config: %w", err)
+	}
+	btcApiCacheSize, err := g.Cfg().Get(ctx, "arbiter.btcApiCacheSize", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("get btcApiCacheSize config: %w", err)
+	}
+	btcSource, err := g.Cfg().Get(ctx, "arbiter.btcSource", btcsource.Esplora)
+	if err != nil {
+		return nil, fmt.Errorf("get btcSource config: %w", err)
+	}
+	bitcoindUrl, err := g.Cfg().Get(ctx, "arbiter.bitcoindUrl", "")
+	if err != nil {
+		return nil, fmt.Errorf("get bitcoindUrl config: %w", err)
+	}
+	bitcoindCookieFile, err := g.Cfg().Get(ctx, "arbiter.bitcoindCookieFile", "")
+	if err != nil {
+		return nil, fmt.Errorf("get bitcoindCookieFile config: %w", err)
+	}
+	electrumAddr, err := g.Cfg().Get(ctx, "arbiter.electrumAddr", "")
+	if err != nil {
+		return nil, fmt.Errorf("get electrumAddr config: %w", err)
 	}
 	escArbiterAddress, err := g.Cfg().Get(ctx, "arbiter.escArbiterAddress")
 	if err != nil {
-		g.Log().Error(ctx, "get escArbiterAddress config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get escArbiterAddress config: %w", err)
 	}
 	gDataPath, err := g.Cfg().Get(ctx, "arbiter.dataPath")
 	if err != nil {
-		g.Log().Error(ctx, "get dataPath config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get dataPath config: %w", err)
 	}
 	gKeyFilePath, err := g.Cfg().Get(ctx, "arbiter.keyFilePath")
 	if err != nil {
-		g.Log().Error(ctx, "get keyFilePath config err:", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("get keyFilePath config: %w", err)
+	}
+	feeClaim, err := g.Cfg().Get(ctx, "arbiter.feeClaim", true)
+	if err != nil {
+		return nil, fmt.Errorf("get feeClaim config: %w", err)
+	}
+	feeClaimGasBudget, err := g.Cfg().Get(ctx, "arbiter.feeClaimGasBudget", "")
+	if err != nil {
+		return nil, fmt.Errorf("get feeClaimGasBudget config: %w", err)
+	}
+	var gasBudget *big.Int
+	if feeClaimGasBudget.String() != "" {
+		var ok bool
+		gasBudget, ok = new(big.Int).SetString(feeClaimGasBudget.String(), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid feeClaimGasBudget %q, need wei amount", feeClaimGasBudget)
+		}
+	}
+	storage, err := g.Cfg().Get(ctx, "arbiter.storage", "file")
+	if err != nil {
+		return nil, fmt.Errorf("get storage config: %w", err)
+	}
+	gDeadlineThresholds, err := g.Cfg().Get(ctx, "arbiter.deadlineThresholds", "6h,1h,15m")
+	if err != nil {
+		return nil, fmt.Errorf("get deadlineThresholds config: %w", err)
+	}
+	deadlineThresholds, err := parseDurations(gDeadlineThresholds.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid deadlineThresholds config: %w", err)
+	}
+	signWorkers, err := g.Cfg().Get(ctx, "arbiter.signWorkers", 4)
+	if err != nil {
+		return nil, fmt.Errorf("get signWorkers config: %w", err)
+	}
+	if signWorkers.Int() < 1 {
+		return nil, fmt.Errorf("invalid signWorkers config %q, need at least 1", signWorkers)
+	}
+	gSignTimeout, err := g.Cfg().Get(ctx, "arbiter.signTimeout", "2m")
+	if err != nil {
+		return nil, fmt.Errorf("get signTimeout config: %w", err)
+	}
+	signTimeout, err := time.ParseDuration(gSignTimeout.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid signTimeout config: %w", err)
+	}
+	if signTimeout <= 0 {
+		return nil, fmt.Errorf("invalid signTimeout config %q, need a positive duration", gSignTimeout)
+	}
+	monitorAddr, err := g.Cfg().Get(ctx, "arbiter.monitorAddr", "127.0.0.1:9180")
+	if err != nil {
+		return nil, fmt.Errorf("get monitorAddr config: %w", err)
+	}
+	readyMaxLag, err := g.Cfg().Get(ctx, "arbiter.readyMaxLag", 50)
+	if err != nil {
+		return nil, fmt.Errorf("get readyMaxLag config: %w", err)
+	}
+	adminApiAddr, err := g.Cfg().Get(ctx, "arbiter.adminApiAddr", "127.0.0.1:9181")
+	if err != nil {
+		return nil, fmt.Errorf("get adminApiAddr config: %w", err)
+	}
+	adminApiToken, err := g.Cfg().Get(ctx, "arbiter.adminApiToken", "")
+	if err != nil {
+		return nil, fmt.Errorf("get adminApiToken config: %w", err)
+	}
+	if err := arbiter.ValidateAdminAPI(adminApiAddr.String(), adminApiToken.String()); err != nil {
+		return nil, fmt.Errorf("invalid adminApiAddr config: %w", err)
+	}
+	logLevel, err := g.Cfg().Get(ctx, "arbiter.logLevel", "all")
+	if err != nil {
+		return nil, fmt.Errorf("get logLevel config: %w", err)
+	}
+	logFormat, err := g.Cfg().Get(ctx, "arbiter.logFormat", logging.FormatText)
+	if err != nil {
+		return nil, fmt.Errorf("get logFormat config: %w", err)
+	}
+	logRotateSize, err := g.Cfg().Get(ctx, "arbiter.logRotateSize", "100M")
+	if err != nil {
+		return nil, fmt.Errorf("get logRotateSize config: %w", err)
+	}
+	logRotateBackups, err := g.Cfg().Get(ctx, "arbiter.logRotateBackups", 10)
+	if err != nil {
+		return nil, fmt.Errorf("get logRotateBackups config: %w", err)
+	}
+	syslogTarget, err := g.Cfg().Get(ctx, "arbiter.syslog", "")
+	if err != nil {
+		return nil, fmt.Errorf("get syslog config: %w", err)
+	}
+	approval, err := loadApprovalConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alertConfig, alertLowBalance, err := loadAlertConfig(ctx)
+	if err != nil {
+		return nil, err
 	}
 	dataPath := getExpandedPath(gDataPath.String())
 	keyFilePath := getExpandedPath(gKeyFilePath.String())
 
-	g.Log().Info(ctx, "btcCreator:", signer)
-	g.Log().Info(ctx, "listener:", listener)
-	g.Log().Info(ctx, "http:", http)
-	g.Log().Info(ctx, "escStartHeight:", escStartHeight)
-	g.Log().Info(ctx, "escArbiterContractAddress:", escArbiterContractAddress)
-	g.Log().Info(ctx, "escArbiterManagerAddress:", escArbiterManagerAddress)
-	g.Log().Info(ctx, "escArbiterAddress:", escArbiterAddress)
-	g.Log().Info(ctx, "dataPath:", dataPath)
-	g.Log().Info(ctx, "keyFilePath:", keyFilePath)
-
 	// if want to submit to ESC contract successfully, need to use esc ela as gas.
 	escKeyFilePath := gfile.Join(keyFilePath, "escKey.json")
 	arbiterKeyFilePath := gfile.Join(keyFilePath, "btcKey.json")
@@ -355,13 +626,21 @@ func initConfig(ctx context.Context) *config.Config {
 	loanNeedSignReqPath := gfile.Join(loanPath, "request/")
 	loanNeedSignFailedPath := gfile.Join(loanPath, "failed/")
 	loanNeedSignSignedPath := gfile.Join(loanPath, "signed/")
+	queueDBPath := gfile.Join(loanPath, "queue.db")
+	loanImportedPath := gfile.Join(loanPath, "imported/")
 	LoanSignedEventPath := gfile.Join(dataPath, "loan_signed_event/")
+	loanCompletedEventPath := gfile.Join(dataPath, "loan_completed_event/")
+	loanFeeClaimedPath := gfile.Join(loanPath, "claimed/")
+	revenueLedgerPath := gfile.Join(dataPath, "revenue_ledger.jsonl")
+	dryRunReportPath := gfile.Join(dataPath, "dry_run_report.jsonl")
 
-	return &config.Config{
-		Network:                          network.String(),
+	cfg := &config.Config{
+		Network:                          profile.Name,
 		Signer:                           signer.Bool(),
 		Listener:                         listener.Bool(),
+		DryRun:                           dryRun.Bool(),
 		Http:                             http.String(),
+		ESCChainID:                       escChainId.Int64(),
 		ESCStartHeight:                   escStartHeight.Uint64(),
 		ESCArbiterContractAddress:        escArbiterContractAddress.String(),
 		ESCArbiterManagerContractAddress: escArbiterManagerAddress.String(),
@@ -376,7 +655,176 @@ func initConfig(ctx context.Context) *config.Config {
 		LoanNeedSignSignedPath: loanNeedSignSignedPath,
 		LoanSignedEventPath:    LoanSignedEventPath,
 		LoanLogPath:            logPath,
+		QueueDBPath:            queueDBPath,
+		LoanImportedPath:       loanImportedPath,
+		LoanCompletedEventPath: loanCompletedEventPath,
+		LoanFeeClaimedPath:     loanFeeClaimedPath,
+		RevenueLedgerPath:      revenueLedgerPath,
+		DryRunReportPath:       dryRunReportPath,
+
+		FeeClaim:          feeClaim.Bool(),
+		FeeClaimGasBudget: gasBudget,
+
+		DeadlineThresholds: deadlineThresholds,
+
+		Storage: storage.String(),
+
+		BTCParams: profile.BTCParams,
+		BTCAPI:    btcApi.String(),
+
+		Proxy:           proxy.String(),
+		BTCAPICacheSize: btcApiCacheSize.Int(),
+
+		BTCSource:          btcSource.String(),
+		BitcoindURL:        bitcoindUrl.String(),
+		BitcoindCookieFile: getExpandedPath(bitcoindCookieFile.String()),
+		ElectrumAddr:       electrumAddr.String(),
+
+		SignWorkers: signWorkers.Int(),
+		SignTimeout: signTimeout,
+		MonitorAddr: monitorAddr.String(),
+		ReadyMaxLag: readyMaxLag.Uint64(),
+
+		AdminAPIAddr:  adminApiAddr.String(),
+		AdminAPIToken: adminApiToken.String(),
+
+		Alert:           alertConfig,
+		AlertLowBalance: alertLowBalance,
+
+		Approval: approval,
+
+		Log: logging.Config{
+			Level:         logLevel.String(),
+			Format:        logFormat.String(),
+			RotateSize:    logRotateSize.String(),
+			RotateBackups: logRotateBackups.Int(),
+			Syslog:        syslogTarget.String(),
+		},
+	}
+	// connects lazily, only the settings are checked
+	if _, err := btcsource.New(cfg.BTCSourceConfig()); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
+
+// getOrDefault returns the value of key, or def if it is missing or empty,
+// for the settings that default to the network profile.
+func getOrDefault(ctx context.Context, key string, def interface{}) (*gvar.Var, error) {
+	value, err := g.Cfg().Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsEmpty() {
+		return gvar.New(def), nil
+	}
+	return value, nil
+}
+
+// loadAlertConfig reads the arbiter.alert section.
+func loadAlertConfig(ctx context.Context) (alert.Config, *big.Int, error) {
+	var getErr error
+	get := func(key string, def interface{}) string {
+		value, err := g.Cfg().Get(ctx, "arbiter.alert."+key, def)
+		if err != nil && getErr == nil {
+			getErr = fmt.Errorf("get alert.%s config: %w", key, err)
+		}
+		return value.String()
+	}
+	dedupWindow, err := time.ParseDuration(get("dedupWindow", "1h"))
+	if err != nil || dedupWindow < 0 {
+		return alert.Config{}, nil, fmt.Errorf("invalid alert.dedupWindow config %q", get("dedupWindow", "1h"))
+	}
+	ratePerMinute, err := strconv.Atoi(get("ratePerMinute", 10))
+	if err != nil || ratePerMinute < 0 {
+		return alert.Config{}, nil, fmt.Errorf("invalid alert.ratePerMinute config %q", get("ratePerMinute", 10))
+	}
+	var lowBalance *big.Int
+	if value := get("lowBalance", ""); value != "" {
+		var ok bool
+		lowBalance, ok = new(big.Int).SetString(value, 10)
+		if !ok {
+			return alert.Config{}, nil, fmt.Errorf("invalid alert.lowBalance %q, need wei amount", value)
+		}
+	}
+	var smtpTo []string
+	for _, to := range strings.Split(get("smtpTo", ""), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			smtpTo = append(smtpTo, to)
+		}
+	}
+	cfg := alert.Config{
+		DedupWindow:   dedupWindow,
+		RatePerMinute: ratePerMinute,
+		Webhook:       alert.WebhookConfig{URL: get("webhookUrl", "")},
+		Slack:         alert.SlackConfig{URL: get("slackUrl", "")},
+		Telegram: alert.TelegramConfig{
+			Token:  get("telegramToken", ""),
+			ChatID: get("telegramChatId", ""),
+		},
+		SMTP: alert.SMTPConfig{
+			Addr:     get("smtpAddr", ""),
+			Username: get("smtpUsername", ""),
+			Password: get("smtpPassword", ""),
+			From:     get("smtpFrom", ""),
+			To:       smtpTo,
+		},
+	}
+	if cfg.Telegram.Token != "" && cfg.Telegram.ChatID == "" {
+		return alert.Config{}, nil, errors.New("alert.telegramChatId is required with telegramToken")
+	}
+	if cfg.SMTP.Addr != "" && (cfg.SMTP.From == "" || len(cfg.SMTP.To) == 0) {
+		return alert.Config{}, nil, errors.New("alert.smtpFrom and smtpTo are required with smtpAddr")
+	}
+	if getErr != nil {
+		return alert.Config{}, nil, getErr
+	}
+	return cfg, lowBalance, nil
+}
+
+// loadApprovalConfig reads the arbiter.approval section.
+func loadApprovalConfig(ctx context.Context) (config.ApprovalConfig, error) {
+	var getErr error
+	get := func(key string, def interface{}) string {
+		value, err := g.Cfg().Get(ctx, "arbiter.approval."+key, def)
+		if err != nil && getErr == nil {
+			getErr = fmt.Errorf("get approval.%s config: %w", key, err)
+		}
+		return value.String()
+	}
+	var cfg config.ApprovalConfig
+	cfg.All = get("all", false) == "true"
+	// a YAML list or a comma separated string
+	dapps, err := g.Cfg().Get(ctx, "arbiter.approval.dapps")
+	if err != nil {
+		return config.ApprovalConfig{}, fmt.Errorf("get approval.dapps config: %w", err)
+	}
+	for _, value := range dapps.Strings() {
+		for _, dapp := range strings.Split(value, ",") {
+			if dapp = strings.TrimSpace(dapp); dapp == "" {
+				continue
+			}
+			if !common.IsHexAddress(dapp) {
+				return config.ApprovalConfig{}, fmt.Errorf("invalid approval.dapps address %q", dapp)
+			}
+			cfg.Dapps = append(cfg.Dapps, dapp)
+		}
+	}
+	minValue, err := strconv.ParseInt(get("minValue", 0), 10, 64)
+	if err != nil || minValue < 0 {
+		return config.ApprovalConfig{}, fmt.Errorf("invalid approval.minValue config %q, need satoshis", get("minValue", 0))
+	}
+	cfg.MinValue = minValue
+	if value := get("autoApproveMargin", ""); value != "" {
+		cfg.AutoApproveMargin, err = time.ParseDuration(value)
+		if err != nil || cfg.AutoApproveMargin < 0 {
+			return config.ApprovalConfig{}, fmt.Errorf("invalid approval.autoApproveMargin config %q", value)
+		}
+	}
+	if getErr != nil {
+		return config.ApprovalConfig{}, getErr
+	}
+	return cfg, nil
 }
 
 func getExpandedPath(path string) string {
@@ -390,3 +838,22 @@ func getExpandedPath(path string) string {
 	}
 	return path
 }
+
+// parseDurations parses a comma separated duration list and sorts it in
+// descending order.
+func parseDurations(value string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] > durations[j] })
+	return durations, nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 The bel2 developers
+
+// Package metrics holds the Prometheus metrics of the arbiter. They are
+// registered on Registry and served on the monitor address.
+package metrics
+
+import (
+	"math"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "arbiter"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	StartTime = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "start_time_seconds",
+		Help:      "Unix time the arbiter started.",
+	})
+
+	ListenerHeight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "listener_height",
+		Help:      "Last ESC block scanned by the listener.",
+	})
+	ChainHead = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_head",
+		Help:      "Latest ESC block seen from the RPC node.",
+	})
+	ListenerLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "listener_lag_blocks",
+		Help:      "Blocks between the chain head and the last scanned block.",
+	})
+	EventsSeen = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_total",
+		Help:      "Contract events seen by the listener, by event.",
+	}, []string{"event"})
+
+	QueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_requests",
+		Help:      "Arbitration requests in the queue, by state.",
+	}, []string{"state"})
+	SignDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sign_duration_seconds",
+		Help:      "Time from picking up a request to its submission on ESC.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 30, 60, 120, 300},
+	})
+	Submissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submissions_total",
+		Help:      "Arbitration signature submissions, by result.",
+	}, []string{"result"})
+	GasSpent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gas_spent_wei_total",
+		Help:      "ESC gas cost of mined transactions sent by the operator, by kind.",
+	}, []string{"kind"})
+	OperatorBalance = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "operator_balance_wei",
+		Help:      "ESC balance of the operator account.",
+	})
+	NearestDeadline = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "nearest_deadline_seconds",
+		Help:      "Seconds until the nearest known deadline of an open request, negative if missed, +Inf if none.",
+	})
+)
+
+const (
+	SubmissionSucceeded = "success"
+	SubmissionFailed    = "failure"
+
+	GasSignature = "signature"
+	GasFeeClaim  = "fee_claim"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		StartTime,
+		ListenerHeight,
+		ChainHead,
+		ListenerLag,
+		EventsSeen,
+		QueueDepth,
+		SignDuration,
+		Submissions,
+		GasSpent,
+		OperatorBalance,
+		NearestDeadline,
+	)
+	StartTime.Set(float64(time.Now().Unix()))
+	NearestDeadline.Set(math.Inf(1))
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// SetListener records the scanned height against the chain head.
+func SetListener(height, head uint64) {
+	ListenerHeight.Set(float64(height))
+	ChainHead.Set(float64(head))
+	if head > height {
+		ListenerLag.Set(float64(head - height))
+	} else {
+		ListenerLag.Set(0)
+	}
+}
+
+// AddGas adds the cost of a mined transaction, gasUsed times gasPrice.
+func AddGas(kind string, gasUsed uint64, gasPrice *big.Int) {
+	if gasPrice == nil {
+		return
+	}
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasUsed))
+	f, _ := new(big.Float).SetInt(cost).Float64()
+	GasSpent.WithLabelValues(kind).Add(f)
+}
+
+// Wei converts a wei amount to a gauge value.
+func Wei(amount *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(amount).Float64()
+	return f
+}
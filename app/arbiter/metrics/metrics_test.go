@@ -0,0 +1,43 @@
+// Copyright (c) 2025 The bel2 developers
+
+package metrics
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	SetListener(90, 100)
+	AddGas(GasSignature, 21000, big.NewInt(1000000000))
+	Submissions.WithLabelValues(SubmissionSucceeded).Inc()
+
+	body := scrape(t)
+	for _, line := range []string{
+		"arbiter_listener_lag_blocks 10",
+		"arbiter_chain_head 100",
+		`arbiter_gas_spent_wei_total{kind="signature"} 2.1e+13`,
+		`arbiter_submissions_total{result="success"} 1`,
+		"arbiter_nearest_deadline_seconds +Inf",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics output misses %q", line)
+		}
+	}
+}
+
+func TestSetListenerAhead(t *testing.T) {
+	SetListener(101, 100)
+	body := scrape(t)
+	if !strings.Contains(body, "arbiter_listener_lag_blocks 0") {
+		t.Fatal("lag must not go negative")
+	}
+}
+
+func scrape(t *testing.T) string {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
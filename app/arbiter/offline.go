@@ -0,0 +1,354 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/glog"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/config"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/history"
+)
+
+const exportRequestUsage = `Usage: arbiter export-request -txid <id> [-from <block>] [-out <file>] [run flags]
+
+Writes the arbitration request txid as a signing bundle for "arbiter
+offline-sign": the ArbitrationRequested event, the on-chain transaction
+record with the BTC transaction, script and prevouts, and the operator BTC
+public key. Fails if the arbiter may not sign the request. Accepts the
+flags and ARBITER_* environment variables of "arbiter run".
+
+Flags:
+  -txid <id>      arbitration transaction id
+  -from <block>   first ESC block searched for the event
+                  (default: escStartHeight)
+  -out <file>     bundle file (default: <txid>.bundle.json)
+  -timeout        time limit for the RPC calls (default 5m)
+`
+
+const offlineSignUsage = `Usage: arbiter offline-sign -bundle <file> -arbiter <address> [flags]
+
+Verifies a signing bundle written by "arbiter export-request" with the
+policy checks of the online signer and signs its BTC transaction. Needs no
+network access or config file, run it on the air-gapped host holding the
+BTC key. The signature file goes back to the online host for "arbiter
+import-signature".
+
+Flags:
+  -bundle <file>       signing bundle
+  -arbiter <address>   ESC arbitrator address, env ARBITER_ADDRESS
+  -keyfile <file>      BTC key file ({"privKey":"<hex>"}),
+                       env ARBITER_BTC_PRIVATE_KEY if empty
+  -out <file>          signature file (default: <txid>.signature.json)
+  -yes                 do not ask for confirmation
+`
+
+const importSignatureUsage = `Usage: arbiter import-signature -signature <file> [flags] [run flags]
+
+Checks a signature written by "arbiter offline-sign" against the current
+on-chain record and the registered operator BTC public key, and submits it
+with the ESC operator key. Accepts the flags and ARBITER_* environment
+variables of "arbiter run".
+
+Flags:
+  -signature <file>   signature file
+  -yes                do not ask for confirmation
+  -wait               wait for the receipt (default true)
+  -timeout            time limit for the RPC calls and the receipt
+                      (default 5m)
+`
+
+func runExportRequest(args []string) error {
+	f := newRunFlags("export-request")
+	f.fs.Usage = func() {
+		fmt.Print(exportRequestUsage)
+		printRunUsage()
+	}
+	txid := f.fs.String("txid", "", "arbitration transaction id")
+	from := f.fs.Uint64("from", 0, "first ESC block searched for the event")
+	out := f.fs.String("out", "", "bundle file")
+	timeout := f.fs.Duration("timeout", 5*time.Minute, "time limit for the RPC calls")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	id, err := parseTxId(*txid)
+	if err != nil {
+		return err
+	}
+	g.Log().SetLevel(glog.LEVEL_WARN)
+
+	ctx, cancel := context.WithTimeout(gctx.New(), *timeout)
+	defer cancel()
+	cfg, inspector, err := loadInspector(ctx, f)
+	if err != nil {
+		return err
+	}
+	start := *from
+	if start == 0 {
+		start = cfg.ESCStartHeight
+	}
+	height, err := inspector.LatestHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("get latest height: %w", err)
+	}
+	event, err := inspector.FindArbitrationRequest(ctx, id, start, height)
+	if err != nil {
+		return fmt.Errorf("find ArbitrationRequested event: %w", err)
+	}
+	if event == nil {
+		return fmt.Errorf("no ArbitrationRequested event of %s in blocks %d to %d, set -from", id, start, height)
+	}
+	info, err := inspector.TransactionById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("getTransactionById: %w", err)
+	}
+	arbitrator, err := inspector.ArbitratorInfo(ctx, common.HexToAddress(cfg.ESCArbiterAddress))
+	if err != nil {
+		return fmt.Errorf("getArbitratorInfo: %w", err)
+	}
+	if len(arbitrator.OperatorBtcPubKey) == 0 {
+		return fmt.Errorf("%s has no operator BTC public key registered", cfg.ESCArbiterAddress)
+	}
+
+	bundle, err := arbiter.NewBundle(cfg.Network, cfg.ESCArbiterAddress, event, info, arbitrator.OperatorBtcPubKey, time.Now())
+	if err != nil {
+		return err
+	}
+	path := *out
+	if path == "" {
+		path = id.Hex() + ".bundle.json"
+	}
+	if err := writeJSON(path, bundle); err != nil {
+		return err
+	}
+	fmt.Println("Bundle:      ", path)
+	fmt.Println("Deadline:    ", time.Unix(bundle.Transaction.Deadline, 0).UTC())
+	fmt.Println("SigHash:     ", bundle.SigHash.Hex())
+	return nil
+}
+
+func runOfflineSign(args []string) error {
+	fs := flag.NewFlagSet("offline-sign", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Print(offlineSignUsage) }
+	bundlePath := fs.String("bundle", "", "signing bundle")
+	arbitrator := fs.String("arbiter", os.Getenv("ARBITER_ADDRESS"), "ESC arbitrator address")
+	keyFile := fs.String("keyfile", "", "BTC key file")
+	out := fs.String("out", "", "signature file")
+	yes := fs.Bool("yes", false, "skip confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *bundlePath == "" {
+		return errors.New("-bundle is required")
+	}
+	if !common.IsHexAddress(*arbitrator) {
+		return fmt.Errorf("-arbiter %q is not an ESC address", *arbitrator)
+	}
+
+	var bundle arbiter.Bundle
+	if err := readJSON(*bundlePath, &bundle); err != nil {
+		return err
+	}
+	req, err := bundle.Verify(*arbitrator, time.Now())
+	if err != nil {
+		return fmt.Errorf("verify bundle: %w", err)
+	}
+	printBundle(&bundle, req)
+
+	key, _, err := readKey(*keyFile, "ARBITER_BTC_PRIVATE_KEY")
+	if err != nil {
+		return fmt.Errorf("read BTC key: %w", err)
+	}
+	if !*yes && !confirm("Sign this BTC transaction?") {
+		return errors.New("aborted")
+	}
+	signature, err := arbiter.SignBundle(&bundle, *arbitrator, key, time.Now())
+	if err != nil {
+		return err
+	}
+	path := *out
+	if path == "" {
+		path = bundle.TxId.Hex() + ".signature.json"
+	}
+	if err := writeJSON(path, signature); err != nil {
+		return err
+	}
+	fmt.Println("Signature:   ", path)
+	return nil
+}
+
+func runImportSignature(args []string) error {
+	f := newRunFlags("import-signature")
+	f.fs.Usage = func() {
+		fmt.Print(importSignatureUsage)
+		printRunUsage()
+	}
+	signaturePath := f.fs.String("signature", "", "signature file")
+	yes := f.fs.Bool("yes", false, "skip confirmation")
+	wait := f.fs.Bool("wait", true, "wait for receipt")
+	timeout := f.fs.Duration("timeout", 5*time.Minute, "time limit for the RPC calls and the receipt")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	if *signaturePath == "" {
+		return errors.New("-signature is required")
+	}
+	var signature arbiter.BundleSignature
+	if err := readJSON(*signaturePath, &signature); err != nil {
+		return err
+	}
+	g.Log().SetLevel(glog.LEVEL_WARN)
+
+	ctx, cancel := context.WithTimeout(gctx.New(), *timeout)
+	defer cancel()
+	cfg, inspector, err := loadInspector(ctx, f)
+	if err != nil {
+		return err
+	}
+	// the record may have changed since the export, check it again
+	info, err := inspector.TransactionById(ctx, signature.TxId)
+	if err != nil {
+		return fmt.Errorf("getTransactionById: %w", err)
+	}
+	arbitrator, err := inspector.ArbitratorInfo(ctx, common.HexToAddress(cfg.ESCArbiterAddress))
+	if err != nil {
+		return fmt.Errorf("getArbitratorInfo: %w", err)
+	}
+	if err := arbiter.VerifyImport(&signature, info, cfg.ESCArbiterAddress, arbitrator.OperatorBtcPubKey, time.Now()); err != nil {
+		return err
+	}
+	fmt.Println("Transaction: ", signature.TxId.Hex())
+	fmt.Println("SigHash:     ", signature.SigHash.Hex())
+	fmt.Println("Signature:   ", signature.Signature.String())
+	fmt.Println("Verified:     operator BTC key", hex.EncodeToString(arbitrator.OperatorBtcPubKey))
+
+	escKey, _, err := readKey(cfg.EscKeyFilePath, "ARBITER_ESC_PRIVATE_KEY")
+	if err != nil {
+		return fmt.Errorf("read ESC key: %w", err)
+	}
+	if !*yes && !confirm("Submit signature?") {
+		return errors.New("aborted")
+	}
+	escNode, err := contract.New(ctx, cfg, escKey, nil, history.Nop{}, g.Log())
+	if err != nil {
+		return err
+	}
+	hash, err := escNode.SubmitArbitrationSignature(ctx, signature.Signature, signature.TxId)
+	if err != nil {
+		return fmt.Errorf("submit signature: %w", err)
+	}
+	fmt.Println("ESC tx:      ", hash.Hex())
+	if !*wait {
+		return nil
+	}
+	receipt, err := escNode.WaitForReceipt(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("wait for receipt: %w", err)
+	}
+	fmt.Println("Block:       ", receipt.BlockNumber)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s reverted", hash.Hex())
+	}
+	fmt.Println("Status:       success")
+	return nil
+}
+
+// loadInspector loads the config with the overrides of f and connects to
+// the ESC RPC.
+func loadInspector(ctx context.Context, f *runFlags) (*config.Config, *contract.Inspector, error) {
+	if _, err := f.load(ctx); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	inspector, err := contract.NewInspector(ctx, cfg.Http, cfg.ESCArbiterContractAddress, cfg.ESCArbiterManagerContractAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.ESCChainID != 0 {
+		chainId, err := inspector.ChainID(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("query ESC chain id: %w", err)
+		}
+		if chainId.Int64() != cfg.ESCChainID {
+			return nil, nil, fmt.Errorf("ESC RPC %s serves chain %s, network %s expects %d", cfg.Http, chainId, cfg.Network, cfg.ESCChainID)
+		}
+	}
+	return cfg, inspector, nil
+}
+
+// printBundle shows what offline-sign is about to sign.
+func printBundle(b *arbiter.Bundle, req *arbiter.BundleRequest) {
+	fmt.Println("Network:     ", b.Network)
+	fmt.Println("Transaction: ", b.TxId.Hex())
+	fmt.Println("Exported:    ", b.CreatedAt)
+	fmt.Println("Dapp:        ", b.Transaction.Dapp.Hex())
+	fmt.Println("Deadline:    ", time.Unix(b.Transaction.Deadline, 0).UTC())
+	fmt.Println("BTC tx:      ", req.BtcTx.TxHash())
+	for i, in := range req.BtcTx.TxIn {
+		amount := "unknown amount"
+		if req.InputAmounts[i] != nil {
+			amount = btcutil.Amount(req.InputAmounts[i].Int64()).String()
+		}
+		fmt.Printf("  input[%d]:   %s (%s)\n", i, in.PreviousOutPoint, amount)
+	}
+	// outputs are shown as addresses of the bundle network if it is known
+	profile, profileErr := config.GetProfile(b.Network)
+	for i, out := range req.BtcTx.TxOut {
+		to := fmt.Sprintf("script %x", out.PkScript)
+		if profileErr == nil {
+			if _, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript, profile.BTCParams); err == nil && len(addrs) == 1 {
+				to = addrs[0].EncodeAddress()
+			}
+		}
+		fmt.Printf("  output[%d]:  %s to %s\n", i, btcutil.Amount(out.Value), to)
+	}
+	fmt.Println("SigHash:     ", b.SigHash.Hex())
+	fmt.Println("Operator key:", b.OperatorBtcPubKey.String())
+}
+
+func parseTxId(value string) (common.Hash, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "0x")
+	if len(value) != 64 {
+		return common.Hash{}, errors.New("-txid must be a 32 byte hex transaction id")
+	}
+	return common.HexToHash(value), nil
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
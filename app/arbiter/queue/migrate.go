@@ -0,0 +1,103 @@
+// Copyright (c) 2025 The bel2 developers
+
+package queue
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+var dirsMigratedKey = []byte("dirsMigrated")
+
+// migrateOrder lists the states of the directory queue, most advanced
+// first. The old flow could leave a request in several directories, the
+// first state it is found in wins so a signed request is not signed again.
+var migrateOrder = []State{StateSigned, StateFailed, StatePending}
+
+// MigrateDirs imports the request files of the former directory queue, one
+// directory per state, the first time it is called on a database. Files
+// are left in place. Failed files keep the reason from their name suffix,
+// e.g. "<txHash>.SubmitSignatureFailed", as last error. It returns the
+// number of imported requests and the files that could not be decoded.
+func (q *Queue) MigrateDirs(dirs map[State]string) (int, []string, error) {
+	for state := range dirs {
+		if !containsState(migrateOrder, state) {
+			return 0, nil, fmt.Errorf("no directory queue for state %s", state)
+		}
+	}
+	migrated := false
+	err := q.db.View(func(tx *bolt.Tx) error {
+		migrated = tx.Bucket(metaBucket).Get(dirsMigratedKey) != nil
+		return nil
+	})
+	if err != nil || migrated {
+		return 0, nil, err
+	}
+
+	imported := 0
+	var skipped []string
+	for _, state := range migrateOrder {
+		dir, ok := dirs[state]
+		if !ok {
+			continue
+		}
+		files, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return imported, skipped, err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return imported, skipped, err
+			}
+			event, err := events.LoadContractEvent(content)
+			if err != nil {
+				skipped = append(skipped, path)
+				continue
+			}
+			createdAt := time.Now()
+			if info, err := file.Info(); err == nil {
+				createdAt = info.ModTime()
+			}
+			lastError := ""
+			if state == StateFailed {
+				lastError = failureReason(file.Name())
+			}
+			created, err := q.add(event, state, createdAt, lastError)
+			if err != nil {
+				return imported, skipped, err
+			}
+			if created {
+				imported++
+			}
+		}
+	}
+
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(dirsMigratedKey, []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+	return imported, skipped, err
+}
+
+// failureReason extracts the suffix the directory queue appended to failed
+// files, "<txHash>.<reason>".
+func failureReason(name string) string {
+	if i := strings.Index(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return "failed"
+}
@@ -0,0 +1,539 @@
+// Copyright (c) 2025 The bel2 developers
+
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+type State string
+
+const (
+	// StatePending requests are waiting to be signed
+	StatePending State = "pending"
+	// StateFailed requests failed with a transient error and are retried
+	// once NextAttempt is reached
+	StateFailed State = "failed"
+	// StateSigned requests had their signature submitted
+	StateSigned State = "signed"
+	// StateParked requests failed permanently and are not retried, LastError
+	// holds the reason
+	StateParked State = "parked"
+	// StateCancelled requests were cancelled or rejected by the operator and
+	// are not processed or requeued by the reconciler
+	StateCancelled State = "cancelled"
+	// StateAwaitingApproval requests passed the automated checks and wait
+	// for an operator to approve or reject them
+	StateAwaitingApproval State = "awaiting_approval"
+	// StateSimulated requests were signed in a dry run, their submission
+	// was simulated but not sent
+	StateSimulated State = "simulated"
+)
+
+// Approval decisions.
+const (
+	Approved = "approved"
+	Rejected = "rejected"
+)
+
+// maxTransitions bounds the state history kept per item.
+const maxTransitions = 50
+
+var (
+	itemsBucket = []byte("items")
+	metaBucket  = []byte("meta")
+
+	ErrNotFound = errors.New("queue item not found")
+	// ErrInvalidState is returned for a change not allowed in the current
+	// state of the item
+	ErrInvalidState = errors.New("invalid queue item state")
+)
+
+// Key identifies an arbitration request by the ESC transaction and log
+// index of its ArbitrationRequested event.
+type Key struct {
+	TxHash   common.Hash
+	LogIndex uint
+}
+
+func KeyOf(event *events.ContractLogEvent) Key {
+	return Key{TxHash: event.TxHash, LogIndex: event.LogIndex}
+}
+
+func (k Key) String() string {
+	return k.TxHash.String() + "-" + strconv.FormatUint(uint64(k.LogIndex), 10)
+}
+
+// ParseKey parses the "<txHash>-<logIndex>" form returned by Key.String.
+func ParseKey(s string) (Key, error) {
+	i := strings.LastIndex(s, "-")
+	if i < 0 {
+		return Key{}, fmt.Errorf("invalid queue key %q", s)
+	}
+	hash, err := hex.DecodeString(strings.TrimPrefix(s[:i], "0x"))
+	if err != nil || len(hash) != common.HashLength {
+		return Key{}, fmt.Errorf("invalid queue key tx hash %q", s[:i])
+	}
+	index, err := strconv.ParseUint(s[i+1:], 10, 32)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid queue key log index %q", s[i+1:])
+	}
+	return Key{TxHash: common.BytesToHash(hash), LogIndex: uint(index)}, nil
+}
+
+func (k Key) bytes() []byte {
+	b := make([]byte, common.HashLength+4)
+	copy(b, k.TxHash[:])
+	binary.BigEndian.PutUint32(b[common.HashLength:], uint32(k.LogIndex))
+	return b
+}
+
+// Item is an arbitration request and its processing state.
+type Item struct {
+	Key       Key
+	Event     *events.ContractLogEvent
+	State     State
+	Attempts  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	LastError string
+	// earliest time a failed request is retried, zero means now
+	NextAttempt time.Time
+	// on-chain arbitration deadline, zero if not known yet
+	Deadline time.Time
+	// ESC transaction that submitted the signature
+	SubmitTxHash common.Hash
+	// state changes, oldest first, at most maxTransitions
+	Transitions []Transition
+	// operator review, nil if the request did not need one
+	Approval *Approval `json:",omitempty"`
+}
+
+// Approval is the operator review of a request in approval mode.
+type Approval struct {
+	// human-readable summary of the request shown to the operator
+	Summary json.RawMessage `json:",omitempty"`
+	// hash of the BTC transaction under review, an approval allows signing
+	// this hash only
+	SigHash     common.Hash
+	RequestedAt time.Time
+	// Approved or Rejected, empty while waiting
+	Decision  string `json:",omitempty"`
+	DecidedBy string `json:",omitempty"`
+	DecidedAt time.Time
+	Reason    string `json:",omitempty"`
+}
+
+// Approved reports whether the request was approved for signing sigHash.
+func (item *Item) Approved(sigHash common.Hash) bool {
+	return item.Approval != nil && item.Approval.Decision == Approved && item.Approval.SigHash == sigHash
+}
+
+// Transition is a change of the state or the error of an item.
+type Transition struct {
+	At    time.Time
+	State State
+	Error string `json:",omitempty"`
+}
+
+func (item *Item) addTransition(at time.Time) {
+	item.Transitions = append(item.Transitions, Transition{At: at, State: item.State, Error: item.LastError})
+	if n := len(item.Transitions); n > maxTransitions {
+		item.Transitions = item.Transitions[n-maxTransitions:]
+	}
+}
+
+// Due reports whether a failed request may be retried at now.
+func (item *Item) Due(now time.Time) bool {
+	return item.State == StateFailed && !now.Before(item.NextAttempt)
+}
+
+// Queue is the arbitration request queue stored in a bbolt database. Every
+// state change is a single transaction.
+type Queue struct {
+	db        *bolt.DB
+	observers []func(item *Item)
+	wake      chan struct{}
+}
+
+func Open(path string) (*Queue, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(itemsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(metaBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Queue{db: db, wake: make(chan struct{}, 1)}, nil
+}
+
+func (q *Queue) Close() error {
+	return q.db.Close()
+}
+
+// OnChange registers fn to be called after every committed change with the
+// new state of the item. Observers must be registered before the queue is
+// shared between goroutines.
+func (q *Queue) OnChange(fn func(item *Item)) {
+	q.observers = append(q.observers, fn)
+}
+
+// Wake returns a channel that receives whenever a request becomes pending.
+// Signals are coalesced, a receiver should process every pending request.
+func (q *Queue) Wake() <-chan struct{} {
+	return q.wake
+}
+
+func (q *Queue) notify(item *Item) {
+	for _, fn := range q.observers {
+		fn(item)
+	}
+	if item.State == StatePending {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Enqueue adds event as a pending request. It returns false without
+// changing anything if the request is already known, see Find.
+func (q *Queue) Enqueue(event *events.ContractLogEvent) (bool, error) {
+	return q.add(event, StatePending, time.Now(), "")
+}
+
+func (q *Queue) add(event *events.ContractLogEvent, state State, createdAt time.Time, lastError string) (bool, error) {
+	key := KeyOf(event)
+	var item *Item
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(itemsBucket)
+		if known, err := findItem(b, event); err != nil || known != nil {
+			return err
+		}
+		item = &Item{
+			Key:       key,
+			Event:     event,
+			State:     state,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			LastError: lastError,
+		}
+		item.addTransition(createdAt)
+		return putItem(b, item)
+	})
+	if err != nil || item == nil {
+		return false, err
+	}
+	q.notify(item)
+	return true, nil
+}
+
+// Find returns the item of the request of event. Events of the former gob
+// format have no log index, so a request is also known by another item of
+// the same ESC transaction and arbitration id.
+func (q *Queue) Find(event *events.ContractLogEvent) (*Item, error) {
+	var item *Item
+	err := q.db.View(func(tx *bolt.Tx) error {
+		var err error
+		item, err = findItem(tx.Bucket(itemsBucket), event)
+		return err
+	})
+	if err == nil && item == nil {
+		err = ErrNotFound
+	}
+	return item, err
+}
+
+func (q *Queue) Get(key Key) (*Item, error) {
+	var item *Item
+	err := q.db.View(func(tx *bolt.Tx) error {
+		var err error
+		item, err = getItem(tx.Bucket(itemsBucket), key)
+		return err
+	})
+	return item, err
+}
+
+// List returns the items in any of states, oldest first. No states means
+// every item.
+func (q *Queue) List(states ...State) ([]*Item, error) {
+	var items []*Item
+	err := q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(itemsBucket).ForEach(func(k, v []byte) error {
+			item := &Item{}
+			if err := json.Unmarshal(v, item); err != nil {
+				return err
+			}
+			if len(states) == 0 || containsState(states, item.State) {
+				items = append(items, item)
+			}
+			return nil
+		})
+	})
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items, err
+}
+
+// Update applies fn to the item and stores the result atomically.
+func (q *Queue) Update(key Key, fn func(item *Item) error) error {
+	var item *Item
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(itemsBucket)
+		var err error
+		item, err = getItem(b, key)
+		if err != nil {
+			return err
+		}
+		state, lastError := item.State, item.LastError
+		if err := fn(item); err != nil {
+			return err
+		}
+		item.UpdatedAt = time.Now()
+		if item.State != state || item.LastError != lastError {
+			item.addTransition(item.UpdatedAt)
+		}
+		return putItem(b, item)
+	})
+	if err != nil {
+		return err
+	}
+	q.notify(item)
+	return nil
+}
+
+// MarkAttempt counts a new processing attempt. It fails if the request was
+// cancelled since it was listed.
+func (q *Queue) MarkAttempt(key Key) error {
+	return q.Update(key, func(item *Item) error {
+		if item.State == StateCancelled {
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.Attempts++
+		return nil
+	})
+}
+
+// MarkFailed moves the request to failed, to be retried at next.
+func (q *Queue) MarkFailed(key Key, reason error, next time.Time) error {
+	return q.Update(key, func(item *Item) error {
+		if item.State == StateCancelled {
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.State = StateFailed
+		item.LastError = reason.Error()
+		item.NextAttempt = next
+		return nil
+	})
+}
+
+// Park stops retrying the request.
+func (q *Queue) Park(key Key, reason error) error {
+	return q.Update(key, func(item *Item) error {
+		if item.State == StateCancelled {
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.State = StateParked
+		item.LastError = reason.Error()
+		item.NextAttempt = time.Time{}
+		return nil
+	})
+}
+
+// SetDeadline records the on-chain arbitration deadline of the request.
+func (q *Queue) SetDeadline(key Key, deadline time.Time) error {
+	return q.Update(key, func(item *Item) error {
+		item.Deadline = deadline
+		return nil
+	})
+}
+
+func (q *Queue) MarkSigned(key Key, submitTxHash common.Hash) error {
+	return q.Update(key, func(item *Item) error {
+		item.State = StateSigned
+		item.SubmitTxHash = submitTxHash
+		item.LastError = ""
+		item.NextAttempt = time.Time{}
+		return nil
+	})
+}
+
+// MarkSimulated records that the request was signed and its submission
+// simulated in a dry run.
+func (q *Queue) MarkSimulated(key Key) error {
+	return q.Update(key, func(item *Item) error {
+		if item.State == StateCancelled {
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.State = StateSimulated
+		item.LastError = ""
+		item.NextAttempt = time.Time{}
+		return nil
+	})
+}
+
+// Requeue moves a known request back to pending.
+func (q *Queue) Requeue(key Key) error {
+	return q.Update(key, func(item *Item) error {
+		item.State = StatePending
+		item.NextAttempt = time.Time{}
+		return nil
+	})
+}
+
+// Retry moves a failed, parked, cancelled or simulated request back to
+// pending and clears its error. A rejected request needs approval again.
+func (q *Queue) Retry(key Key) error {
+	return q.Update(key, func(item *Item) error {
+		switch item.State {
+		case StateFailed, StateParked, StateCancelled, StateSimulated:
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.State = StatePending
+		item.LastError = ""
+		item.NextAttempt = time.Time{}
+		if item.Approval != nil && item.Approval.Decision == Rejected {
+			item.Approval = nil
+		}
+		return nil
+	})
+}
+
+// AwaitApproval stops the request until an operator approves or rejects
+// it. summary describes the request for the review, an approval allows
+// signing sigHash only.
+func (q *Queue) AwaitApproval(key Key, summary []byte, sigHash common.Hash) error {
+	return q.Update(key, func(item *Item) error {
+		if item.State == StateCancelled {
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.State = StateAwaitingApproval
+		item.NextAttempt = time.Time{}
+		item.Approval = &Approval{Summary: summary, SigHash: sigHash, RequestedAt: time.Now()}
+		return nil
+	})
+}
+
+// Approve moves a request awaiting approval to pending, it is signed
+// without asking again.
+func (q *Queue) Approve(key Key, by, reason string) error {
+	return q.decide(key, Approved, by, reason)
+}
+
+// Reject cancels a request awaiting approval.
+func (q *Queue) Reject(key Key, by, reason string) error {
+	return q.decide(key, Rejected, by, reason)
+}
+
+func (q *Queue) decide(key Key, decision, by, reason string) error {
+	return q.Update(key, func(item *Item) error {
+		if item.State != StateAwaitingApproval || item.Approval == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.Approval.Decision = decision
+		item.Approval.DecidedBy = by
+		item.Approval.DecidedAt = time.Now()
+		item.Approval.Reason = reason
+		if decision == Approved {
+			item.State = StatePending
+			item.LastError = ""
+			return nil
+		}
+		item.State = StateCancelled
+		item.LastError = "rejected by " + by
+		if reason != "" {
+			item.LastError += ": " + reason
+		}
+		return nil
+	})
+}
+
+// Cancel stops processing a request that is not signed yet. A request
+// already picked up by a worker still finishes its current attempt.
+func (q *Queue) Cancel(key Key, reason string) error {
+	return q.Update(key, func(item *Item) error {
+		switch item.State {
+		case StatePending, StateFailed, StateParked, StateAwaitingApproval:
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidState, item.State)
+		}
+		item.State = StateCancelled
+		item.LastError = reason
+		item.NextAttempt = time.Time{}
+		return nil
+	})
+}
+
+func getItem(b *bolt.Bucket, key Key) (*Item, error) {
+	v := b.Get(key.bytes())
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	item := &Item{}
+	if err := json.Unmarshal(v, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// findItem returns the item of event, nil if there is none.
+func findItem(b *bolt.Bucket, event *events.ContractLogEvent) (*Item, error) {
+	key := KeyOf(event)
+	if b.Get(key.bytes()) != nil {
+		return getItem(b, key)
+	}
+	if len(event.Topics) < 2 {
+		return nil, nil
+	}
+	c := b.Cursor()
+	prefix := key.TxHash.Bytes()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		item := &Item{}
+		if err := json.Unmarshal(v, item); err != nil {
+			return nil, err
+		}
+		if item.Event != nil && len(item.Event.Topics) >= 2 && item.Event.Topics[0] == event.Topics[0] && item.Event.Topics[1] == event.Topics[1] {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+func putItem(b *bolt.Bucket, item *Item) error {
+	v, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return b.Put(item.Key.bytes(), v)
+}
+
+func containsState(states []State, state State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
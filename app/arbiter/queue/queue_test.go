@@ -0,0 +1,407 @@
+// Copyright (c) 2025 The bel2 developers
+
+package queue
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/contract/events"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	q, err := Open(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+func testEvent(tx byte, logIndex uint) *events.ContractLogEvent {
+	return &events.ContractLogEvent{
+		TxHash:   common.Hash{tx},
+		Topics:   []common.Hash{events.ArbitrationRequested, {tx, 1}},
+		Block:    100,
+		LogIndex: logIndex,
+	}
+}
+
+func TestEnqueueKeyedByLogIndex(t *testing.T) {
+	q := newTestQueue(t)
+
+	// two requests of different transactions in one ESC transaction
+	for _, logIndex := range []uint{0, 1} {
+		event := testEvent(1, logIndex)
+		event.Topics[1][1] = byte(logIndex)
+		created, err := q.Enqueue(event)
+		if err != nil || !created {
+			t.Fatalf("enqueue log %d: created %v, err %v", logIndex, created, err)
+		}
+	}
+	duplicate := testEvent(1, 1)
+	duplicate.Topics[1][1] = 1
+	created, err := q.Enqueue(duplicate)
+	if err != nil || created {
+		t.Fatalf("duplicate enqueue: created %v, err %v", created, err)
+	}
+
+	items, err := q.List(StatePending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 pending items, got %d", len(items))
+	}
+}
+
+func TestStateTransitions(t *testing.T) {
+	q := newTestQueue(t)
+	event := testEvent(2, 3)
+	key := KeyOf(event)
+	if _, err := q.Enqueue(event); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.MarkAttempt(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkFailed(key, errors.New("rpc down"), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	item, err := q.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StateFailed || item.Attempts != 1 || item.LastError != "rpc down" {
+		t.Fatalf("unexpected failed item %+v", item)
+	}
+	if item.Due(time.Now()) || !item.Due(time.Now().Add(2*time.Minute)) {
+		t.Fatalf("unexpected retry schedule %v", item.NextAttempt)
+	}
+
+	if err := q.Park(key, errors.New("policy rejected")); err != nil {
+		t.Fatal(err)
+	}
+	item, err = q.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StateParked || item.LastError != "policy rejected" || item.Due(time.Now().Add(time.Hour)) {
+		t.Fatalf("unexpected parked item %+v", item)
+	}
+
+	if err := q.Requeue(key); err != nil {
+		t.Fatal(err)
+	}
+	submit := common.Hash{9}
+	if err := q.MarkSigned(key, submit); err != nil {
+		t.Fatal(err)
+	}
+	item, err = q.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StateSigned || item.SubmitTxHash != submit || item.LastError != "" {
+		t.Fatalf("unexpected signed item %+v", item)
+	}
+	if item.Event.Topics[1] != event.Topics[1] {
+		t.Fatalf("event not preserved: %+v", item.Event)
+	}
+
+	if _, err := q.Get(Key{TxHash: common.Hash{7}}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := Key{TxHash: common.HexToHash("0xabc"), LogIndex: 12}
+	parsed, err := ParseKey(key.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != key {
+		t.Fatalf("expected %v, got %v", key, parsed)
+	}
+	if _, err := ParseKey("0xabc"); err == nil {
+		t.Fatal("expected error for key without log index")
+	}
+}
+
+func TestMigrateDirs(t *testing.T) {
+	q := newTestQueue(t)
+	dir := t.TempDir()
+	request := filepath.Join(dir, "request")
+	failed := filepath.Join(dir, "failed")
+
+	pending := testEvent(3, 0)
+	if err := events.SaveContractEvent(filepath.Join(request, pending.TxHash.String()), pending); err != nil {
+		t.Fatal(err)
+	}
+	broken := testEvent(4, 0)
+	if err := events.SaveContractEvent(filepath.Join(failed, broken.TxHash.String()+".SubmitSignatureFailed"), broken); err != nil {
+		t.Fatal(err)
+	}
+	dirs := map[State]string{
+		StatePending: request,
+		StateFailed:  failed,
+		StateSigned:  filepath.Join(dir, "signed"),
+	}
+
+	imported, skipped, err := q.MigrateDirs(dirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 2 || len(skipped) != 0 {
+		t.Fatalf("imported %d, skipped %v", imported, skipped)
+	}
+	item, err := q.Get(KeyOf(broken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StateFailed || item.LastError != "SubmitSignatureFailed" {
+		t.Fatalf("unexpected migrated item %+v", item)
+	}
+
+	if err := q.Requeue(KeyOf(broken)); err != nil {
+		t.Fatal(err)
+	}
+	imported, _, err = q.MigrateDirs(dirs)
+	if err != nil || imported != 0 {
+		t.Fatalf("second migration imported %d, err %v", imported, err)
+	}
+}
+
+func TestMigrateDirsDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	dirs := map[State]string{
+		StatePending: filepath.Join(dir, "request"),
+		StateFailed:  filepath.Join(dir, "failed"),
+		StateSigned:  filepath.Join(dir, "signed"),
+	}
+	event := testEvent(7, 0)
+	for _, state := range []State{StatePending, StateFailed, StateSigned} {
+		if err := events.SaveContractEvent(filepath.Join(dirs[state], event.TxHash.String()), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a fresh queue each time, map order must not matter
+	for i := 0; i < 10; i++ {
+		q := newTestQueue(t)
+		imported, _, err := q.MigrateDirs(dirs)
+		if err != nil || imported != 1 {
+			t.Fatalf("imported %d, err %v", imported, err)
+		}
+		item, err := q.Get(KeyOf(event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.State != StateSigned {
+			t.Fatalf("request in request/ and signed/ imported as %s", item.State)
+		}
+	}
+}
+
+func TestEnqueueLegacyEvent(t *testing.T) {
+	dir := t.TempDir()
+	dirs := map[State]string{StateSigned: filepath.Join(dir, "signed")}
+	// events of the gob format have no log index
+	legacy := testEvent(8, 0)
+	if err := events.SaveContractEvent(filepath.Join(dirs[StateSigned], legacy.TxHash.String()), legacy); err != nil {
+		t.Fatal(err)
+	}
+	q := newTestQueue(t)
+	if imported, _, err := q.MigrateDirs(dirs); err != nil || imported != 1 {
+		t.Fatalf("imported %d, err %v", imported, err)
+	}
+
+	// a rescan finds the same request under its real log index
+	event := testEvent(8, 3)
+	created, err := q.Enqueue(event)
+	if err != nil || created {
+		t.Fatalf("rescanned legacy request: created %v, err %v", created, err)
+	}
+	item, err := q.Find(event)
+	if err != nil || item.Key != KeyOf(legacy) || item.State != StateSigned {
+		t.Fatalf("find: %+v, %v", item, err)
+	}
+
+	// another request of the same ESC transaction is new
+	other := testEvent(8, 4)
+	other.Topics[1] = common.Hash{9}
+	if created, err := q.Enqueue(other); err != nil || !created {
+		t.Fatalf("other request: created %v, err %v", created, err)
+	}
+	if _, err := q.Find(testEvent(9, 0)); err != ErrNotFound {
+		t.Fatalf("unknown request: %v", err)
+	}
+}
+
+func TestWakeOnPending(t *testing.T) {
+	q := newTestQueue(t)
+	event := testEvent(5, 0)
+	if _, err := q.Enqueue(event); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Enqueue(testEvent(6, 0)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-q.Wake():
+	default:
+		t.Fatal("no wake signal after enqueue")
+	}
+	select {
+	case <-q.Wake():
+		t.Fatal("wake signals not coalesced")
+	default:
+	}
+
+	if err := q.MarkSigned(KeyOf(event), common.Hash{1}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-q.Wake():
+		t.Fatal("wake signal for a signed request")
+	default:
+	}
+}
+
+func TestRetryAndCancel(t *testing.T) {
+	q := newTestQueue(t)
+	event := testEvent(5, 0)
+	key := KeyOf(event)
+	if _, err := q.Enqueue(event); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Retry(key); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("retry pending: %v", err)
+	}
+	if err := q.Cancel(key, "duplicate request"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkAttempt(key); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("attempt cancelled: %v", err)
+	}
+	if err := q.Park(key, errors.New("late failure")); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("park cancelled: %v", err)
+	}
+	if err := q.Retry(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkSigned(key, common.Hash{9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Cancel(key, "too late"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("cancel signed: %v", err)
+	}
+
+	item, err := q.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Transition{
+		{State: StatePending},
+		{State: StateCancelled, Error: "duplicate request"},
+		{State: StatePending},
+		{State: StateSigned},
+	}
+	if len(item.Transitions) != len(want) {
+		t.Fatalf("transitions: %+v", item.Transitions)
+	}
+	for i, tr := range item.Transitions {
+		if tr.State != want[i].State || tr.Error != want[i].Error || tr.At.IsZero() {
+			t.Fatalf("transition %d: got %+v, want %+v", i, tr, want[i])
+		}
+	}
+}
+
+func TestApproval(t *testing.T) {
+	q := newTestQueue(t)
+	approved, rejected := testEvent(6, 0), testEvent(7, 0)
+	for _, event := range []*events.ContractLogEvent{approved, rejected} {
+		if _, err := q.Enqueue(event); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Approve(KeyOf(event), "alice", ""); !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("approve pending: %v", err)
+		}
+		if err := q.AwaitApproval(KeyOf(event), []byte(`{"dapp":"0x01"}`), common.Hash{0x51}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := q.Approve(KeyOf(approved), "alice", "checked outputs"); err != nil {
+		t.Fatal(err)
+	}
+	item, err := q.Get(KeyOf(approved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StatePending || !item.Approved(common.Hash{0x51}) || item.Approval.DecidedBy != "alice" || string(item.Approval.Summary) != `{"dapp":"0x01"}` {
+		t.Fatalf("approved: %+v %+v", item, item.Approval)
+	}
+	// the approval covers the reviewed transaction only
+	if item.Approved(common.Hash{0x52}) {
+		t.Fatal("approved another sigHash")
+	}
+
+	if err := q.Reject(KeyOf(rejected), "bob", "wrong payout address"); err != nil {
+		t.Fatal(err)
+	}
+	item, err = q.Get(KeyOf(rejected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StateCancelled || item.Approved(common.Hash{0x51}) || item.LastError != "rejected by bob: wrong payout address" {
+		t.Fatalf("rejected: %+v", item)
+	}
+	if err := q.Approve(KeyOf(rejected), "alice", ""); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("approve rejected: %v", err)
+	}
+	// a rejected request sent back to the signer is reviewed again
+	if err := q.Retry(KeyOf(rejected)); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ = q.Get(KeyOf(rejected)); item.Approval != nil {
+		t.Fatalf("retried approval: %+v", item.Approval)
+	}
+}
+
+func TestSimulated(t *testing.T) {
+	q := newTestQueue(t)
+	event := testEvent(8, 0)
+	key := KeyOf(event)
+	if _, err := q.Enqueue(event); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkFailed(key, errors.New("rpc timeout"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkSimulated(key); err != nil {
+		t.Fatal(err)
+	}
+	item, err := q.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.State != StateSimulated || item.LastError != "" || !item.NextAttempt.IsZero() {
+		t.Fatalf("simulated: %+v", item)
+	}
+	// a retry simulates the request again
+	if err := q.Retry(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Cancel(key, "not needed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.MarkSimulated(key); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("simulate cancelled: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
+)
+
+// editors write a file in several steps, reload once they are done
+const reloadDelay = time.Second
+
+// watchConfig reloads the config file when it changes or on SIGHUP until
+// ctx is cancelled. Flag and environment overrides are applied again.
+func watchConfig(ctx context.Context, f *runFlags, path string, arb *arbiter.Arbiter) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// the directory is watched, editors and config maps replace the file
+	var changed <-chan fsnotify.Event
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = watcher.Add(filepath.Dir(path))
+	}
+	if err != nil {
+		g.Log().Error(ctx, "watch config file error, reload with SIGHUP only", err)
+	} else {
+		defer watcher.Close()
+		changed = watcher.Events
+	}
+
+	var delay <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			g.Log().Info(ctx, "SIGHUP received, reloading config")
+			reloadConfig(ctx, f, arb)
+		case event, ok := <-changed:
+			if !ok {
+				changed = nil
+				continue
+			}
+			if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename) != 0 {
+				delay = time.After(reloadDelay)
+			}
+		case <-delay:
+			delay = nil
+			g.Log().Info(ctx, "config file changed, reloading")
+			reloadConfig(ctx, f, arb)
+		}
+	}
+}
+
+// reloadConfig reads the config file again and hands it to the arbiter. An
+// invalid file is reported and the config in effect is kept.
+func reloadConfig(ctx context.Context, f *runFlags, arb *arbiter.Arbiter) {
+	g.Cfg().GetAdapter().(*gcfg.AdapterFile).Clear()
+	if _, err := f.load(ctx); err != nil {
+		g.Log().Error(ctx, "reload config error, keeping the current config:", err)
+		return
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		g.Log().Error(ctx, "reload config error, keeping the current config:", err)
+		return
+	}
+	if _, _, err := arb.Reload(cfg); err != nil {
+		g.Log().Error(ctx, "reload config error:", err)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2025 The bel2 developers
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gctx"
+	"golang.org/x/term"
+
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/arbiter"
+	"github.com/BeL2Labs/Arbiter_Signer/app/arbiter/logging"
+)
+
+const configFileName = "config.yaml"
+
+const runUsage = `Usage: arbiter run [-config <file>] [flags]
+
+Starts the arbiter with an existing config file and key files, without
+prompting. Create them once with "arbiter init", or provide the keys in
+ARBITER_ESC_PRIVATE_KEY and ARBITER_BTC_PRIVATE_KEY.
+
+Every setting below can also be given as environment variable. Flags take
+precedence over the environment, which takes precedence over the file.
+
+Flags:
+  -config <file>       config file, env ARBITER_CONFIG
+                       (default: config.yaml in the working directory,
+                       then next to the executable)
+`
+
+// runOverride maps a flag and an environment variable to a config key.
+type runOverride struct {
+	flag  string
+	env   string
+	key   string
+	usage string
+}
+
+var runOverrides = []runOverride{
+	{"network", "ARBITER_NETWORK", "arbiter.network", "network profile: mainnet, testnet, signet or devnet"},
+	{"esc-rpc", "ARBITER_ESC_RPC", "chain.esc", "ESC RPC URL"},
+	{"esc-chain-id", "ARBITER_ESC_CHAIN_ID", "chain.escChainId", "expected ESC chain id"},
+	{"arbiter-contract", "ARBITER_CONTRACT_ADDRESS", "arbiter.escArbiterContractAddress", "ESC arbiter contract address"},
+	{"manager-contract", "ARBITER_MANAGER_CONTRACT_ADDRESS", "arbiter.escArbiterManagerContractAddress", "ESC arbiter manager contract address"},
+	{"btc-api", "ARBITER_BTC_API", "arbiter.btcApi", "Esplora compatible BTC API URL"},
+	{"arbiter-address", "ARBITER_ADDRESS", "arbiter.escArbiterAddress", "ESC arbitrator address"},
+	{"listener", "ARBITER_LISTENER", "arbiter.listener", "listen for arbitration requests, true or false"},
+	{"signer", "ARBITER_SIGNER", "arbiter.signer", "sign arbitration requests, true or false"},
+	{"dry-run", "ARBITER_DRY_RUN", "arbiter.dryRun", "sign and simulate, but send nothing, true or false"},
+	{"start-height", "ARBITER_ESC_START_HEIGHT", "arbiter.escStartHeight", "ESC block to start listening from"},
+	{"data-path", "ARBITER_DATA_PATH", "arbiter.dataPath", "data directory"},
+	{"key-path", "ARBITER_KEY_FILE_PATH", "arbiter.keyFilePath", "key file directory"},
+	{"monitor-addr", "ARBITER_MONITOR_ADDR", "arbiter.monitorAddr", "metrics and probe listen address"},
+	{"admin-api-addr", "ARBITER_ADMIN_API_ADDR", "arbiter.adminApiAddr", "admin API listen address"},
+	// secrets are read from the environment only, flags show up in ps
+	{"", "ARBITER_ADMIN_API_TOKEN", "arbiter.adminApiToken", "admin API bearer token"},
+	{"log-level", "ARBITER_LOG_LEVEL", "arbiter.logLevel", "lowest level logged"},
+	{"log-format", "ARBITER_LOG_FORMAT", "arbiter.logFormat", "text or json"},
+}
+
+// switchFlags are the overrides that may be given without a value, as in
+// -dry-run.
+var switchFlags = map[string]bool{"dry-run": true}
+
+// switchValue is a boolean flag value kept as string like the other
+// overrides.
+type switchValue string
+
+func (s *switchValue) String() string { return string(*s) }
+
+func (s *switchValue) Set(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*s = switchValue(strconv.FormatBool(b))
+	return nil
+}
+
+func (s *switchValue) IsBoolFlag() bool { return true }
+
+// keyEnvs are the environment variables holding the private keys, by key
+// file name.
+var keyEnvs = []struct{ file, env string }{
+	{"escKey.json", "ARBITER_ESC_PRIVATE_KEY"},
+	{"btcKey.json", "ARBITER_BTC_PRIVATE_KEY"},
+}
+
+func printRunUsage() {
+	fmt.Print(runUsage)
+	for _, o := range runOverrides {
+		name := "(env only)"
+		if o.flag != "" {
+			name = "-" + o.flag
+		}
+		fmt.Printf("  %-20s %s, env %s\n", name, o.usage, o.env)
+	}
+}
+
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	configPath := fs.String("config", "", "config file to write (default: config.yaml next to the executable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := setupConfig(*configPath); err != nil {
+		return err
+	}
+	fmt.Println("\nConfiguration written, start the arbiter with: arbiter run")
+	return nil
+}
+
+// runFlags are the flags of the commands that load the config file.
+type runFlags struct {
+	fs     *flag.FlagSet
+	config *string
+	values map[string]*string
+}
+
+func newRunFlags(name string) *runFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = printRunUsage
+	f := &runFlags{fs: fs, values: make(map[string]*string)}
+	f.config = fs.String("config", os.Getenv("ARBITER_CONFIG"), "config file")
+	// accepted for the command lines of earlier releases
+	fs.StringVar(f.config, "gf.gcfg.file", *f.config, "config file")
+	for _, o := range runOverrides {
+		switch {
+		case switchFlags[o.flag]:
+			value := new(switchValue)
+			fs.Var(value, o.flag, o.usage)
+			f.values[o.flag] = (*string)(value)
+		case o.flag != "":
+			f.values[o.flag] = fs.String(o.flag, "", o.usage)
+		}
+	}
+	return f
+}
+
+func (f *runFlags) parse(args []string) error {
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if f.fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(f.fs.Args(), " "))
+	}
+	return nil
+}
+
+// load points the gf configuration at the config file and applies the
+// overrides of the flags and the environment. It returns the file path.
+func (f *runFlags) load(ctx context.Context) (string, error) {
+	path, err := findConfigFile(*f.config)
+	if err != nil {
+		return "", err
+	}
+	adapter := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+	if err := adapter.SetPath(filepath.Dir(path)); err != nil {
+		return "", err
+	}
+	adapter.SetFileName(filepath.Base(path))
+	if !adapter.Available(ctx) {
+		return "", fmt.Errorf("cannot load config file %s", path)
+	}
+
+	explicit := make(map[string]bool)
+	f.fs.Visit(func(fl *flag.Flag) { explicit[fl.Name] = true })
+	for _, o := range runOverrides {
+		value, ok := os.Getenv(o.env), os.Getenv(o.env) != ""
+		if o.flag != "" && explicit[o.flag] {
+			value, ok = *f.values[o.flag], true
+		}
+		if !ok {
+			continue
+		}
+		if err := adapter.Set(o.key, value); err != nil {
+			return "", fmt.Errorf("set %s: %w", o.key, err)
+		}
+	}
+	return path, nil
+}
+
+// runArbiter loads the config, applies the overrides and runs the arbiter
+// until SIGINT or SIGTERM.
+func runArbiter(args []string) error {
+	f := newRunFlags("run")
+	if err := f.parse(args); err != nil {
+		return err
+	}
+	ctx := gctx.New()
+	path, err := f.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keyPath, err := g.Cfg().Get(ctx, "arbiter.keyFilePath")
+	if err != nil || keyPath.String() == "" {
+		return errors.New("no keyFilePath configured")
+	}
+	if err := prepareKeyFiles(getExpandedPath(keyPath.String())); err != nil {
+		return err
+	}
+
+	startArbiter(f, path)
+	return nil
+}
+
+// findConfigFile returns path, or the default config file if path is empty.
+func findConfigFile(path string) (string, error) {
+	if path != "" {
+		path = getExpandedPath(path)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("config file: %w", err)
+		}
+		return filepath.Abs(path)
+	}
+	candidates := []string{configFileName}
+	if execPath, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(execPath), configFileName))
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return filepath.Abs(candidate)
+		}
+	}
+	return "", errors.New(`no config.yaml found, create one with "arbiter init" or pass -config`)
+}
+
+// prepareKeyFiles writes the key files from the environment where set and
+// checks that both exist. The variables are removed from the environment
+// afterwards.
+func prepareKeyFiles(dir string) error {
+	for _, k := range keyEnvs {
+		path := filepath.Join(dir, k.file)
+		if key := strings.TrimSpace(os.Getenv(k.env)); key != "" {
+			os.Unsetenv(k.env)
+			if err := validatePrivateKey(key); err != nil {
+				return fmt.Errorf("%s: %w", k.env, err)
+			}
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return err
+			}
+			data := fmt.Sprintf(`{"privKey":"%s"}`, key)
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				return fmt.Errorf("write %s: %w", path, err)
+			}
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf(`key file %s missing, create it with "arbiter init" or set %s`, path, k.env)
+		}
+	}
+	return nil
+}
+
+func validatePrivateKey(key string) error {
+	if len(key) != 64 {
+		return errors.New("private key must be exactly 64 hex characters")
+	}
+	if _, err := hex.DecodeString(key); err != nil {
+		return errors.New("private key must be in hex format")
+	}
+	return nil
+}
+
+// interactive reports whether stdin is a terminal.
+func interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// startArbiter runs the arbiter with the loaded config until SIGINT or
+// SIGTERM. Changes of the config file are applied while it runs.
+func startArbiter(f *runFlags, configPath string) {
+	// cancelled on SIGINT/SIGTERM, subsystems then finish their current work
+	ctx, stop := signal.NotifyContext(gctx.New(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// start arbiter
+	g.Log().Info(ctx, "Starting arbiter, config:", configPath)
+	cfg := initConfig(ctx)
+	if err := logging.Setup(cfg.Log); err != nil {
+		g.Log().Error(ctx, "setup logging err:", err)
+		os.Exit(1)
+	}
+	arb := arbiter.NewArbiter(ctx, cfg)
+	arb.Start()
+	go watchConfig(ctx, f, configPath, arb)
+
+	<-ctx.Done()
+	stop()
+	g.Log().Info(ctx, "Shutting down arbiter, send the signal again to force it...")
+	arb.Wait()
+	arb.Close()
+	g.Log().Info(ctx, "Arbiter stopped")
+}
@@ -48,14 +48,29 @@ update_log()
 check_status()
 {
  PROCESS_NAME="arbiter"
- if pgrep -x "$PROCESS_NAME" > /dev/null
+ if ! pgrep -x "$PROCESS_NAME" > /dev/null
  then
-     echo_info "$PROCESS_NAME is running."
-     echo_info_green "Succeed!"
- else
      echo_info "$PROCESS_NAME is not running."
      echo_info_red "Failed!"
+     return
  fi
+ echo_info "$PROCESS_NAME is running."
+
+ # wait for the readiness probe, the first check runs after startup
+ for i in $(seq 1 12)
+ do
+     if curl -fs http://127.0.0.1:9180/readyz > /dev/null
+     then
+         echo_info "$PROCESS_NAME is ready."
+         echo_info_green "Succeed!"
+         return
+     fi
+     sleep 5
+ done
+ echo_info "$PROCESS_NAME is not ready:"
+ curl -s http://127.0.0.1:9180/readyz
+ echo
+ echo_info_red "Failed!"
 }
 
 #